@@ -5,8 +5,8 @@ var params = {
       AttributeName: 'id',
       KeyType: 'HASH',
     },
-    { // Required HASH type attribute
-      AttributeName: 'created',
+    { // Required RANGE type attribute
+      AttributeName: 'username',
       KeyType: 'RANGE',
     }
   ],
@@ -16,8 +16,8 @@ var params = {
       AttributeType: 'S', // (S | N | B) for string, number, binary
     },
     {
-      AttributeName: 'created',
-      AttributeType: 'N', // (S | N | B) for string, number, binary
+      AttributeName: 'username',
+      AttributeType: 'S', // (S | N | B) for string, number, binary
     }
   ],
   ProvisionedThroughput: { // required provisioned throughput for the table
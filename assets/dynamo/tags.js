@@ -0,0 +1,32 @@
+var params = {
+  TableName: 'tags',
+  KeySchema: [ // The type of of schema.  Must start with a HASH type, with an optional second RANGE.
+    { // Required HASH type attribute
+      AttributeName: 'tag',
+      KeyType: 'HASH',
+    },
+    { // Required RANGE type attribute
+      AttributeName: 'id',
+      KeyType: 'RANGE',
+    }
+  ],
+  AttributeDefinitions: [ // The names and types of all primary and index key attributes only
+    {
+      AttributeName: 'tag',
+      AttributeType: 'S', // (S | N | B) for string, number, binary
+    },
+    {
+      AttributeName: 'id',
+      AttributeType: 'S', // (S | N | B) for string, number, binary
+    }
+  ],
+  ProvisionedThroughput: { // required provisioned throughput for the table
+    ReadCapacityUnits: 1,
+    WriteCapacityUnits: 1,
+  }
+};
+
+dynamodb.createTable(params, function(err, data) {
+  if (err) ppJson(err); // an error occurred
+  else ppJson(data); // successful response
+});
@@ -0,0 +1,22 @@
+// Command build-assets minifies the stylesheets and scripts into
+// public/assets, naming each file after a hash of its content, and writes
+// assets/manifest.json, which the `asset` template helper reads. Run it
+// before deploying.
+package main
+
+import (
+	"log"
+
+	"bishack.dev/utils"
+)
+
+func main() {
+	files, err := utils.BuildAssets("public/assets")
+	if err != nil {
+		log.Fatalln("build error:", err.Error())
+	}
+
+	for name, file := range files {
+		log.Printf("%s -> public/assets/%s", name, file)
+	}
+}
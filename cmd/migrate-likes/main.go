@@ -0,0 +1,63 @@
+// Command migrate-likes copies likes from the old likes table, keyed by
+// (id, created), into a likes table keyed by (id, username). Duplicate likes
+// of the same user are dropped along the way. Run reconcile-likes after it
+// to fix the counters on the posts.
+package main
+
+import (
+	"log"
+	"os"
+
+	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	// autoload env
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	from := os.Getenv("DYNAMO_TABLE_LIKES_OLD")
+	to := os.Getenv("DYNAMO_TABLE_LIKES")
+	if from == "" || to == "" {
+		log.Fatalln("DYNAMO_TABLE_LIKES_OLD and DYNAMO_TABLE_LIKES are required")
+	}
+
+	c := dynamo.New(to, os.Getenv("DYNAMO_ENDPOINT"), nil)
+
+	input := &dynamodb.ScanInput{}
+	input.SetTableName(from)
+
+	copied, skipped := 0, 0
+	for {
+		out, err := c.Provider.Scan(input)
+		if err != nil {
+			log.Fatalln("scan error:", err.Error())
+		}
+
+		for _, item := range out.Items {
+			put := &dynamodb.PutItemInput{}
+			put.SetTableName(c.TableName)
+			put.SetItem(item)
+			put.SetConditionExpression("attribute_not_exists(id)")
+
+			_, err := c.Provider.PutItem(put)
+			if aerr, ok := err.(awserr.Error); ok &&
+				aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+				skipped++
+				continue
+			}
+			if err != nil {
+				log.Fatalln("put error:", err.Error())
+			}
+			copied++
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.SetExclusiveStartKey(out.LastEvaluatedKey)
+	}
+
+	log.Printf("copied %d likes, skipped %d duplicates\n", copied, skipped)
+}
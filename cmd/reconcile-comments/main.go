@@ -0,0 +1,30 @@
+// Command reconcile-comments recomputes the `commentsCount` counter of every
+// post from the comments table. Run it once after deploying the counters,
+// and again any time the counters look off.
+package main
+
+import (
+	"log"
+	"os"
+
+	"bishack.dev/services/comment"
+
+	// autoload env
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	c := comment.New(
+		os.Getenv("DYNAMO_TABLE_COMMENTS"),
+		os.Getenv("DYNAMO_ENDPOINT"),
+		nil,
+	)
+	c.PostsTable = os.Getenv("DYNAMO_TABLE_POSTS")
+
+	n, err := c.Reconcile()
+	if err != nil {
+		log.Fatalln("reconcile error:", err.Error())
+	}
+
+	log.Printf("reconciled comments of %d posts\n", n)
+}
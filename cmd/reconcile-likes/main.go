@@ -0,0 +1,30 @@
+// Command reconcile-likes recomputes the `likes` counter of every post from
+// the likes table. Run it once after deploying the counters, and again any
+// time the counters look off.
+package main
+
+import (
+	"log"
+	"os"
+
+	"bishack.dev/services/like"
+
+	// autoload env
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	l := like.New(
+		os.Getenv("DYNAMO_TABLE_LIKES"),
+		os.Getenv("DYNAMO_ENDPOINT"),
+		nil,
+	)
+	l.PostsTable = os.Getenv("DYNAMO_TABLE_POSTS")
+
+	n, err := l.Reconcile()
+	if err != nil {
+		log.Fatalln("reconcile error:", err.Error())
+	}
+
+	log.Printf("reconciled likes of %d posts\n", n)
+}
@@ -0,0 +1,294 @@
+// Package container holds the services handlers depend on. It's built once
+// when the app starts and travels with every request on its context.
+package container
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"bishack.dev/services/comment"
+	"bishack.dev/services/like"
+	"bishack.dev/services/post"
+	"bishack.dev/services/search"
+	"bishack.dev/services/sqlite"
+	"bishack.dev/services/tag"
+	"bishack.dev/services/token"
+	"bishack.dev/services/user"
+	"bishack.dev/utils/session"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	// autoload env
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// UserService talks to Cognito
+type UserService interface {
+	Signup(username, password string, meta map[string]string) (*cip.SignUpOutput, error)
+	Verify(username, code string) (*cip.ConfirmSignUpOutput, error)
+	Login(username, password string) (*cip.InitiateAuthOutput, error)
+	GetToken(username, token string) (string, error)
+	AccountDetails(token string) *user.User
+	GetUser(username string) *user.User
+	UpdateUser(token string, attrs map[string]string) (*cip.UpdateUserAttributesOutput, error)
+	ChangePassword(token, previous, proposed string) (*cip.ChangePasswordOutput, error)
+}
+
+// SessionHelper keeps track of who's signed in and of flash messages
+type SessionHelper interface {
+	SetUser(w http.ResponseWriter, r *http.Request, username, token string)
+	GetUser(r *http.Request) map[string]string
+	DeleteUser(w http.ResponseWriter, r *http.Request)
+	GetSessions(username string) ([]*session.Session, error)
+	RevokeSession(username, id string) error
+	SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
+	GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
+}
+
+// HTTPClient makes outgoing requests
+type HTTPClient interface {
+	Do(r *http.Request) (*http.Response, error)
+	Get(url string) (*http.Response, error)
+	PostForm(url string, data url.Values) (*http.Response, error)
+}
+
+// PostService stores posts
+type PostService interface {
+	post.Repository
+}
+
+// LikeService stores likes
+type LikeService interface {
+	like.Repository
+}
+
+// CommentService stores comments
+type CommentService interface {
+	CreateComment(params map[string]interface{}) (*comment.Comment, error)
+	GetComments(post string) ([]*comment.Comment, error)
+	UpdateComment(post, id, username, content string) error
+	DeleteComment(post, id, username string) error
+	RemoveComment(post, id string) error
+	HideComment(post, id string, hidden bool) error
+	PinComment(post, id string, pinned bool) error
+}
+
+// TagService stores post tags
+type TagService interface {
+	GetCloud() ([]*tag.Count, error)
+	GetTagged(tag string) ([]*tag.Tag, error)
+	SetTags(id string, created int64, username string, publish int, previous, tags []string) error
+	RemoveTags(id string, tags []string) error
+}
+
+// TokenService stores personal access tokens
+type TokenService interface {
+	CreateToken(username, name string, scopes []string) (string, *token.Token, error)
+	GetTokens(username string) ([]*token.Token, error)
+	RevokeToken(username, id string) error
+	Verify(secret string) (*token.Token, error)
+}
+
+// Container holds a service of each kind. Fields left nil are served by a
+// placeholder whose calls fail, rather than panic.
+type Container struct {
+	Users    UserService
+	Session  SessionHelper
+	Client   HTTPClient
+	Posts    PostService
+	Likes    LikeService
+	Comments CommentService
+	Tags     TagService
+	Tokens   TokenService
+	Search   search.Index
+}
+
+// New builds the services from the environment. STORAGE picks where posts,
+// likes, comments, tags and tokens are kept: dynamo, the default, memory or
+// sqlite, the database at SQLITE_PATH. COGNITO_LOCAL=true signs users up
+// and in against an in-process stand-in for Cognito.
+func New() (*Container, error) {
+	var (
+		cognitoID           = os.Getenv("COGNITO_CLIENT_ID")
+		cognitoSecret       = os.Getenv("COGNITO_CLIENT_SECRET")
+		dynamoTablePosts    = os.Getenv("DYNAMO_TABLE_POSTS")
+		dynamoTableLikes    = os.Getenv("DYNAMO_TABLE_LIKES")
+		dynamoTableComments = os.Getenv("DYNAMO_TABLE_COMMENTS")
+		dynamoTableTags     = os.Getenv("DYNAMO_TABLE_TAGS")
+		dynamoTableTokens   = os.Getenv("DYNAMO_TABLE_TOKENS")
+		dynamoTableSessions = os.Getenv("DYNAMO_TABLE_SESSIONS")
+		dynamoEndpoint      = os.Getenv("DYNAMO_ENDPOINT")
+		storage             = os.Getenv("STORAGE")
+		sqlitePath          = os.Getenv("SQLITE_PATH")
+		cognitoLocal        = os.Getenv("COGNITO_LOCAL")
+	)
+
+	// support timeout and net transport.
+	client := &http.Client{
+		Timeout: time.Second * 10,
+		Transport: &http.Transport{
+			Dial: (&net.Dialer{
+				Timeout: 5 * time.Second,
+			}).Dial,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+
+	users := user.New(cognitoID, cognitoSecret)
+	if cognitoLocal == "true" {
+		users.Provider = user.NewLocal(cognitoID, cognitoSecret)
+	}
+
+	c := &Container{
+		// Cognito lookups are cached across requests
+		Users:  user.NewCache(users),
+		Client: client,
+		Search: search.NewMemory(),
+	}
+
+	switch storage {
+	case "", "dynamo":
+		l := like.New(dynamoTableLikes, dynamoEndpoint, nil)
+		l.PostsTable = dynamoTablePosts
+
+		comments := comment.New(dynamoTableComments, dynamoEndpoint, nil)
+		comments.PostsTable = dynamoTablePosts
+
+		c.Posts = post.New(dynamoTablePosts, dynamoEndpoint, nil)
+		c.Likes = l
+		c.Comments = comments
+		// the tag cloud is cached across requests
+		c.Tags = tag.NewCache(tag.New(dynamoTableTags, dynamoEndpoint, nil))
+		c.Tokens = token.New(dynamoTableTokens, dynamoEndpoint, nil)
+		c.Session = session.New(session.NewDynamoStore(dynamoTableSessions, dynamoEndpoint, nil))
+	case "memory":
+		p := post.NewMemory()
+
+		c.Posts = p
+		c.Likes = like.NewMemory(p)
+		c.Comments = comment.NewMemory(p)
+		c.Tags = tag.NewMemory()
+		c.Tokens = token.NewMemory()
+		c.Session = session.New(session.NewMemoryStore())
+	case "sqlite":
+		if sqlitePath == "" {
+			sqlitePath = "bishack.db"
+		}
+
+		db, err := sqlite.Open(sqlitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Posts = post.NewSQLite(db)
+		c.Likes = like.NewSQLite(db)
+		c.Comments = comment.NewSQLite(db)
+		c.Tags = tag.NewSQLite(db)
+		c.Tokens = token.NewSQLite(db)
+		// sessions don't outlive the process, signing in again is cheap
+		c.Session = session.New(session.NewMemoryStore())
+	default:
+		return nil, fmt.Errorf("Unknown STORAGE %q, use dynamo, memory or sqlite", storage)
+	}
+
+	return c, nil
+}
+
+type key int
+
+const (
+	containerKey key = iota
+	userKey
+	tokenKey
+	apiTokenKey
+)
+
+// With returns a shallow copy of r carrying c
+func With(r *http.Request, c *Container) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), containerKey, c))
+}
+
+// From gets the container ctx carries, an empty one if there's none
+func From(ctx context.Context) *Container {
+	if c, ok := ctx.Value(containerKey).(*Container); ok && c != nil {
+		return c
+	}
+
+	return &Container{}
+}
+
+// Users ...
+func Users(ctx context.Context) UserService {
+	if s := From(ctx).Users; s != nil {
+		return s
+	}
+	return noUsers{}
+}
+
+// Session ...
+func Session(ctx context.Context) SessionHelper {
+	if s := From(ctx).Session; s != nil {
+		return s
+	}
+	return noSession{}
+}
+
+// Client ...
+func Client(ctx context.Context) HTTPClient {
+	if s := From(ctx).Client; s != nil {
+		return s
+	}
+	return noClient{}
+}
+
+// Posts ...
+func Posts(ctx context.Context) PostService {
+	if s := From(ctx).Posts; s != nil {
+		return s
+	}
+	return noPosts{}
+}
+
+// Likes ...
+func Likes(ctx context.Context) LikeService {
+	if s := From(ctx).Likes; s != nil {
+		return s
+	}
+	return noLikes{}
+}
+
+// Comments ...
+func Comments(ctx context.Context) CommentService {
+	if s := From(ctx).Comments; s != nil {
+		return s
+	}
+	return noComments{}
+}
+
+// Tags ...
+func Tags(ctx context.Context) TagService {
+	if s := From(ctx).Tags; s != nil {
+		return s
+	}
+	return noTags{}
+}
+
+// Tokens ...
+func Tokens(ctx context.Context) TokenService {
+	if s := From(ctx).Tokens; s != nil {
+		return s
+	}
+	return noTokens{}
+}
+
+// Search ...
+func Search(ctx context.Context) search.Index {
+	if s := From(ctx).Search; s != nil {
+		return s
+	}
+	return noSearch{}
+}
@@ -0,0 +1,126 @@
+package container
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"bishack.dev/services/comment"
+	"bishack.dev/services/like"
+	"bishack.dev/services/post"
+	"bishack.dev/services/tag"
+	"bishack.dev/services/token"
+	"bishack.dev/services/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	defer os.Unsetenv("STORAGE")
+	defer os.Unsetenv("SQLITE_PATH")
+
+	t.Run("dynamo", func(t *testing.T) {
+		os.Setenv("STORAGE", "")
+
+		c, err := New()
+		assert.Nil(t, err)
+		assert.IsType(t, &post.Client{}, c.Posts)
+		assert.IsType(t, &like.Client{}, c.Likes)
+		assert.IsType(t, &comment.Client{}, c.Comments)
+		assert.IsType(t, &tag.Cache{}, c.Tags)
+		assert.IsType(t, &token.Client{}, c.Tokens)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		os.Setenv("STORAGE", "memory")
+
+		c, err := New()
+		assert.Nil(t, err)
+		assert.IsType(t, &post.Memory{}, c.Posts)
+		assert.IsType(t, &like.Memory{}, c.Likes)
+		assert.IsType(t, &comment.Memory{}, c.Comments)
+		assert.IsType(t, &tag.Memory{}, c.Tags)
+		assert.IsType(t, &token.Memory{}, c.Tokens)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		os.Setenv("STORAGE", "sqlite")
+		os.Setenv("SQLITE_PATH", ":memory:")
+
+		c, err := New()
+		assert.Nil(t, err)
+		assert.IsType(t, &post.SQLite{}, c.Posts)
+		assert.IsType(t, &like.SQLite{}, c.Likes)
+		assert.IsType(t, &comment.SQLite{}, c.Comments)
+		assert.IsType(t, &tag.SQLite{}, c.Tags)
+		assert.IsType(t, &token.SQLite{}, c.Tokens)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		os.Setenv("STORAGE", "postgres")
+
+		_, err := New()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("local cognito", func(t *testing.T) {
+		os.Setenv("STORAGE", "memory")
+		os.Setenv("COGNITO_LOCAL", "true")
+		defer os.Unsetenv("COGNITO_LOCAL")
+
+		c, err := New()
+		assert.Nil(t, err)
+		assert.IsType(t, &user.Local{}, c.Users.(*user.Cache).Provider)
+	})
+}
+
+func TestFrom(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		assert.NotNil(t, From(context.Background()))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		c := &Container{}
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+		assert.Equal(t, c, From(With(r, c).Context()))
+	})
+}
+
+func TestMissingServices(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Nil(t, Users(ctx).GetUser("test"))
+	assert.Nil(t, Posts(ctx).GetPost("test", "x"))
+	assert.Nil(t, Session(ctx).GetUser(nil))
+	assert.Empty(t, Search(ctx).Search("test", 0))
+
+	_, err := Tokens(ctx).Verify("bh_test")
+	assert.Equal(t, ErrUnavailable, err)
+
+	_, _, err = Posts(ctx).GetPostsPage("", 10)
+	assert.Equal(t, ErrUnavailable, err)
+
+	assert.Equal(t, ErrUnavailable, Likes(ctx).Like("x", "test"))
+	assert.Equal(t, ErrUnavailable, Comments(ctx).RemoveComment("x", "y"))
+	assert.Equal(t, ErrUnavailable, Tags(ctx).RemoveTags("x", nil))
+
+	_, err = Client(ctx).Get("https://bishack.dev")
+	assert.Equal(t, ErrUnavailable, err)
+}
+
+func TestRequestValues(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+	assert.Nil(t, User(r.Context()))
+	assert.Equal(t, "", Token(r.Context()))
+	assert.Nil(t, APIToken(r.Context()))
+
+	u := &user.User{Username: "test"}
+	tk := &token.Token{ID: "x"}
+	r = WithAPIToken(WithToken(WithUser(r, u), "access"), tk)
+
+	assert.Equal(t, u, User(r.Context()))
+	assert.Equal(t, "access", Token(r.Context()))
+	assert.Equal(t, tk, APIToken(r.Context()))
+}
@@ -0,0 +1,45 @@
+package container
+
+import (
+	"context"
+	"net/http"
+
+	"bishack.dev/services/token"
+	"bishack.dev/services/user"
+)
+
+// WithUser returns a shallow copy of r carrying the signed in user
+func WithUser(r *http.Request, u *user.User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userKey, u))
+}
+
+// User is the signed in user, nil for visitors
+func User(ctx context.Context) *user.User {
+	u, _ := ctx.Value(userKey).(*user.User)
+	return u
+}
+
+// WithToken returns a shallow copy of r carrying the user's Cognito access
+// token
+func WithToken(r *http.Request, t string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), tokenKey, t))
+}
+
+// Token is the Cognito access token of the signed in user, empty for
+// visitors and bearer requests
+func Token(ctx context.Context) string {
+	t, _ := ctx.Value(tokenKey).(string)
+	return t
+}
+
+// WithAPIToken returns a shallow copy of r carrying the personal access
+// token it was authenticated with
+func WithAPIToken(r *http.Request, t *token.Token) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), apiTokenKey, t))
+}
+
+// APIToken is the personal access token of a bearer request, nil otherwise
+func APIToken(ctx context.Context) *token.Token {
+	t, _ := ctx.Value(apiTokenKey).(*token.Token)
+	return t
+}
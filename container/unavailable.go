@@ -0,0 +1,122 @@
+package container
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"bishack.dev/services/comment"
+	"bishack.dev/services/like"
+	"bishack.dev/services/post"
+	"bishack.dev/services/search"
+	"bishack.dev/services/tag"
+	"bishack.dev/services/token"
+	"bishack.dev/services/user"
+	"bishack.dev/utils/session"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+// ErrUnavailable is returned by the placeholders standing in for services
+// a container doesn't have
+var ErrUnavailable = errors.New("Service unavailable")
+
+// Placeholders for missing services. Lookups find nothing and everything
+// else fails with ErrUnavailable.
+type (
+	noUsers    struct{}
+	noSession  struct{}
+	noClient   struct{}
+	noPosts    struct{}
+	noLikes    struct{}
+	noComments struct{}
+	noTags     struct{}
+	noTokens   struct{}
+	noSearch   struct{}
+)
+
+func (noUsers) Signup(username, password string, meta map[string]string) (*cip.SignUpOutput, error) {
+	return nil, ErrUnavailable
+}
+func (noUsers) Verify(username, code string) (*cip.ConfirmSignUpOutput, error) {
+	return nil, ErrUnavailable
+}
+func (noUsers) Login(username, password string) (*cip.InitiateAuthOutput, error) {
+	return nil, ErrUnavailable
+}
+func (noUsers) GetToken(username, token string) (string, error) { return "", ErrUnavailable }
+func (noUsers) AccountDetails(token string) *user.User          { return nil }
+func (noUsers) GetUser(username string) *user.User              { return nil }
+func (noUsers) UpdateUser(token string, attrs map[string]string) (*cip.UpdateUserAttributesOutput, error) {
+	return nil, ErrUnavailable
+}
+func (noUsers) ChangePassword(token, previous, proposed string) (*cip.ChangePasswordOutput, error) {
+	return nil, ErrUnavailable
+}
+
+func (noSession) SetUser(w http.ResponseWriter, r *http.Request, username, token string) {}
+func (noSession) GetUser(r *http.Request) map[string]string                              { return nil }
+func (noSession) DeleteUser(w http.ResponseWriter, r *http.Request)                      {}
+func (noSession) GetSessions(username string) ([]*session.Session, error) {
+	return nil, ErrUnavailable
+}
+func (noSession) RevokeSession(username, id string) error                      { return ErrUnavailable }
+func (noSession) SetFlash(w http.ResponseWriter, r *http.Request, t, v string) {}
+func (noSession) GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash {
+	return nil
+}
+
+func (noClient) Do(r *http.Request) (*http.Response, error)          { return nil, ErrUnavailable }
+func (noClient) Get(url string) (*http.Response, error)              { return nil, ErrUnavailable }
+func (noClient) PostForm(string, url.Values) (*http.Response, error) { return nil, ErrUnavailable }
+
+func (noPosts) CreatePost(params map[string]interface{}) *post.Post { return nil }
+func (noPosts) GetPost(username, id string) *post.Post              { return nil }
+func (noPosts) GetPosts() ([]*post.Post, error)                     { return nil, ErrUnavailable }
+func (noPosts) GetPostsPage(after string, limit int64) ([]*post.Post, string, error) {
+	return nil, "", ErrUnavailable
+}
+func (noPosts) GetUserPostsPage(username, after string, limit int64) ([]*post.Post, string, error) {
+	return nil, "", ErrUnavailable
+}
+func (noPosts) GetDrafts(username string) []*post.Post      { return nil }
+func (noPosts) BatchGetPosts(keys []*post.Key) []*post.Post { return nil }
+func (noPosts) UpdatePost(username, id string, created int64, params map[string]interface{}) error {
+	return ErrUnavailable
+}
+func (noPosts) DeletePost(username, id string, created int64) error { return ErrUnavailable }
+
+func (noLikes) GetLike(id, username string) (*like.Like, error) { return nil, ErrUnavailable }
+func (noLikes) ToggleLike(id, username string) error            { return ErrUnavailable }
+func (noLikes) Like(id, username string) error                  { return ErrUnavailable }
+func (noLikes) Unlike(id, username string) error                { return ErrUnavailable }
+
+func (noComments) CreateComment(params map[string]interface{}) (*comment.Comment, error) {
+	return nil, ErrUnavailable
+}
+func (noComments) GetComments(post string) ([]*comment.Comment, error) {
+	return nil, ErrUnavailable
+}
+func (noComments) UpdateComment(post, id, username, content string) error { return ErrUnavailable }
+func (noComments) DeleteComment(post, id, username string) error          { return ErrUnavailable }
+func (noComments) RemoveComment(post, id string) error                    { return ErrUnavailable }
+func (noComments) HideComment(post, id string, hidden bool) error         { return ErrUnavailable }
+func (noComments) PinComment(post, id string, pinned bool) error          { return ErrUnavailable }
+
+func (noTags) GetCloud() ([]*tag.Count, error)           { return nil, ErrUnavailable }
+func (noTags) GetTagged(tag string) ([]*tag.Tag, error)  { return nil, ErrUnavailable }
+func (noTags) RemoveTags(id string, tags []string) error { return ErrUnavailable }
+func (noTags) SetTags(id string, created int64, username string, publish int, previous, tags []string) error {
+	return ErrUnavailable
+}
+
+func (noTokens) CreateToken(username, name string, scopes []string) (string, *token.Token, error) {
+	return "", nil, ErrUnavailable
+}
+func (noTokens) GetTokens(username string) ([]*token.Token, error) { return nil, ErrUnavailable }
+func (noTokens) RevokeToken(username, id string) error             { return ErrUnavailable }
+func (noTokens) Verify(secret string) (*token.Token, error)        { return nil, ErrUnavailable }
+
+func (noSearch) Add(p *post.Post)                                {}
+func (noSearch) Remove(id string)                                {}
+func (noSearch) Search(query string, limit int) []*search.Result { return nil }
+func (noSearch) Load(load func() ([]*post.Post, error)) error    { return ErrUnavailable }
@@ -3,24 +3,29 @@ module bishack.dev
 go 1.12
 
 require (
+	github.com/alecthomas/chroma/v2 v2.14.0
 	github.com/aws/aws-sdk-go v1.30.14
 	github.com/aws/aws-xray-sdk-go v1.0.0
-	github.com/gorilla/context v1.1.1
+	github.com/gorilla/context v1.1.1 // indirect
 	github.com/gorilla/csrf v1.5.1
 	github.com/gorilla/mux v1.7.2 // indirect
 	github.com/gorilla/pat v0.0.0-20180118222023-199c85a7f6d1
 	github.com/gorilla/sessions v1.1.3
 	github.com/joho/godotenv v1.3.0
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/pkg/errors v0.9.1
 	github.com/russross/blackfriday v2.0.0+incompatible // indirect
 	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
 	github.com/stretchr/objx v0.2.0 // indirect
 	github.com/stretchr/testify v1.5.1
+	github.com/tdewolff/minify/v2 v2.20.19
+	github.com/tdewolff/parse/v2 v2.7.12
 	gitlab.com/golang-commonmark/html v0.0.0-20180917080848-cfaf75183c4a // indirect
 	gitlab.com/golang-commonmark/linkify v0.0.0-20180917065525-c22b7bdb1179 // indirect
 	gitlab.com/golang-commonmark/markdown v0.0.0-20181102083822-772775880e1f
 	gitlab.com/golang-commonmark/mdurl v0.0.0-20180912090424-e5bce34c34f2 // indirect
 	gitlab.com/golang-commonmark/puny v0.0.0-20180912090636-2cd490539afe // indirect
 	gitlab.com/opennota/wd v0.0.0-20180912061657-c5d65f63c638 // indirect
-	golang.org/x/text v0.3.2 // indirect
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.26.0
 )
@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bishack.dev/container"
+	"bishack.dev/services/dynamo"
+	"bishack.dev/services/post"
+	"bishack.dev/services/tag"
+	"bishack.dev/services/user"
+	"bishack.dev/utils"
+)
+
+// maxAPILimit caps the `limit` query param of paginated API endpoints
+const maxAPILimit = 100
+
+// maxAPIBody caps the size of API request bodies
+const maxAPIBody = 1 << 20
+
+type apiPost struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Cover       string   `json:"cover,omitempty"`
+	Content     string   `json:"content"`
+	Author      string   `json:"author"`
+	Username    string   `json:"username"`
+	UserPic     string   `json:"userPic,omitempty"`
+	Tags        []string `json:"tags"`
+	Published   bool     `json:"published"`
+	ReadingTime int      `json:"readingTime"`
+	Likes       int64    `json:"likes"`
+	Created     int64    `json:"created"`
+	Updated     int64    `json:"updated"`
+}
+
+type apiUser struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Bio      string `json:"bio,omitempty"`
+	Location string `json:"location,omitempty"`
+	Website  string `json:"website,omitempty"`
+	Picture  string `json:"picture,omitempty"`
+}
+
+type apiLike struct {
+	Post  string `json:"post"`
+	Liked bool   `json:"liked"`
+	Likes int64  `json:"likes"`
+}
+
+// apiPage wraps paginated results. Pass `next` as the `after` query param
+// to get the following page; it's omitted on the last page.
+type apiPage struct {
+	Data interface{} `json:"data"`
+	Next string      `json:"next,omitempty"`
+}
+
+func newAPIPost(p *post.Post) *apiPost {
+	tags := p.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &apiPost{
+		ID:          p.ID,
+		Title:       p.Title,
+		Cover:       p.Cover,
+		Content:     p.Content,
+		Author:      p.Author,
+		Username:    p.Username,
+		UserPic:     p.UserPic,
+		Tags:        tags,
+		Published:   p.Publish == 1,
+		ReadingTime: computeReadingTime(p.Content),
+		Likes:       p.LikesCount,
+		Created:     p.Created,
+		Updated:     p.Updated,
+	}
+}
+
+func newAPIPosts(posts []*post.Post) []*apiPost {
+	out := []*apiPost{}
+	for _, p := range posts {
+		out = append(out, newAPIPost(p))
+	}
+	return out
+}
+
+// APIPosts lists published posts, newest first
+func APIPosts(w http.ResponseWriter, r *http.Request) {
+	limit, ok := apiLimit(w, r)
+	if !ok {
+		return
+	}
+
+	ps := container.Posts(r.Context())
+
+	posts, next, err := ps.GetPostsPage(r.URL.Query().Get("after"), limit)
+	if !apiPageError(w, err) {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, &apiPage{Data: newAPIPosts(posts), Next: next})
+}
+
+// apiNewPost is the body of APICreatePost
+type apiNewPost struct {
+	Title     string   `json:"title"`
+	Content   string   `json:"content"`
+	Cover     string   `json:"cover"`
+	Tags      []string `json:"tags"`
+	Published bool     `json:"published"`
+}
+
+// APICreatePost creates a post for the current user, e.g. release notes
+// published from CI with a personal access token
+func APICreatePost(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		utils.WriteAPIError(w, http.StatusUnauthorized, "unauthorized", "Authentication required")
+		return
+	}
+
+	var body apiNewPost
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody)).Decode(&body)
+	if err != nil {
+		utils.WriteAPIError(w, http.StatusBadRequest, "invalid_body", "Body must be a JSON object")
+		return
+	}
+
+	body.Title = strings.TrimSpace(body.Title)
+	if body.Title == "" || strings.TrimSpace(body.Content) == "" {
+		utils.WriteAPIError(w, http.StatusBadRequest, "invalid_body", "title and content are required")
+		return
+	}
+
+	publish := 0
+	if body.Published {
+		publish = 1
+	}
+
+	tags := tag.Parse(strings.Join(body.Tags, ","))
+
+	attr := map[string]interface{}{
+		"title":       body.Title,
+		"cover":       body.Cover,
+		"content":     body.Content,
+		"publish":     publish,
+		"author":      u.Name,
+		"userPic":     u.Picture,
+		"username":    u.Username,
+		"readingTime": computeReadingTime(body.Content),
+	}
+	if len(tags) > 0 {
+		attr["tags"] = tags
+	}
+
+	ps := container.Posts(r.Context())
+
+	p := ps.CreatePost(attr)
+	if p == nil {
+		utils.WriteAPIError(w, http.StatusInternalServerError, "internal", "Something went wrong")
+		return
+	}
+
+	ts := container.Tags(r.Context())
+
+	if err := ts.SetTags(p.ID, p.Created, p.Username, p.Publish, nil, tags); err != nil {
+		log.Println("SetTags error", err.Error())
+	}
+
+	idx := container.Search(r.Context())
+	idx.Add(p)
+
+	utils.WriteJSON(w, http.StatusCreated, newAPIPost(p))
+}
+
+// APIPost gets a single post. Drafts are only visible to their author.
+func APIPost(w http.ResponseWriter, r *http.Request) {
+	p := apiGetPost(w, r)
+	if p == nil {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, newAPIPost(p))
+}
+
+// APIPostLike gets the like count of a post and whether the current user
+// likes it
+func APIPostLike(w http.ResponseWriter, r *http.Request) {
+	p := apiGetPost(w, r)
+	if p == nil {
+		return
+	}
+
+	ls := container.Likes(r.Context())
+
+	liked := false
+	if u := container.User(r.Context()); u != nil {
+		_, err := ls.GetLike(p.ID, u.Username)
+		liked = err == nil
+	}
+
+	utils.WriteJSON(w, http.StatusOK, &apiLike{Post: p.ID, Liked: liked, Likes: p.LikesCount})
+}
+
+// APIUser gets the public profile of a user
+func APIUser(w http.ResponseWriter, r *http.Request) {
+	u := apiGetUser(w, r)
+	if u == nil {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, &apiUser{
+		Username: u.Username,
+		Name:     u.Name,
+		Bio:      u.Bio,
+		Location: u.Location,
+		Website:  u.Website,
+		Picture:  u.Picture,
+	})
+}
+
+// APIUserPosts lists the published posts of a user, newest first
+func APIUserPosts(w http.ResponseWriter, r *http.Request) {
+	limit, ok := apiLimit(w, r)
+	if !ok {
+		return
+	}
+
+	u := apiGetUser(w, r)
+	if u == nil {
+		return
+	}
+
+	ps := container.Posts(r.Context())
+
+	posts, next, err := ps.GetUserPostsPage(u.Username, r.URL.Query().Get("after"), limit)
+	if !apiPageError(w, err) {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, &apiPage{Data: newAPIPosts(posts), Next: next})
+}
+
+// APINotFound is the catch-all for unknown API routes
+func APINotFound(w http.ResponseWriter, r *http.Request) {
+	utils.WriteAPIError(w, http.StatusNotFound, "not_found", "Unknown API endpoint")
+}
+
+func apiGetPost(w http.ResponseWriter, r *http.Request) *post.Post {
+	username := r.URL.Query().Get(":username")
+	id := r.URL.Query().Get(":id")
+
+	ps := container.Posts(r.Context())
+
+	p := ps.GetPost(username, id)
+
+	// drafts are only visible to their author
+	if p != nil && p.Publish != 1 {
+		u := container.User(r.Context())
+		if u == nil || u.Username != p.Username {
+			p = nil
+		}
+	}
+
+	if p == nil {
+		utils.WriteAPIError(w, http.StatusNotFound, "not_found", "Post not found")
+		return nil
+	}
+
+	return p
+}
+
+func apiGetUser(w http.ResponseWriter, r *http.Request) *user.User {
+	us := container.Users(r.Context())
+
+	u := us.GetUser(r.URL.Query().Get(":username"))
+	if u == nil {
+		utils.WriteAPIError(w, http.StatusNotFound, "not_found", "User not found")
+		return nil
+	}
+
+	return u
+}
+
+// apiLimit reads the `limit` query param, defaulting to the page size of
+// the HTML feeds
+func apiLimit(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return pageSize, true
+	}
+
+	limit, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || limit < 1 || limit > maxAPILimit {
+		utils.WriteAPIError(
+			w,
+			http.StatusBadRequest,
+			"invalid_limit",
+			"limit must be a number from 1 to "+strconv.Itoa(maxAPILimit),
+		)
+		return 0, false
+	}
+
+	return limit, true
+}
+
+// apiPageError writes the error response of a failed page query and
+// reports whether it's safe to carry on
+func apiPageError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == dynamo.ErrInvalidCursor:
+		utils.WriteAPIError(w, http.StatusBadRequest, "invalid_cursor", "after is not a valid cursor")
+		return false
+	case err != nil:
+		utils.WriteAPIError(w, http.StatusInternalServerError, "internal", "Something went wrong")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/dynamo"
+	"bishack.dev/services/like"
+	"bishack.dev/services/post"
+	"bishack.dev/services/search"
+	"bishack.dev/services/user"
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeAPI(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	assert.Equal(t, "application/json", w.Header().Get("content-type"))
+
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func apiErrorCode(body map[string]interface{}) string {
+	return body["error"].(map[string]interface{})["code"].(string)
+}
+
+func TestAPIPosts(t *testing.T) {
+	t.Run("invalid limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts?limit=1000", nil)
+
+		APIPosts(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_limit", apiErrorCode(decodeAPI(t, w)))
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts?after=nope", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPostsPage", "nope", int64(pageSize)).Return(nil, "", dynamo.ErrInvalidCursor)
+
+		APIPosts(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_cursor", apiErrorCode(decodeAPI(t, w)))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPostsPage", "", int64(pageSize)).Return(nil, "", errors.New(""))
+
+		APIPosts(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "internal", apiErrorCode(decodeAPI(t, w)))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts?limit=1&after=first", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPostsPage", "first", int64(1)).Return([]*post.Post{
+			{ID: "test", Title: "Test", Publish: 1, LikesCount: 4},
+		}, "second", nil)
+
+		APIPosts(w, r)
+
+		body := decodeAPI(t, w)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "second", body["next"])
+
+		data := body["data"].([]interface{})
+		assert.Equal(t, 1, len(data))
+		first := data[0].(map[string]interface{})
+		assert.Equal(t, "test", first["id"])
+		assert.Equal(t, float64(4), first["likes"])
+		assert.Equal(t, true, first["published"])
+		assert.Equal(t, []interface{}{}, first["tags"])
+	})
+}
+
+func TestAPIPost(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts/test/nope", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPost").Return(nil)
+
+		APIPost(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "not_found", apiErrorCode(decodeAPI(t, w)))
+	})
+
+	t.Run("draft of someone else", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts/test/draft", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "ing"})
+		p.On("GetPost").Return(&post.Post{ID: "draft", Username: "test"})
+
+		APIPost(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("own draft", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts/test/draft", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+		p.On("GetPost").Return(&post.Post{ID: "draft", Username: "test"})
+
+		APIPost(w, r)
+
+		body := decodeAPI(t, w)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "draft", body["id"])
+		assert.Equal(t, false, body["published"])
+	})
+}
+
+func TestAPIPostLike(t *testing.T) {
+	t.Run("anonymous", func(t *testing.T) {
+		p := new(postMock)
+		l := new(likeMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts/test/test/like", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+			Likes: l,
+		})
+		p.On("GetPost").Return(&post.Post{ID: "test", Publish: 1, LikesCount: 2})
+
+		APIPostLike(w, r)
+
+		body := decodeAPI(t, w)
+		assert.Equal(t, false, body["liked"])
+		assert.Equal(t, float64(2), body["likes"])
+		l.AssertNotCalled(t, "GetLike")
+	})
+
+	t.Run("liked", func(t *testing.T) {
+		p := new(postMock)
+		l := new(likeMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/posts/test/test/like", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+			Likes: l,
+		})
+		r = container.WithUser(r, &user.User{Username: "ing"})
+		p.On("GetPost").Return(&post.Post{ID: "test", Publish: 1, LikesCount: 2})
+		l.On("GetLike", "test", "ing").Return(&like.Like{}, nil)
+
+		APIPostLike(w, r)
+
+		body := decodeAPI(t, w)
+		assert.Equal(t, true, body["liked"])
+		assert.Equal(t, "test", body["post"])
+	})
+}
+
+func TestAPIUser(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		u := new(userServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/users/nope", nil)
+
+		r = container.With(r, &container.Container{
+			Users: u,
+		})
+		u.On("GetUser", "").Return(nil)
+
+		APIUser(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "not_found", apiErrorCode(decodeAPI(t, w)))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		u := new(userServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/api/v1/users/test", nil)
+
+		r = container.With(r, &container.Container{
+			Users: u,
+		})
+		u.On("GetUser", "").Return(&user.User{
+			Username: "test",
+			Name:     "Test",
+			Email:    "secret@example.com",
+		})
+
+		APIUser(w, r)
+
+		body := decodeAPI(t, w)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "test", body["username"])
+		assert.Nil(t, body["email"])
+	})
+}
+
+func TestAPIUserPosts(t *testing.T) {
+	u := new(userServiceMock)
+	p := new(postMock)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/api/v1/users/test/posts", nil)
+
+	r = container.With(r, &container.Container{
+		Users: u,
+		Posts: p,
+	})
+	u.On("GetUser", "").Return(&user.User{Username: "test"})
+	p.On("GetUserPostsPage", "test", "", int64(pageSize)).Return([]*post.Post{
+		{ID: "test", Publish: 1},
+	}, "", nil)
+
+	APIUserPosts(w, r)
+
+	body := decodeAPI(t, w)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, len(body["data"].([]interface{})))
+	assert.Nil(t, body["next"])
+}
+
+func TestAPINotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/api/v2/nope", nil)
+
+	APINotFound(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "not_found", apiErrorCode(decodeAPI(t, w)))
+}
+
+func TestAPICreatePost(t *testing.T) {
+	t.Run("anonymous", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/api/v1/posts", strings.NewReader("{}"))
+
+		APICreatePost(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "unauthorized", apiErrorCode(decodeAPI(t, w)))
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		for _, body := range []string{"nope", `{"title":"Test"}`, `{"title":" ","content":"x"}`} {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/api/v1/posts", strings.NewReader(body))
+
+			r = container.WithUser(r, &user.User{Username: "test"})
+
+			APICreatePost(w, r)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, "invalid_body", apiErrorCode(decodeAPI(t, w)))
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(
+			http.MethodPost,
+			"/api/v1/posts",
+			strings.NewReader(`{"title":"Test","content":"Hello"}`),
+		)
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("CreatePost", mock.Anything).Return(nil)
+
+		APICreatePost(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(postMock)
+		tg := new(tagMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(
+			http.MethodPost,
+			"/api/v1/posts",
+			strings.NewReader(`{"title":"v1.2.0","content":"Notes","tags":["Release","go"],"published":true}`),
+		)
+
+		r = container.WithUser(r, &user.User{Username: "test", Name: "Test"})
+		r = container.With(r, &container.Container{
+			Posts:  p,
+			Tags:   tg,
+			Search: search.NewMemory(),
+		})
+
+		// the author always comes from the authenticated user
+		p.On("CreatePost", mock.MatchedBy(func(vals map[string]interface{}) bool {
+			return vals["username"] == "test" &&
+				vals["author"] == "Test" &&
+				vals["publish"] == 1
+		})).Return(&post.Post{
+			ID:       "v120-42",
+			Title:    "v1.2.0",
+			Username: "test",
+			Publish:  1,
+			Tags:     []string{"release", "go"},
+		})
+		tg.On("SetTags", "v120-42", int64(0), "test", 1, []string(nil), []string{"release", "go"}).Return(nil)
+
+		APICreatePost(w, r)
+
+		body := decodeAPI(t, w)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "v120-42", body["id"])
+		p.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"bishack.dev/utils"
+)
+
+// Asset serves stylesheets and scripts. Fingerprinted files are cached for
+// good, plain names (dev, or before a build) are revalidated every time.
+func Asset(w http.ResponseWriter, r *http.Request) {
+	file := r.URL.Query().Get(":file")
+
+	b, immutable, err := utils.ReadAsset(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println("Asset error", err.Error())
+		}
+
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if immutable {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(utils.AssetCacheAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	// ServeContent picks the content type from the extension
+	http.ServeContent(w, r, file, time.Time{}, bytes.NewReader(b))
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsset(t *testing.T) {
+	t.Run("from source", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/assets/main.css?:file=main.css", nil)
+
+		Asset(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, "^text/css", w.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/assets/nope.js?:file=nope.js", nil)
+
+		Asset(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -8,11 +8,8 @@ import (
 	"regexp"
 	"strings"
 
-	"bishack.dev/services/user"
+	"bishack.dev/container"
 	"bishack.dev/utils"
-	"bishack.dev/utils/session"
-	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
-	"github.com/gorilla/context"
 	"github.com/gorilla/csrf"
 )
 
@@ -33,13 +30,7 @@ func FinishSignup(w http.ResponseWriter, r *http.Request) {
 	username := r.Form.Get("username")
 	password := r.Form.Get("password")
 
-	u := context.Get(r, "userService").(interface {
-		Signup(
-			username,
-			password string,
-			meta map[string]string,
-		) (*cip.SignUpOutput, error)
-	})
+	u := container.Users(r.Context())
 
 	meta := map[string]string{
 		"name":     name,
@@ -59,9 +50,7 @@ func FinishSignup(w http.ResponseWriter, r *http.Request) {
 			errMessage = "Account already exists. Try to log in instead."
 		}
 
-		sess := context.Get(r, "session").(interface {
-			SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
-		})
+		sess := container.Session(r.Context())
 		sess.SetFlash(w, r, "error", errMessage)
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 
@@ -76,14 +65,9 @@ func Verify(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	username := r.URL.Query().Get("username")
 
-	sess := context.Get(r, "session").(interface {
-		SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
-	u := context.Get(r, "userService").(interface {
-		Verify(username, code string) (*cip.ConfirmSignUpOutput, error)
-	})
+	u := container.Users(r.Context())
 
 	if code != "" {
 		_, err := u.Verify(username, code)
@@ -121,14 +105,9 @@ func Verify(w http.ResponseWriter, r *http.Request) {
 // Signup ...
 func Signup(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
-	sess := context.Get(r, "session").(interface {
-		SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
-	client := context.Get(r, "client").(interface {
-		PostForm(url string, data url.Values) (*http.Response, error)
-	})
+	client := container.Client(r.Context())
 
 	// check for oauth code from github
 	if code != "" {
@@ -163,9 +142,7 @@ func Signup(w http.ResponseWriter, r *http.Request) {
 	// check for access token after code verification
 	accessToken := r.URL.Query().Get("access_token")
 	if accessToken != "" {
-		client := context.Get(r, "client").(interface {
-			Do(r *http.Request) (*http.Response, error)
-		})
+		client := container.Client(r.Context())
 
 		req, _ := http.NewRequest(
 			http.MethodGet,
@@ -210,28 +187,20 @@ func Signup(w http.ResponseWriter, r *http.Request) {
 
 // Logout ...
 func Logout(w http.ResponseWriter, r *http.Request) {
-	sess := context.Get(r, "session").(interface {
-		DeleteUser(w http.ResponseWriter, r *http.Request)
-	})
+	sess := container.Session(r.Context())
 	sess.DeleteUser(w, r)
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
 // Login ...
 func Login(w http.ResponseWriter, r *http.Request) {
-	sess := context.Get(r, "session").(interface {
-		SetUser(w http.ResponseWriter, r *http.Request, username, token string)
-		SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
-	})
+	sess := container.Session(r.Context())
 
 	_ = r.ParseForm()
 	username := r.Form.Get("username")
 	password := r.Form.Get("password")
 
-	u := context.Get(r, "userService").(interface {
-		Login(username, password string) (*cip.InitiateAuthOutput, error)
-		AccountDetails(token string) *user.User
-	})
+	u := container.Users(r.Context())
 
 	out, err := u.Login(username, password)
 
@@ -258,9 +227,7 @@ func Login(w http.ResponseWriter, r *http.Request) {
 
 // LoginForm ...
 func LoginForm(w http.ResponseWriter, r *http.Request) {
-	sess := context.Get(r, "session").(interface {
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
 	utils.Render(w, "main", "login-form", map[string]interface{}{
 		"Title":          "User Login",
@@ -10,12 +10,12 @@ import (
 	"regexp"
 	"testing"
 
+	"bishack.dev/container"
 	"bishack.dev/services/user"
 	_ "bishack.dev/testing"
 	"bishack.dev/utils/session"
 	"github.com/aws/aws-sdk-go/aws"
 	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
-	"github.com/gorilla/context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,7 +25,9 @@ func TestLoginForm(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-	context.Set(r, "session", s)
+	r = container.With(r, &container.Container{
+		Session: s,
+	})
 
 	s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 		return true
@@ -53,8 +55,10 @@ func TestLogin(t *testing.T) {
 		form.Add("password", "")
 		r.PostForm = form
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 		m.On("Login", "", "").Return(nil, errors.New("wrong username/password"))
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -81,8 +85,10 @@ func TestLogin(t *testing.T) {
 		form.Add("password", "test")
 		r.PostForm = form
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		out := &cip.InitiateAuthOutput{
 			AuthenticationResult: &cip.AuthenticationResultType{
@@ -119,8 +125,10 @@ func TestLogin(t *testing.T) {
 		form.Add("password", "test")
 		r.PostForm = form
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		out := &cip.InitiateAuthOutput{
 			AuthenticationResult: &cip.AuthenticationResultType{
@@ -159,7 +167,9 @@ func TestLogout(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest(http.MethodPost, "/login", nil)
 
-	context.Set(r, "session", s)
+	r = container.With(r, &container.Container{
+		Session: s,
+	})
 
 	s.On("DeleteUser", mock.MatchedBy(func(w http.ResponseWriter) bool {
 		return true
@@ -181,8 +191,10 @@ func TestVerify(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/verify?code=111&username=test", nil)
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		m.On("Verify", "test", "111").Return(nil, errors.New(""))
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
@@ -206,8 +218,10 @@ func TestVerify(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/verify?code=111&username=test", nil)
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		out := &cip.ConfirmSignUpOutput{}
 		m.On("Verify", "test", "111").Return(out, nil)
@@ -232,8 +246,10 @@ func TestVerify(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/verify", nil)
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -259,8 +275,10 @@ func TestVerify(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/verify", nil)
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -290,8 +308,10 @@ func TestFinishSignup(t *testing.T) {
 		form.Add("password", "beepboop")
 		r.PostForm = form
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		m.On("Signup", "test", "beepboop", mock.MatchedBy(func(m map[string]string) bool {
 			return true
@@ -323,8 +343,10 @@ func TestFinishSignup(t *testing.T) {
 		form.Add("password", "beepboop")
 		r.PostForm = form
 
-		context.Set(r, "userService", m)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:   m,
+			Session: s,
+		})
 
 		m.On("Signup", "test", "beepboop", mock.MatchedBy(func(m map[string]string) bool {
 			return true
@@ -349,7 +371,9 @@ func TestFinishSignup(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodPost, "/signup", nil)
 
-		context.Set(r, "userService", m)
+		r = container.With(r, &container.Container{
+			Users: m,
+		})
 
 		m.On("Signup", "", "", mock.MatchedBy(func(m map[string]string) bool {
 			return true
@@ -371,8 +395,10 @@ func TestSignup(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/signup?code=123", nil)
 
-			context.Set(r, "session", s)
-			context.Set(r, "client", c)
+			r = container.With(r, &container.Container{
+				Session: s,
+				Client:  c,
+			})
 
 			c.On("PostForm", mock.MatchedBy(func(url string) bool {
 				return true
@@ -399,8 +425,10 @@ func TestSignup(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/signup?code=123", nil)
 
-			context.Set(r, "session", s)
-			context.Set(r, "client", c)
+			r = container.With(r, &container.Container{
+				Session: s,
+				Client:  c,
+			})
 
 			resp := &http.Response{}
 			resp.Body = ioutil.NopCloser(bytes.NewReader([]byte(`?beep=boop`)))
@@ -429,8 +457,10 @@ func TestSignup(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/signup?code=123", nil)
 
-			context.Set(r, "session", s)
-			context.Set(r, "client", c)
+			r = container.With(r, &container.Container{
+				Session: s,
+				Client:  c,
+			})
 
 			resp := &http.Response{}
 			resp.Body = ioutil.NopCloser(bytes.NewReader([]byte(`access_token=123`)))
@@ -456,8 +486,10 @@ func TestSignup(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/signup?access_token=123", nil)
 
-			context.Set(r, "session", s)
-			context.Set(r, "client", c)
+			r = container.With(r, &container.Container{
+				Session: s,
+				Client:  c,
+			})
 
 			c.On("Do", mock.MatchedBy(func(r *http.Request) bool {
 				return true
@@ -482,8 +514,10 @@ func TestSignup(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodGet, "/signup?access_token=123", nil)
 
-			context.Set(r, "session", s)
-			context.Set(r, "client", c)
+			r = container.With(r, &container.Container{
+				Session: s,
+				Client:  c,
+			})
 
 			resp := &http.Response{}
 			resp.StatusCode = http.StatusOK
@@ -514,8 +548,10 @@ func TestSignup(t *testing.T) {
 			w := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodGet, "/signup?access_token=123", nil)
 
-			context.Set(r, "session", s)
-			context.Set(r, "client", c)
+			r = container.With(r, &container.Container{
+				Session: s,
+				Client:  c,
+			})
 
 			resp := &http.Response{}
 			resp.StatusCode = http.StatusOK
@@ -547,8 +583,10 @@ func TestSignup(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/signup", nil)
 
-		context.Set(r, "session", s)
-		context.Set(r, "client", c)
+		r = container.With(r, &container.Container{
+			Session: s,
+			Client:  c,
+		})
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
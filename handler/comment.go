@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+)
+
+// CreateComment ...
+func CreateComment(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	p := commentedPost(r)
+	if p == nil {
+		NotFound(w, r)
+		return
+	}
+
+	id := p.ID
+	content := strings.TrimSpace(r.PostForm.Get("content"))
+	back := fmt.Sprintf("/%s/%s", p.Username, id)
+
+	sess := container.Session(r.Context())
+
+	if content == "" {
+		sess.SetFlash(w, r, "error", "Comment can't be empty")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	cs := container.Comments(r.Context())
+
+	// replies only go to comments on the same post
+	parent := r.PostForm.Get("parent")
+	if parent != "" && !hasComment(cs, id, parent) {
+		sess.SetFlash(w, r, "error", "The comment you replied to is gone")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	c, err := cs.CreateComment(map[string]interface{}{
+		"post":     id,
+		"parent":   parent,
+		"author":   u.Name,
+		"username": u.Username,
+		"userPic":  u.Picture,
+		"content":  content,
+	})
+	if err != nil {
+		log.Println("CreateComment error:", err.Error())
+		sess.SetFlash(w, r, "error", "An error occurred. Try again.")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, back+"#comment-"+c.ID, http.StatusSeeOther)
+}
+
+// UpdateComment ...
+func UpdateComment(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	p := commentedPost(r)
+	if p == nil {
+		NotFound(w, r)
+		return
+	}
+
+	id := r.PostForm.Get("id")
+	post := p.ID
+	content := strings.TrimSpace(r.PostForm.Get("content"))
+	back := fmt.Sprintf("/%s/%s#comment-%s", p.Username, post, id)
+
+	sess := container.Session(r.Context())
+
+	if content == "" {
+		sess.SetFlash(w, r, "error", "Comment can't be empty")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	cs := container.Comments(r.Context())
+
+	err := cs.UpdateComment(post, id, u.Username, content)
+	if err != nil {
+		log.Println("UpdateComment error:", err.Error())
+		sess.SetFlash(w, r, "error", "An error occurred. Try again.")
+	} else {
+		sess.SetFlash(w, r, "success", "Comment updated!")
+	}
+
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// DeleteComment ...
+func DeleteComment(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	p := commentedPost(r)
+	if p == nil {
+		NotFound(w, r)
+		return
+	}
+
+	id := r.PostForm.Get("id")
+	post := p.ID
+	back := fmt.Sprintf("/%s/%s#comments", p.Username, post)
+
+	sess := container.Session(r.Context())
+
+	cs := container.Comments(r.Context())
+
+	err := cs.DeleteComment(post, id, u.Username)
+	if err != nil {
+		log.Println("DeleteComment error:", err.Error())
+		sess.SetFlash(w, r, "error", "An error occurred. Try again.")
+	} else {
+		sess.SetFlash(w, r, "success", "Comment deleted!")
+	}
+
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// ModerateComment lets the author of a post hide, pin or delete any comment
+// on their own post
+func ModerateComment(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	id := r.PostForm.Get("id")
+	pid := r.PostForm.Get("post")
+	action := r.PostForm.Get("action")
+	back := fmt.Sprintf("/%s/%s#comments", u.Username, pid)
+
+	sess := container.Session(r.Context())
+
+	// only the author of the post can moderate its comments
+	ps := container.Posts(r.Context())
+	if ps.GetPost(u.Username, pid) == nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	cs := container.Comments(r.Context())
+
+	var err error
+	switch action {
+	case "hide":
+		err = cs.HideComment(pid, id, true)
+	case "unhide":
+		err = cs.HideComment(pid, id, false)
+	case "pin":
+		err = cs.PinComment(pid, id, true)
+	case "unpin":
+		err = cs.PinComment(pid, id, false)
+	case "delete":
+		err = cs.RemoveComment(pid, id)
+	default:
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		log.Println("ModerateComment error:", err.Error())
+		sess.SetFlash(w, r, "error", "An error occurred. Try again.")
+	} else {
+		sess.SetFlash(w, r, "success", "Comment updated!")
+	}
+
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// commentedPost looks up the published post a comment form is for, drafts
+// can't be commented on. Redirects go to where the post is stored rather
+// than to whatever the form says.
+func commentedPost(r *http.Request) *post.Post {
+	id := r.PostForm.Get("post")
+	owner := r.PostForm.Get("owner")
+	if id == "" || owner == "" {
+		return nil
+	}
+
+	p := container.Posts(r.Context()).GetPost(owner, id)
+	if p == nil || p.Publish != 1 {
+		return nil
+	}
+
+	return p
+}
+
+// hasComment tells if the comment with the given id is on the post
+func hasComment(cs container.CommentService, post, id string) bool {
+	comments, err := cs.GetComments(post)
+	if err != nil {
+		log.Println("GetComments error:", err.Error())
+		return false
+	}
+
+	for _, c := range comments {
+		if c.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,450 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/comment"
+	"bishack.dev/services/post"
+	"bishack.dev/services/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateComment(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("unknown post", func(t *testing.T) {
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{"post": {"test"}, "owner": {"/evil.com"}, "content": {"hi"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(nil)
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("Location"))
+		c.AssertNotCalled(t, "CreateComment", mock.Anything)
+	})
+
+	t.Run("draft", func(t *testing.T) {
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{"post": {"test"}, "owner": {"ing"}, "content": {"hi"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing"})
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		c.AssertNotCalled(t, "CreateComment", mock.Anything)
+	})
+
+	t.Run("reply to a comment elsewhere", func(t *testing.T) {
+		s := new(sessionMock)
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{
+			"post":    {"test"},
+			"owner":   {"ing"},
+			"parent":  {"other"},
+			"content": {"hi"},
+		}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+		c.On("GetComments", "test").Return([]*comment.Comment{{ID: "x", Post: "test"}}, nil)
+		s.On("SetFlash", mock.Anything, mock.Anything, "error", "The comment you replied to is gone")
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/ing/test", w.Header().Get("Location"))
+		c.AssertNotCalled(t, "CreateComment", mock.Anything)
+		s.AssertExpectations(t)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		s := new(sessionMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{"post": {"test"}, "owner": {"ing"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Posts:   p,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "error", "Comment can't be empty")
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/ing/test", w.Header().Get("Location"))
+		s.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := new(sessionMock)
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{"post": {"test"}, "owner": {"ing"}, "content": {"hi"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+
+		c.On("CreateComment", mock.MatchedBy(func(params map[string]interface{}) bool {
+			return true
+		})).Return(nil, errors.New(""))
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "error", "An error occurred. Try again.")
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/ing/test", w.Header().Get("Location"))
+		c.AssertExpectations(t)
+		s.AssertExpectations(t)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{"post": {"test"}, "owner": {"ing"}, "content": {"hi"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+
+		c.On("CreateComment", mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["post"] == "test" &&
+				params["username"] == "test" &&
+				params["content"] == "hi"
+		})).Return(&comment.Comment{ID: "x"}, nil)
+
+		CreateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/ing/test#comment-x", w.Header().Get("Location"))
+		c.AssertExpectations(t)
+	})
+
+	t.Run("reply", func(t *testing.T) {
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/new", nil)
+		r.PostForm = url.Values{
+			"post":    {"test"},
+			"owner":   {"ing"},
+			"parent":  {"x"},
+			"content": {"hi"},
+		}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+		c.On("GetComments", "test").Return([]*comment.Comment{{ID: "x", Post: "test"}}, nil)
+		c.On("CreateComment", mock.MatchedBy(func(params map[string]interface{}) bool {
+			return params["parent"] == "x"
+		})).Return(&comment.Comment{ID: "y"}, nil)
+
+		CreateComment(w, r)
+
+		assert.Equal(t, "/ing/test#comment-y", w.Header().Get("Location"))
+		c.AssertExpectations(t)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/update", nil)
+
+		UpdateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := new(sessionMock)
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/update", nil)
+		r.PostForm = url.Values{"id": {"x"}, "post": {"test"}, "owner": {"ing"}, "content": {"hi"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+
+		c.On("UpdateComment", "test", "x", "test", "hi").Return(errors.New(""))
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "error", "An error occurred. Try again.")
+
+		UpdateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		c.AssertExpectations(t)
+		s.AssertExpectations(t)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/update", nil)
+		r.PostForm = url.Values{"id": {"x"}, "post": {"test"}, "owner": {"ing"}, "content": {"hi"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+
+		c.On("UpdateComment", "test", "x", "test", "hi").Return(nil)
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "success", "Comment updated!")
+
+		UpdateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/ing/test#comment-x", w.Header().Get("Location"))
+		c.AssertExpectations(t)
+		s.AssertExpectations(t)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/delete", nil)
+
+		DeleteComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+		c := new(commentMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/delete", nil)
+		r.PostForm = url.Values{"id": {"x"}, "post": {"test"}, "owner": {"ing"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "ing", Publish: 1})
+
+		c.On("DeleteComment", "test", "x", "test").Return(nil)
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "success", "Comment deleted!")
+
+		DeleteComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/ing/test#comments", w.Header().Get("Location"))
+		c.AssertExpectations(t)
+		s.AssertExpectations(t)
+	})
+}
+
+func TestModerateComment(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/moderate", nil)
+
+		ModerateComment(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("not the post author", func(t *testing.T) {
+		s := new(sessionMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/moderate", nil)
+		r.PostForm = url.Values{"id": {"x"}, "post": {"test"}, "action": {"hide"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Posts:   p,
+		})
+
+		p.On("GetPost").Return(nil)
+
+		ModerateComment(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		s := new(sessionMock)
+		p := new(postMock)
+		c := new(commentMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/comments/moderate", nil)
+		r.PostForm = url.Values{"id": {"x"}, "post": {"test"}, "action": {"boop"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+		})
+
+		p.On("GetPost").Return(&post.Post{ID: "test"})
+
+		ModerateComment(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	for action, call := range map[string][]interface{}{
+		"hide":   {"HideComment", "test", "x", true},
+		"unhide": {"HideComment", "test", "x", false},
+		"pin":    {"PinComment", "test", "x", true},
+		"unpin":  {"PinComment", "test", "x", false},
+		"delete": {"RemoveComment", "test", "x"},
+	} {
+		action, call := action, call
+		t.Run(action, func(t *testing.T) {
+			s := new(sessionMock)
+			p := new(postMock)
+			c := new(commentMock)
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/comments/moderate", nil)
+			r.PostForm = url.Values{"id": {"x"}, "post": {"test"}, "action": {action}}
+
+			r = container.WithUser(r, &user.User{Username: "test"})
+			r = container.With(r, &container.Container{
+				Session:  s,
+				Posts:    p,
+				Comments: c,
+			})
+
+			p.On("GetPost").Return(&post.Post{ID: "test"})
+			c.On(call[0].(string), call[1:]...).Return(nil)
+			s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+				return true
+			}), mock.MatchedBy(func(r *http.Request) bool {
+				return true
+			}), "success", "Comment updated!")
+
+			ModerateComment(w, r)
+
+			assert.Equal(t, http.StatusSeeOther, w.Code)
+			assert.Equal(t, "/test/test#comments", w.Header().Get("Location"))
+			c.AssertExpectations(t)
+		})
+	}
+}
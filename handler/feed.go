@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	"bishack.dev/utils"
+)
+
+// feedSize is the number of entries on a feed
+const feedSize = 20
+
+// feedCacheAge lets proxies and aggregators reuse a feed for a while
+// before asking again
+const feedCacheAge = 5 * time.Minute
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Creator     string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// feed is what the Atom and RSS renderers have in common
+type feed struct {
+	Title       string
+	Description string
+	Link        string
+	Self        string
+	Posts       []*post.Post
+}
+
+// Feed is the Atom feed of the latest posts
+func Feed(w http.ResponseWriter, r *http.Request) {
+	f, ok := siteFeed(w, r, "/feed.xml")
+	if !ok {
+		return
+	}
+
+	writeFeed(w, r, atom(f, utils.BaseURL()), "application/atom+xml", f.Posts)
+}
+
+// RSS is the RSS 2.0 feed of the latest posts
+func RSS(w http.ResponseWriter, r *http.Request) {
+	f, ok := siteFeed(w, r, "/rss.xml")
+	if !ok {
+		return
+	}
+
+	writeFeed(w, r, rss(f, utils.BaseURL()), "application/rss+xml", f.Posts)
+}
+
+// UserFeed is the Atom feed of the latest posts of a user
+func UserFeed(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get(":username")
+
+	us := container.Users(r.Context())
+
+	u := us.GetUser(username)
+	if u == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	ps := container.Posts(r.Context())
+
+	posts, _, err := ps.GetUserPostsPage(u.Username, "", feedSize)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeFeed(w, r, atom(&feed{
+		Title:       u.Name + " on bishack.dev",
+		Description: u.Bio,
+		Link:        "/" + u.Username,
+		Self:        "/" + u.Username + "/feed.xml",
+		Posts:       posts,
+	}, utils.BaseURL()), "application/atom+xml", posts)
+}
+
+// TagFeed is the Atom feed of the latest posts under a tag
+func TagFeed(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get(":tag")
+
+	posts := taggedPosts(r, name)
+	if len(posts) == 0 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if len(posts) > feedSize {
+		posts = posts[:feedSize]
+	}
+
+	writeFeed(w, r, atom(&feed{
+		Title: "#" + name + " on bishack.dev",
+		Link:  "/t/" + name,
+		Self:  "/t/" + name + "/feed.xml",
+		Posts: posts,
+	}, utils.BaseURL()), "application/atom+xml", posts)
+}
+
+func siteFeed(w http.ResponseWriter, r *http.Request, self string) (*feed, bool) {
+	ps := container.Posts(r.Context())
+
+	posts, _, err := ps.GetPostsPage("", feedSize)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return &feed{
+		Title:       "Bisdak Tech Community",
+		Description: "We are a community of bisdak developers, designers, tinkerers, and hackers",
+		Link:        "/",
+		Self:        self,
+		Posts:       posts,
+	}, true
+}
+
+func atom(f *feed, base string) *atomFeed {
+	out := &atomFeed{
+		ID:      base + f.Self,
+		Title:   f.Title,
+		Updated: feedTime(lastModified(f.Posts)).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: base + f.Self, Rel: "self", Type: "application/atom+xml"},
+			{Href: base + f.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+
+	for _, p := range f.Posts {
+		link := postURL(base, p)
+
+		e := atomEntry{
+			ID:        link,
+			Title:     p.Title,
+			Published: feedTime(p.Created).Format(time.RFC3339),
+			Updated:   feedTime(updated(p)).Format(time.RFC3339),
+			Author:    atomAuthor{Name: p.Author, URI: base + "/" + p.Username},
+			Links:     []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Content:   atomContent{Type: "html", Body: utils.Markdown(p.Content)},
+		}
+
+		if p.Cover != "" {
+			e.Links = append(e.Links, atomLink{
+				Href: p.Cover,
+				Rel:  "enclosure",
+				Type: imageType(p.Cover),
+			})
+		}
+
+		for _, t := range p.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: t})
+		}
+
+		out.Entries = append(out.Entries, e)
+	}
+
+	return out
+}
+
+func rss(f *feed, base string) *rssFeed {
+	out := &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          base + f.Link,
+			Description:   f.Description,
+			LastBuildDate: feedTime(lastModified(f.Posts)).Format(time.RFC1123Z),
+		},
+	}
+
+	for _, p := range f.Posts {
+		link := postURL(base, p)
+
+		item := rssItem{
+			Title:       p.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     feedTime(p.Created).Format(time.RFC1123Z),
+			Creator:     p.Author,
+			Categories:  p.Tags,
+			Description: utils.Markdown(p.Content),
+		}
+
+		// the size of the cover isn't known, 0 is what most feeds use then
+		if p.Cover != "" {
+			item.Enclosure = &rssEnclosure{URL: p.Cover, Type: imageType(p.Cover)}
+		}
+
+		out.Channel.Items = append(out.Channel.Items, item)
+	}
+
+	return out
+}
+
+// writeFeed writes the feed with validators so aggregators polling it get
+// a 304 as long as nothing changed
+func writeFeed(
+	w http.ResponseWriter,
+	r *http.Request,
+	v interface{},
+	contentType string,
+	posts []*post.Post,
+) {
+	writeXML(w, r, v, contentType, feedTime(lastModified(posts)))
+}
+
+// writeXML writes v as a cacheable XML document. The ETag is a hash of the
+// body and ServeContent answers If-None-Match and If-Modified-Since with it.
+func writeXML(
+	w http.ResponseWriter,
+	r *http.Request,
+	v interface{},
+	contentType string,
+	modified time.Time,
+) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha1.Sum(body)
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedCacheAge.Seconds())))
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+func postURL(base string, p *post.Post) string {
+	return fmt.Sprintf("%s/%s/%s", base, p.Username, p.ID)
+}
+
+func updated(p *post.Post) int64 {
+	if p.Updated > p.Created {
+		return p.Updated
+	}
+
+	return p.Created
+}
+
+func lastModified(posts []*post.Post) int64 {
+	var last int64
+	for _, p := range posts {
+		if u := updated(p); u > last {
+			last = u
+		}
+	}
+
+	return last
+}
+
+func feedTime(unix int64) time.Time {
+	return time.Unix(unix, 0).UTC()
+}
+
+func imageType(src string) string {
+	if t := mime.TypeByExtension(path.Ext(src)); t != "" {
+		return t
+	}
+
+	return "image/jpeg"
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	"bishack.dev/services/tag"
+	"bishack.dev/services/user"
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+var feedPosts = []*post.Post{
+	{
+		ID:       "hello-42",
+		Title:    "Hello",
+		Content:  "# Hi\n\nThere",
+		Cover:    "https://s3.bishack.dev/cover.png",
+		Author:   "Test",
+		Username: "test",
+		Tags:     []string{"go"},
+		Publish:  1,
+		Created:  42,
+		Updated:  100,
+	},
+	{
+		ID:       "older-1",
+		Title:    "Older",
+		Author:   "Test",
+		Username: "test",
+		Publish:  1,
+		Created:  1,
+	},
+}
+
+func TestFeed(t *testing.T) {
+	os.Setenv("BASE_URL", "https://bishack.dev")
+	defer os.Unsetenv("BASE_URL")
+
+	t.Run("error", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/feed.xml", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPostsPage", "", int64(feedSize)).Return(nil, "", errors.New(""))
+
+		Feed(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("atom", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		// a forged host doesn't end up in the feed
+		r, _ := http.NewRequest(http.MethodGet, "http://evil.com/feed.xml", nil)
+		r.Header.Set("X-Forwarded-Proto", "http")
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPostsPage", "", int64(feedSize)).Return(feedPosts, "", nil)
+
+		Feed(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, "^application/atom\\+xml", w.Header().Get("Content-Type"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.Equal(t, time.Unix(100, 0).UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+		var f atomFeed
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &f))
+		assert.Equal(t, "1970-01-01T00:01:40Z", f.Updated)
+		assert.Equal(t, 2, len(f.Entries))
+
+		e := f.Entries[0]
+		assert.Equal(t, "https://bishack.dev/test/hello-42", e.ID)
+		assert.Equal(t, "Test", e.Author.Name)
+		assert.Equal(t, "1970-01-01T00:00:42Z", e.Published)
+		assert.Regexp(t, regexp.MustCompile(`<h1 id="hi">Hi`), e.Content.Body)
+		assert.Equal(t, "enclosure", e.Links[1].Rel)
+		assert.Equal(t, "image/png", e.Links[1].Type)
+		assert.Equal(t, "go", e.Categories[0].Term)
+
+		// posts that were never edited use their created time
+		assert.Equal(t, "1970-01-01T00:00:01Z", f.Entries[1].Updated)
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		p := new(postMock)
+		p.On("GetPostsPage", "", int64(feedSize)).Return(feedPosts, "", nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/feed.xml", nil)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		Feed(w, r)
+		etag := w.Header().Get("ETag")
+
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest(http.MethodGet, "/feed.xml", nil)
+		r.Header.Set("If-None-Match", etag)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		Feed(w, r)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest(http.MethodGet, "/feed.xml", nil)
+		r.Header.Set("If-Modified-Since", time.Unix(200, 0).UTC().Format(http.TimeFormat))
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		Feed(w, r)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+}
+
+func TestRSS(t *testing.T) {
+	os.Setenv("BASE_URL", "http://bishack.dev")
+	defer os.Unsetenv("BASE_URL")
+
+	p := new(postMock)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "http://bishack.dev/rss.xml", nil)
+
+	r = container.With(r, &container.Container{
+		Posts: p,
+	})
+	p.On("GetPostsPage", "", int64(feedSize)).Return(feedPosts, "", nil)
+
+	RSS(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, "^application/rss\\+xml", w.Header().Get("Content-Type"))
+
+	var f rssFeed
+	assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &f))
+	assert.Equal(t, "2.0", f.Version)
+	assert.Equal(t, 2, len(f.Channel.Items))
+
+	item := f.Channel.Items[0]
+	assert.Equal(t, "http://bishack.dev/test/hello-42", item.GUID.Value)
+	assert.Equal(t, "Test", item.Creator)
+	assert.Regexp(t, regexp.MustCompile(`<h1 id="hi">Hi`), item.Description)
+	assert.Equal(t, "https://s3.bishack.dev/cover.png", item.Enclosure.URL)
+	assert.Nil(t, f.Channel.Items[1].Enclosure)
+}
+
+func TestUserFeed(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		u := new(userServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/nope/feed.xml", nil)
+
+		r = container.With(r, &container.Container{
+			Users: u,
+		})
+		u.On("GetUser", "").Return(nil)
+
+		UserFeed(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		u := new(userServiceMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/test/feed.xml", nil)
+
+		r = container.With(r, &container.Container{
+			Users: u,
+			Posts: p,
+		})
+		u.On("GetUser", "").Return(&user.User{Username: "test", Name: "Test"})
+		p.On("GetUserPostsPage", "test", "", int64(feedSize)).Return(feedPosts[:1], "", nil)
+
+		UserFeed(w, r)
+
+		var f atomFeed
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &f))
+		assert.Equal(t, "Test on bishack.dev", f.Title)
+		assert.Equal(t, 1, len(f.Entries))
+	})
+}
+
+func TestTagFeed(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		tg := new(tagMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/t/nope/feed.xml", nil)
+
+		r = container.With(r, &container.Container{
+			Tags: tg,
+		})
+		tg.On("GetTagged", "").Return(nil, nil)
+
+		TagFeed(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		tg := new(tagMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/t/go/feed.xml", nil)
+
+		r = container.With(r, &container.Container{
+			Tags:  tg,
+			Posts: p,
+		})
+		tg.On("GetTagged", "").Return([]*tag.Tag{{ID: "hello-42", Created: 42}}, nil)
+		p.On("BatchGetPosts", []*post.Key{{ID: "hello-42", Created: 42}}).Return(feedPosts[:1])
+
+		TagFeed(w, r)
+
+		var f atomFeed
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &f))
+		assert.Equal(t, 1, len(f.Entries))
+	})
+}
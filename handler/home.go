@@ -3,58 +3,51 @@ package handler
 import (
 	"log"
 	"net/http"
-	"sync"
 
-	"bishack.dev/services/like"
-	"bishack.dev/services/post"
+	"bishack.dev/container"
+	"bishack.dev/services/dynamo"
 	"bishack.dev/utils"
-	"bishack.dev/utils/session"
-	"github.com/gorilla/context"
 )
 
+// pageSize is the number of posts shown per page on the feeds
+const pageSize = 20
+
 // Home ...
 func Home(w http.ResponseWriter, r *http.Request) {
-	sess := context.Get(r, "session").(interface {
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
 	// get user details from context and cast it as map[string]string if
 	// not nil
-	u := context.Get(r, "user")
+	u := container.User(r.Context())
 
-	ps := context.Get(r, "postService").(interface {
-		GetPosts() []*post.Post
-	})
+	ps := container.Posts(r.Context())
 
-	posts := ps.GetPosts()
+	posts, next, err := ps.GetPostsPage(r.URL.Query().Get("after"), pageSize)
+	if err == dynamo.ErrInvalidCursor {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
 
-	ls := context.Get(r, "likeService").(interface {
-		GetLikes(id string) ([]*like.Like, error)
-	})
-	// populate likes/comments count
-	var wg sync.WaitGroup
-	wg.Add(len(posts))
+	// likes and comments are counted on the post item
 	for _, p := range posts {
-		go func(p *post.Post) {
-			defer wg.Done()
+		p.ReadingTime = computeReadingTime(p.Content)
+	}
 
-			results, err := ls.GetLikes(p.ID)
-			if err != nil {
-				log.Println("GetLikes error", err.Error())
-				return
-			}
+	ts := container.Tags(r.Context())
 
-			p.LikesCount = int64(len(results))
-			p.ReadingTime = computeReadingTime(p.Content)
-		}(p)
+	cloud, err := ts.GetCloud()
+	if err != nil {
+		log.Println("GetCloud error", err.Error())
 	}
-	wg.Wait()
 
 	utils.Render(w, "main", "home", map[string]interface{}{
 		"Title": "Bisdak Tech Community",
 		"Flash": sess.GetFlash(w, r),
 		"User":  u,
 		"Posts": posts,
+		"Next":  next,
+		"Tags":  cloud,
+		"Feed":  "/feed.xml",
 	})
 }
 
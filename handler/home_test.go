@@ -1,17 +1,17 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
 	"testing"
 
-	"bishack.dev/services/like"
+	"bishack.dev/container"
+	"bishack.dev/services/dynamo"
 	"bishack.dev/services/post"
+	"bishack.dev/services/tag"
 	"bishack.dev/services/user"
 	_ "bishack.dev/testing"
-	"github.com/gorilla/context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,15 +21,22 @@ func TestHome(t *testing.T) {
 		s := new(sessionMock)
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
+		tg := new(tagMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
+		tg.On("GetCloud").Return(nil, nil)
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Tags:     tg,
+			Session:  s,
+		})
 
-		p.On("GetPosts").Return(nil)
+		p.On("GetPostsPage", "", int64(pageSize)).Return(nil, "", nil)
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
@@ -46,19 +53,26 @@ func TestHome(t *testing.T) {
 		s := new(sessionMock)
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
+		tg := new(tagMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "user", &user.User{
+		r = container.WithUser(r, &user.User{
 			Username: "tibur",
 		})
 
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
+		tg.On("GetCloud").Return([]*tag.Count{{Tag: "golang", Count: 3}}, nil)
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Tags:     tg,
+			Session:  s,
+		})
 
-		p.On("GetPosts").Return(nil)
+		p.On("GetPostsPage", "", int64(pageSize)).Return(nil, "", nil)
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
@@ -68,35 +82,42 @@ func TestHome(t *testing.T) {
 		Home(w, r)
 
 		assert.Regexp(t, regexp.MustCompile("@tibur"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile(`href="/t/golang"`), w.Body.String())
 
 		s.AssertExpectations(t)
 	})
 
-	t.Run("likes with error", func(t *testing.T) {
+	t.Run("comments with error", func(t *testing.T) {
 		s := new(sessionMock)
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
+		tg := new(tagMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "user", &user.User{
+		r = container.WithUser(r, &user.User{
 			Username: "tibur",
 		})
 
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
+		tg.On("GetCloud").Return(nil, nil)
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Tags:     tg,
+			Session:  s,
+		})
 
-		p.On("GetPosts").Return([]*post.Post{
+		p.On("GetPostsPage", "", int64(pageSize)).Return([]*post.Post{
 			{ID: "test"},
-		})
+		}, "", nil)
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
 			return true
 		})).Return(nil)
-		l.On("GetLikes", "test").Return(nil, errors.New(""))
 
 		Home(w, r)
 
@@ -105,40 +126,106 @@ func TestHome(t *testing.T) {
 		s.AssertExpectations(t)
 	})
 
-	t.Run("likes ok", func(t *testing.T) {
+	t.Run("counters", func(t *testing.T) {
 		s := new(sessionMock)
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
+		tg := new(tagMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "user", &user.User{
+		r = container.WithUser(r, &user.User{
 			Username: "tibur",
 		})
 
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
-
-		p.On("GetPosts").Return([]*post.Post{
-			{ID: "test"},
+		tg.On("GetCloud").Return(nil, nil)
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Tags:     tg,
+			Session:  s,
 		})
+
+		p.On("GetPostsPage", "", int64(pageSize)).Return([]*post.Post{
+			{ID: "test", LikesCount: 3, CommentsCount: 2},
+		}, "", nil)
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
 			return true
 		})).Return(nil)
-		l.On("GetLikes", "test").Return([]*like.Like{{}}, nil)
 
 		Home(w, r)
 
 		assert.Regexp(t, regexp.MustCompile("@tibur"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("♥ 3"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("💬 2"), w.Body.String())
+		l.AssertNotCalled(t, "GetLikes", mock.Anything)
+		c.AssertNotCalled(t, "GetComments", mock.Anything)
 
 		s.AssertExpectations(t)
 	})
 }
 
+func TestHomePagination(t *testing.T) {
+	t.Run("invalid cursor", func(t *testing.T) {
+		s := new(sessionMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/?after=nope", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+
+		p.On("GetPostsPage", "nope", int64(pageSize)).Return(nil, "", dynamo.ErrInvalidCursor)
+
+		Home(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("load more", func(t *testing.T) {
+		s := new(sessionMock)
+		p := new(postMock)
+		l := new(likeMock)
+		c := new(commentMock)
+		tg := new(tagMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/?after=first", nil)
+
+		r = container.WithUser(r, &user.User{Username: "tibur"})
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Tags:     tg,
+			Session:  s,
+		})
+
+		p.On("GetPostsPage", "first", int64(pageSize)).Return([]*post.Post{
+			{ID: "test"},
+		}, "second", nil)
+		tg.On("GetCloud").Return(nil, nil)
+		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		})).Return(nil)
+
+		Home(w, r)
+
+		assert.Regexp(t, regexp.MustCompile(`href="\?after=second"`), w.Body.String())
+		p.AssertExpectations(t)
+	})
+}
+
 func TestNotFound(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest(http.MethodGet, "/xxx", nil)
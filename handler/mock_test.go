@@ -4,8 +4,11 @@ import (
 	"net/http"
 	"net/url"
 
+	"bishack.dev/services/comment"
 	"bishack.dev/services/like"
 	"bishack.dev/services/post"
+	"bishack.dev/services/tag"
+	"bishack.dev/services/token"
 	"bishack.dev/services/user"
 	"bishack.dev/utils/session"
 	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
@@ -86,6 +89,11 @@ func (o *userServiceMock) GetUser(username string) *user.User {
 	return resp.(*user.User)
 }
 
+func (o *userServiceMock) GetToken(username, token string) (string, error) {
+	args := o.Called(username, token)
+	return args.String(0), args.Error(1)
+}
+
 func (o *userServiceMock) Verify(
 	username,
 	code string,
@@ -154,6 +162,22 @@ func (o *sessionMock) GetFlash(
 	return resp.(*session.Flash)
 }
 
+func (o *sessionMock) GetSessions(username string) ([]*session.Session, error) {
+	args := o.Called(username)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.([]*session.Session), args.Error(1)
+}
+
+func (o *sessionMock) RevokeSession(username, id string) error {
+	args := o.Called(username, id)
+	return args.Error(0)
+}
+
 type clientMock struct {
 	mock.Mock
 }
@@ -212,15 +236,15 @@ func (p *postMock) CreatePost(vals map[string]interface{}) *post.Post {
 	return resp.(*post.Post)
 }
 
-func (p *postMock) GetPosts() []*post.Post {
+func (p *postMock) GetPosts() ([]*post.Post, error) {
 	args := p.Called()
 	resp := args.Get(0)
 
 	if resp == nil {
-		return nil
+		return nil, args.Error(1)
 	}
 
-	return resp.([]*post.Post)
+	return resp.([]*post.Post), args.Error(1)
 }
 
 func (p *postMock) GetUserPosts(username string) []*post.Post {
@@ -234,12 +258,50 @@ func (p *postMock) GetUserPosts(username string) []*post.Post {
 	return resp.([]*post.Post)
 }
 
-func (p *postMock) UpdatePost(id, cover, content string, created int64) error {
-	args := p.Called(id, cover, content, created)
+func (p *postMock) GetPostsPage(after string, limit int64) ([]*post.Post, string, error) {
+	args := p.Called(after, limit)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+
+	return resp.([]*post.Post), args.String(1), args.Error(2)
+}
+
+func (p *postMock) GetUserPostsPage(username, after string, limit int64) ([]*post.Post, string, error) {
+	args := p.Called(username, after, limit)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+
+	return resp.([]*post.Post), args.String(1), args.Error(2)
+}
+
+func (p *postMock) UpdatePost(username, id string, created int64, params map[string]interface{}) error {
+	args := p.Called(username, id, created, params)
 	_ = args.Get(0)
 	return args.Error(0)
 }
 
+func (p *postMock) DeletePost(username, id string, created int64) error {
+	args := p.Called(username, id, created)
+	return args.Error(0)
+}
+
+func (p *postMock) GetDrafts(username string) []*post.Post {
+	args := p.Called(username)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil
+	}
+
+	return resp.([]*post.Post)
+}
+
 func (p *postMock) GetPost(username, id string) *post.Post {
 	args := p.Called()
 	resp := args.Get(0)
@@ -251,6 +313,17 @@ func (p *postMock) GetPost(username, id string) *post.Post {
 	return resp.(*post.Post)
 }
 
+func (p *postMock) BatchGetPosts(keys []*post.Key) []*post.Post {
+	args := p.Called(keys)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil
+	}
+
+	return resp.([]*post.Post)
+}
+
 type likeMock struct {
 	mock.Mock
 }
@@ -281,3 +354,149 @@ func (l *likeMock) ToggleLike(id, username string) error {
 	args := l.Called(id, username)
 	return args.Error(0)
 }
+
+func (l *likeMock) Like(id, username string) error {
+	args := l.Called(id, username)
+	return args.Error(0)
+}
+
+func (l *likeMock) Unlike(id, username string) error {
+	args := l.Called(id, username)
+	return args.Error(0)
+}
+
+type commentMock struct {
+	mock.Mock
+}
+
+func (c *commentMock) CreateComment(params map[string]interface{}) (*comment.Comment, error) {
+	args := c.Called(params)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*comment.Comment), args.Error(1)
+}
+
+func (c *commentMock) GetComments(post string) ([]*comment.Comment, error) {
+	args := c.Called(post)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.([]*comment.Comment), args.Error(1)
+}
+
+func (c *commentMock) UpdateComment(post, id, username, content string) error {
+	args := c.Called(post, id, username, content)
+	return args.Error(0)
+}
+
+func (c *commentMock) DeleteComment(post, id, username string) error {
+	args := c.Called(post, id, username)
+	return args.Error(0)
+}
+
+func (c *commentMock) HideComment(post, id string, hidden bool) error {
+	args := c.Called(post, id, hidden)
+	return args.Error(0)
+}
+
+func (c *commentMock) PinComment(post, id string, pinned bool) error {
+	args := c.Called(post, id, pinned)
+	return args.Error(0)
+}
+
+func (c *commentMock) RemoveComment(post, id string) error {
+	args := c.Called(post, id)
+	return args.Error(0)
+}
+
+type tagMock struct {
+	mock.Mock
+}
+
+func (t *tagMock) SetTags(
+	id string,
+	created int64,
+	username string,
+	publish int,
+	previous,
+	tags []string,
+) error {
+	args := t.Called(id, created, username, publish, previous, tags)
+	return args.Error(0)
+}
+
+func (t *tagMock) RemoveTags(id string, tags []string) error {
+	args := t.Called(id, tags)
+	return args.Error(0)
+}
+
+func (t *tagMock) GetTagged(name string) ([]*tag.Tag, error) {
+	args := t.Called(name)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.([]*tag.Tag), args.Error(1)
+}
+
+func (t *tagMock) GetCloud() ([]*tag.Count, error) {
+	args := t.Called()
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.([]*tag.Count), args.Error(1)
+}
+
+type tokenMock struct {
+	mock.Mock
+}
+
+func (t *tokenMock) CreateToken(username, name string, scopes []string) (string, *token.Token, error) {
+	args := t.Called(username, name, scopes)
+	resp := args.Get(1)
+
+	if resp == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+
+	return args.String(0), resp.(*token.Token), args.Error(2)
+}
+
+func (t *tokenMock) GetTokens(username string) ([]*token.Token, error) {
+	args := t.Called(username)
+	resp := args.Get(0)
+
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.([]*token.Token), args.Error(1)
+}
+
+func (t *tokenMock) RevokeToken(username, id string) error {
+	args := t.Called(username, id)
+	return args.Error(0)
+}
+
+func (t *tokenMock) Verify(secret string) (*token.Token, error) {
+	args := t.Called(secret)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*token.Token), args.Error(1)
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"bishack.dev/container"
+	"bishack.dev/utils/ogimage"
+)
+
+// ogCacheAge is how long clients can keep a share image. The url changes
+// along with the post so this can be long.
+const ogCacheAge = 7 * 24 * 60 * 60
+
+// ogMaxAvatar caps the size of avatars we're willing to download
+const ogMaxAvatar = 2 << 20
+
+// ogCache keeps rendered images for as long as the process lives
+var ogCache = ogimage.NewCache(128)
+
+// ogAvatarHosts are the only hosts avatars are downloaded from. The picture
+// of a user comes from the signup form so it can point anywhere.
+var ogAvatarHosts = map[string]bool{
+	"avatars.githubusercontent.com": true,
+}
+
+// ogClient downloads avatars. It only follows redirects to ogAvatarHosts and
+// won't connect to private, loopback or link-local addresses whatever a host
+// resolves to.
+var ogClient container.HTTPClient = &http.Client{
+	Timeout: 5 * time.Second,
+	CheckRedirect: func(r *http.Request, via []*http.Request) error {
+		if len(via) >= 3 {
+			return errors.New("too many redirects")
+		}
+		if !avatarURL(r.URL) {
+			return fmt.Errorf("redirect to %s isn't allowed", r.URL.Host)
+		}
+
+		return nil
+	},
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: dialPublic,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// ogPrivateNets are the networks ogClient refuses to connect to
+var ogPrivateNets = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+var (
+	ogLogoOnce sync.Once
+	ogLogo     image.Image
+)
+
+// PostImage renders the share image of a published post
+func PostImage(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get(":id")
+	username := r.URL.Query().Get(":username")
+
+	ps := container.Posts(r.Context())
+
+	p := ps.GetPost(username, id)
+	if p == nil || p.Publish != 1 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	modified := updated(p)
+	key := fmt.Sprintf("%s-%d", p.ID, modified)
+	etag := `"` + key + `"`
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", ogCacheAge))
+	w.Header().Set("ETag", etag)
+
+	// answer revalidations before doing any drawing
+	if strings.Contains(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	b, ok := ogCache.Get(key)
+	if !ok {
+		var err error
+		b, err = ogimage.Render(&ogimage.Card{
+			Title:       p.Title,
+			Author:      p.Author,
+			Username:    p.Username,
+			ReadingTime: computeReadingTime(p.Content),
+			Avatar:      fetchImage(p.UserPic),
+			Logo:        logo(),
+		})
+		if err != nil {
+			log.Println("Render error", err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		ogCache.Put(key, b)
+	}
+
+	http.ServeContent(w, r, "", feedTime(modified), bytes.NewReader(b))
+}
+
+// fetchImage downloads and decodes an avatar, the card does without it if
+// it isn't on an allowed host, is bigger than the card or anything else
+// goes wrong
+func fetchImage(src string) image.Image {
+	u, err := url.Parse(src)
+	if err != nil || !avatarURL(u) {
+		return nil
+	}
+
+	resp, err := ogClient.Get(u.String())
+	if err != nil {
+		log.Println("fetchImage error", err.Error())
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, ogMaxAvatar))
+	if err != nil {
+		log.Println("fetchImage read error", err.Error())
+		return nil
+	}
+
+	// check the size before decoding, a small file can claim a huge image
+	config, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		log.Println("fetchImage decode error", err.Error())
+		return nil
+	}
+	if config.Width > ogimage.Width || config.Height > ogimage.Height {
+		log.Println("fetchImage error", "avatar too large", config.Width, config.Height)
+		return nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		log.Println("fetchImage decode error", err.Error())
+		return nil
+	}
+
+	return img
+}
+
+// avatarURL checks that u is https on one of ogAvatarHosts
+func avatarURL(u *url.URL) bool {
+	return u.Scheme == "https" && ogAvatarHosts[u.Hostname()]
+}
+
+// dialPublic refuses connections to ogPrivateNets. It runs after the host
+// is resolved, so a public name pointing at a private address is caught too.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%s isn't an ip address", host)
+	}
+
+	for _, n := range ogPrivateNets {
+		if n.Contains(ip) {
+			return fmt.Errorf("%s is a private address", ip)
+		}
+	}
+
+	return nil
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		nets[i] = n
+	}
+
+	return nets
+}
+
+func logo() image.Image {
+	ogLogoOnce.Do(func() {
+		f, err := os.Open("public/images/icon.png")
+		if err != nil {
+			log.Println("logo error", err.Error())
+			return
+		}
+		defer f.Close()
+
+		ogLogo, _, _ = image.Decode(f)
+	})
+
+	return ogLogo
+}
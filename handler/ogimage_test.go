@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	_ "bishack.dev/testing"
+	"bishack.dev/utils/ogimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostImage(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		for _, p := range []*post.Post{nil, {ID: "draft"}} {
+			ps := new(postMock)
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/ing/draft/og.png", nil)
+
+			r = container.With(r, &container.Container{
+				Posts: ps,
+			})
+			if p == nil {
+				ps.On("GetPost", mock.Anything).Return(nil)
+			} else {
+				ps.On("GetPost", mock.Anything).Return(p)
+			}
+
+			PostImage(w, r)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		avatar := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 8, 8)))
+		}))
+		defer avatar.Close()
+		defer trustAvatars(avatar)()
+
+		ps := new(postMock)
+		ps.On("GetPost", mock.Anything).Return(&post.Post{
+			ID:       "og-test-42",
+			Title:    "Hello",
+			Author:   "Ing",
+			Username: "ing",
+			UserPic:  avatar.URL,
+			Publish:  1,
+			Created:  42,
+			Updated:  43,
+		})
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/ing/og-test-42/og.png", nil)
+		r = container.With(r, &container.Container{
+			Posts: ps,
+		})
+
+		PostImage(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, `"og-test-42-43"`, w.Header().Get("ETag"))
+
+		img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+		assert.Nil(t, err)
+		assert.Equal(t, 1200, img.Bounds().Dx())
+
+		_, ok := ogCache.Get("og-test-42-43")
+		assert.True(t, ok)
+
+		// revalidation doesn't render again
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest(http.MethodGet, "/ing/og-test-42/og.png", nil)
+		r.Header.Set("If-None-Match", `"og-test-42-43"`)
+		r = container.With(r, &container.Container{
+			Posts: ps,
+		})
+
+		PostImage(w, r)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+	})
+}
+
+// trustAvatars lets ogClient download from a test server, returning a func
+// that undoes it
+func trustAvatars(s *httptest.Server) func() {
+	client := ogClient
+	ogClient = s.Client()
+	ogAvatarHosts["127.0.0.1"] = true
+
+	return func() {
+		ogClient = client
+		delete(ogAvatarHosts, "127.0.0.1")
+	}
+}
+
+func TestFetchImage(t *testing.T) {
+	avatar := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken":
+			_, _ = w.Write([]byte("not an image"))
+		case "/huge":
+			_ = png.Encode(w, image.NewGray(image.Rect(0, 0, ogimage.Width+1, 1)))
+		default:
+			_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 8, 8)))
+		}
+	}))
+	defer avatar.Close()
+	defer trustAvatars(avatar)()
+
+	assert.NotNil(t, fetchImage(avatar.URL+"/ok"))
+
+	assert.Nil(t, fetchImage(""))
+	assert.Nil(t, fetchImage("/images/icon.png"))
+	assert.Nil(t, fetchImage(avatar.URL+"/broken"))
+	assert.Nil(t, fetchImage(avatar.URL+"/huge"))
+
+	// only https on an allowed host
+	assert.Nil(t, fetchImage(strings.Replace(avatar.URL, "https", "http", 1)+"/ok"))
+	assert.Nil(t, fetchImage(strings.Replace(avatar.URL, "127.0.0.1", "localhost", 1)+"/ok"))
+	assert.Nil(t, fetchImage("https://169.254.169.254/latest/meta-data/"))
+}
+
+func TestOgClient(t *testing.T) {
+	t.Run("redirects", func(t *testing.T) {
+		for to, ok := range map[string]bool{
+			"https://avatars.githubusercontent.com/u/1": true,
+			"http://avatars.githubusercontent.com/u/1":  false,
+			"https://169.254.169.254/latest/meta-data/": false,
+		} {
+			r, _ := http.NewRequest(http.MethodGet, to, nil)
+			err := ogClient.(*http.Client).CheckRedirect(r, nil)
+			assert.Equal(t, ok, err == nil, to)
+		}
+	})
+
+	t.Run("private addresses", func(t *testing.T) {
+		for address, ok := range map[string]bool{
+			"140.82.112.3:443":          true,
+			"[2606:50c0:8000::154]:443": true,
+			"127.0.0.1:443":             false,
+			"10.0.0.1:443":              false,
+			"169.254.169.254:80":        false,
+			"192.168.1.1:443":           false,
+			"[::1]:443":                 false,
+			"[::ffff:127.0.0.1]:443":    false,
+			"[fe80::1]:443":             false,
+		} {
+			err := dialPublic("tcp", address, nil)
+			assert.Equal(t, ok, err == nil, address)
+		}
+	})
+}
@@ -4,22 +4,20 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 
-	"bishack.dev/services/like"
+	"bishack.dev/container"
+	"bishack.dev/services/comment"
 	"bishack.dev/services/post"
-	"bishack.dev/services/user"
+	"bishack.dev/services/tag"
 	"bishack.dev/utils"
-	"bishack.dev/utils/session"
-	"github.com/gorilla/context"
+	"bishack.dev/utils/meta"
 	"github.com/gorilla/csrf"
 )
 
 // New ...
 func New(w http.ResponseWriter, r *http.Request) {
-	u := context.Get(r, "user")
+	u := container.User(r.Context())
 	if u == nil {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
@@ -34,43 +32,141 @@ func New(w http.ResponseWriter, r *http.Request) {
 
 // UpdatePost ...
 func UpdatePost(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
 	_ = r.ParseForm()
 
 	id := r.FormValue("id")
-	cover := r.FormValue("cover")
-	created, _ := strconv.Atoi(r.FormValue("created"))
-	content := r.FormValue("content")
 
-	ps := context.Get(r, "postService").(interface {
-		UpdatePost(string, string, string, int64) error
-	})
+	tags := tag.Parse(r.FormValue("tags"))
 
-	sess := context.Get(r, "session").(interface {
-		SetFlash(http.ResponseWriter, *http.Request, string, string)
-	})
+	params := map[string]interface{}{
+		"cover":   r.FormValue("cover"),
+		"content": r.FormValue("content"),
+		"tags":    tags,
+	}
 
-	err := ps.UpdatePost(id, cover, content, int64(created))
-	if err != nil {
+	ps := container.Posts(r.Context())
+
+	// only the author can update the post
+	p := ps.GetPost(u.Username, id)
+	if p == nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	// publish/unpublish buttons
+	state := p.Publish
+	publish := r.FormValue("publish")
+	switch publish {
+	case "":
+	case "0", "1":
+		state, _ = strconv.Atoi(publish)
+		params["publish"] = state
+	default:
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	sess := container.Session(r.Context())
+
+	err := ps.UpdatePost(u.Username, id, p.Created, params)
+	if err == nil {
+		ts := container.Tags(r.Context())
+
+		if err := ts.SetTags(id, p.Created, p.Username, state, p.Tags, tags); err != nil {
+			log.Println("SetTags error", err.Error())
+		}
+
+		p.Cover = params["cover"].(string)
+		p.Content = params["content"].(string)
+		p.Tags = tags
+		p.Publish = state
+
+		idx := container.Search(r.Context())
+		idx.Add(p)
+	}
+
+	switch {
+	case err == post.ErrForbidden:
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	case err != nil:
 		sess.SetFlash(w, r, "error", "An error occurred. Try again.")
-	} else {
+	case publish == "1":
+		sess.SetFlash(w, r, "success", "Post published!")
+	case publish == "0":
+		sess.SetFlash(w, r, "success", "Post moved back to drafts!")
+	default:
 		sess.SetFlash(w, r, "success", "Changes saved successfully!")
 	}
 
 	http.Redirect(w, r, "/edit/"+id, http.StatusSeeOther)
 }
 
+// DeletePost ...
+func DeletePost(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	id := r.FormValue("id")
+
+	ps := container.Posts(r.Context())
+
+	sess := container.Session(r.Context())
+
+	// only the author can delete the post
+	p := ps.GetPost(u.Username, id)
+	if p == nil {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	err := ps.DeletePost(u.Username, id, p.Created)
+	if err == post.ErrForbidden {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if err == nil {
+		ts := container.Tags(r.Context())
+
+		if err := ts.RemoveTags(id, p.Tags); err != nil {
+			log.Println("RemoveTags error", err.Error())
+		}
+
+		idx := container.Search(r.Context())
+		idx.Remove(id)
+	}
+
+	if err != nil {
+		sess.SetFlash(w, r, "error", "An error occurred. Try again.")
+		http.Redirect(w, r, "/edit/"+id, http.StatusSeeOther)
+		return
+	}
+
+	sess.SetFlash(w, r, "success", "Post deleted!")
+	http.Redirect(w, r, "/"+u.Username, http.StatusSeeOther)
+}
+
 // EditPost ...
 func EditPost(w http.ResponseWriter, r *http.Request) {
-	uc := context.Get(r, "user")
-	if uc == nil {
+	u := container.User(r.Context())
+	if u == nil {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
-	u := uc.(*user.User)
 
-	ps := context.Get(r, "postService").(interface {
-		GetPost(username, id string) *post.Post
-	})
+	ps := container.Posts(r.Context())
 
 	username := u.Username
 	id := r.URL.Query().Get(":id")
@@ -81,9 +177,7 @@ func EditPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sess := context.Get(r, "session").(interface {
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
 	flash := sess.GetFlash(w, r)
 
@@ -98,6 +192,12 @@ func EditPost(w http.ResponseWriter, r *http.Request) {
 
 // CreatePost ...
 func CreatePost(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
 	_ = r.ParseForm()
 	attr := map[string]interface{}{}
 
@@ -108,15 +208,19 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	attr["title"] = r.PostForm.Get("title")
 	attr["cover"] = r.PostForm.Get("cover")
-	attr["author"] = r.PostForm.Get("author")
 	attr["content"] = content
-	attr["userPic"] = r.PostForm.Get("userPic")
-	attr["username"] = r.PostForm.Get("username")
+	// the author is whoever is signed in, never what the form says
+	attr["author"] = u.Name
+	attr["userPic"] = u.Picture
+	attr["username"] = u.Username
 	attr["readingTime"] = computeReadingTime(content)
 
-	ps := context.Get(r, "postService").(interface {
-		CreatePost(params map[string]interface{}) *post.Post
-	})
+	tags := tag.Parse(r.PostForm.Get("tags"))
+	if len(tags) > 0 {
+		attr["tags"] = tags
+	}
+
+	ps := container.Posts(r.Context())
 
 	p := ps.CreatePost(attr)
 	if p == nil {
@@ -125,17 +229,93 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ts := container.Tags(r.Context())
+
+	err := ts.SetTags(p.ID, p.Created, p.Username, p.Publish, nil, tags)
+	if err != nil {
+		log.Println("SetTags error", err.Error())
+	}
+
+	idx := container.Search(r.Context())
+	idx.Add(p)
+
+	if p.Publish != 1 {
+		http.Redirect(w, r, "/drafts/"+p.ID, http.StatusSeeOther)
+		return
+	}
+
 	http.Redirect(w, r, fmt.Sprintf("/%s/%s", p.Username, p.ID), http.StatusSeeOther)
 }
 
+// Drafts lists the unpublished posts of the current user
+func Drafts(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	ps := container.Posts(r.Context())
+
+	sess := container.Session(r.Context())
+
+	posts := ps.GetDrafts(u.Username)
+	for _, p := range posts {
+		p.ReadingTime = computeReadingTime(p.Content)
+	}
+
+	utils.Render(w, "main", "drafts", map[string]interface{}{
+		"Title": "My Drafts",
+		"Flash": sess.GetFlash(w, r),
+		"User":  u,
+		"Posts": posts,
+	})
+}
+
+// PreviewDraft renders an unpublished post. Only the author can see it.
+func PreviewDraft(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	id := r.URL.Query().Get(":id")
+
+	ps := container.Posts(r.Context())
+
+	post := ps.GetPost(u.Username, id)
+	if post == nil {
+		NotFound(w, r)
+		return
+	}
+
+	// nothing to preview here
+	if post.Publish == 1 {
+		http.Redirect(w, r, fmt.Sprintf("/%s/%s", post.Username, post.ID), http.StatusSeeOther)
+		return
+	}
+
+	post.ReadingTime = computeReadingTime(post.Content)
+
+	sess := container.Session(r.Context())
+
+	utils.Render(w, "main", "post", map[string]interface{}{
+		"Title":          post.Title,
+		"Flash":          sess.GetFlash(w, r),
+		"Post":           post,
+		"User":           u,
+		"Preview":        true,
+		csrf.TemplateTag: csrf.TemplateField(r),
+	})
+}
+
 // GetPost ...
 func GetPost(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get(":id")
 	username := r.URL.Query().Get(":username")
 
-	ps := context.Get(r, "postService").(interface {
-		GetPost(username, id string) *post.Post
-	})
+	ps := container.Posts(r.Context())
 
 	post := ps.GetPost(username, id)
 	if post == nil {
@@ -147,18 +327,25 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	u := container.User(r.Context())
+
+	// drafts are only visible to their author through the preview page
+	if post.Publish != 1 {
+		if u != nil && u.Username == post.Username {
+			http.Redirect(w, r, "/drafts/"+post.ID, http.StatusSeeOther)
+			return
+		}
+
+		NotFound(w, r)
+		return
+	}
+
 	post.ReadingTime = computeReadingTime(post.Content)
 
-	ls := context.Get(r, "likeService").(interface {
-		GetLike(id, username string) (*like.Like, error)
-		GetLikes(id string) ([]*like.Like, error)
-	})
+	ls := container.Likes(r.Context())
 
-	var u *user.User
 	liker := false
-	uc := context.Get(r, "user")
-	if uc != nil {
-		u = uc.(*user.User)
+	if u != nil {
 
 		_, err := ls.GetLike(post.ID, u.Username)
 		if err == nil {
@@ -167,28 +354,32 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 
 	}
 
-	likes, err := ls.GetLikes(post.ID)
-	if err == nil {
-		post.LikesCount = int64(len(likes))
+	cs := container.Comments(r.Context())
+
+	comments, err := cs.GetComments(post.ID)
+	if err != nil {
+		log.Println("GetComments error", err.Error())
 	}
+	post.CommentsCount = int64(len(comments))
 
-	chunks := strings.Split(post.Content, "\r\n\r\n")
-	description := chunks[0]
-	if len(chunks) >= 2 {
-		description = chunks[1]
-		description = strings.Join(
-			regexp.MustCompile(`[a-zA-Z0-9\.\-\s\/\:,;]+`).FindAllString(description, -1),
-			" ",
-		)
+	viewer := ""
+	if u != nil {
+		viewer = u.Username
 	}
+	thread := comment.Thread(comments, viewer, viewer == post.Username)
+
+	m := meta.Post(post, utils.BaseURL())
+
+	sess := container.Session(r.Context())
 
 	utils.Render(w, "main", "post", map[string]interface{}{
 		"Title":          post.Title,
+		"Flash":          sess.GetFlash(w, r),
 		"Post":           post,
-		"Description":    description,
+		"Meta":           m,
 		"User":           u,
-		"Cover":          post.Cover,
 		"Liker":          liker,
+		"Comments":       newCommentViews(thread, post.Username, viewer, csrf.TemplateField(r)),
 		csrf.TemplateTag: csrf.TemplateField(r),
 	})
 }
@@ -198,15 +389,11 @@ func ToggleLike(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get(":id")
 	username := ""
 
-	uc := context.Get(r, "user")
-	if uc != nil {
-		u := uc.(*user.User)
+	if u := container.User(r.Context()); u != nil {
 		username = u.Username
 	}
 
-	ls := context.Get(r, "likeService").(interface {
-		ToggleLike(id, username string) error
-	})
+	ls := container.Likes(r.Context())
 
 	err := ls.ToggleLike(id, username)
 	if err != nil {
@@ -217,6 +404,50 @@ func ToggleLike(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "ok")
 }
 
+// Like likes a post on behalf of the current user. Liking the same post
+// again is a no-op.
+func Like(w http.ResponseWriter, r *http.Request) {
+	setLike(w, r, func(ls likeSetter, id, username string) error {
+		return ls.Like(id, username)
+	})
+}
+
+// Unlike removes the like of the current user from a post. Unliking a post
+// that wasn't liked is a no-op.
+func Unlike(w http.ResponseWriter, r *http.Request) {
+	setLike(w, r, func(ls likeSetter, id, username string) error {
+		return ls.Unlike(id, username)
+	})
+}
+
+type likeSetter interface {
+	Like(id, username string) error
+	Unlike(id, username string) error
+}
+
+func setLike(
+	w http.ResponseWriter,
+	r *http.Request,
+	set func(ls likeSetter, id, username string) error,
+) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	id := r.URL.Query().Get(":id")
+	ls := container.Likes(r.Context())
+
+	err := set(ls, id, u.Username)
+	if err != nil {
+		http.Error(w, "error", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
+
 func computeReadingTime(content string) int {
 	const avgWPM = 265 // 265 wpm
 	wordCount := len(content)
@@ -4,14 +4,19 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"regexp"
+	"strconv"
 	"testing"
 
+	"bishack.dev/container"
+	"bishack.dev/services/comment"
 	"bishack.dev/services/like"
 	"bishack.dev/services/post"
+	"bishack.dev/services/search"
 	"bishack.dev/services/user"
 	_ "bishack.dev/testing"
-	"github.com/gorilla/context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -34,23 +39,46 @@ func TestNew(t *testing.T) {
 			Username: "test",
 		}
 
-		context.Set(r, "user", user)
+		r = container.WithUser(r, user)
 
 		New(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Regexp(t, regexp.MustCompile(`value="test"`), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile(`action="/new"`), w.Body.String())
+		// the author comes from the session, not the form
+		assert.NotRegexp(t, regexp.MustCompile(`name="username"`), w.Body.String())
 	})
 }
 
 func TestCreatePost(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/new", nil)
+		r.PostForm = url.Values{"title": {"test"}, "username": {"ing"}}
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		CreatePost(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+		p.AssertNotCalled(t, "CreatePost", mock.Anything)
+	})
+
 	t.Run("nil", func(t *testing.T) {
 		p := new(postMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/new", nil)
 
-		context.Set(r, "postService", p)
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
 		p.On("CreatePost", mock.MatchedBy(func(vals map[string]interface{}) bool {
 			return true
 		})).Return(nil)
@@ -63,18 +91,38 @@ func TestCreatePost(t *testing.T) {
 
 	t.Run("ok", func(t *testing.T) {
 		p := new(postMock)
+		tg := new(tagMock)
+		idx := search.NewMemory()
 
 		w := httptest.NewRecorder()
-		r, _ := http.NewRequest(http.MethodGet, "/new", nil)
+		r, _ := http.NewRequest(http.MethodPost, "/new", nil)
+		r.PostForm = url.Values{
+			"title":    {"test"},
+			"author":   {"Someone Else"},
+			"username": {"someone"},
+			"userPic":  {"http://169.254.169.254/"},
+		}
 
-		context.Set(r, "postService", p)
+		r = container.WithUser(r, &user.User{
+			Name:     "Tester",
+			Username: "test",
+			Picture:  "https://avatars.githubusercontent.com/u/1",
+		})
+		r = container.With(r, &container.Container{
+			Posts:  p,
+			Tags:   tg,
+			Search: idx,
+		})
 		p.On("CreatePost", mock.MatchedBy(func(vals map[string]interface{}) bool {
-			return true
+			return vals["author"] == "Tester" &&
+				vals["username"] == "test" &&
+				vals["userPic"] == "https://avatars.githubusercontent.com/u/1"
 		})).Return(&post.Post{
 			Title:   "test",
 			Content: "test",
 			ID:      "test",
 		})
+		tg.On("SetTags", "test", int64(0), "", 0, []string(nil), []string{}).Return(nil)
 
 		CreatePost(w, r)
 
@@ -83,6 +131,36 @@ func TestCreatePost(t *testing.T) {
 	})
 }
 
+func TestCreateDraft(t *testing.T) {
+	p := new(postMock)
+	tg := new(tagMock)
+	idx := search.NewMemory()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodPost, "/new", nil)
+	r.PostForm = url.Values{"publish": {"0"}, "title": {"test"}}
+
+	r = container.WithUser(r, &user.User{Username: "test"})
+	r = container.With(r, &container.Container{
+		Posts:  p,
+		Tags:   tg,
+		Search: idx,
+	})
+	tg.On("SetTags", "test", int64(0), "", 0, []string(nil), []string{}).Return(nil)
+	p.On("CreatePost", mock.MatchedBy(func(vals map[string]interface{}) bool {
+		return vals["publish"] == 0
+	})).Return(&post.Post{
+		Title: "test",
+		ID:    "test",
+	})
+
+	CreatePost(w, r)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/drafts/test", w.Header().Get("Location"))
+	p.AssertExpectations(t)
+}
+
 func TestGetPost(t *testing.T) {
 	t.Run("nil", func(t *testing.T) {
 		p := new(postMock)
@@ -91,8 +169,10 @@ func TestGetPost(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
+		r = container.With(r, &container.Container{
+			Posts: p,
+			Likes: l,
+		})
 
 		p.On("GetPost", mock.MatchedBy(func(id string) bool {
 			return true
@@ -107,20 +187,32 @@ func TestGetPost(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
+		s := new(sessionMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Session:  s,
+		})
 
 		p.On("GetPost", mock.MatchedBy(func(id string) bool {
 			return true
 		})).Return(&post.Post{
 			Title:   "test",
 			Content: "beep\r\n\r\nboop\r\n\r\n",
+			Publish: 1,
 		})
-		l.On("GetLikes", "").Return(nil, errors.New(""))
+		c.On("GetComments", "").Return(nil, errors.New(""))
+		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		})).Return(nil)
 
 		GetPost(w, r)
 
@@ -128,32 +220,249 @@ func TestGetPost(t *testing.T) {
 		assert.Regexp(t, regexp.MustCompile("test"), w.Body.String())
 	})
 
+	t.Run("metadata", func(t *testing.T) {
+		os.Setenv("BASE_URL", "http://bishack.dev")
+		defer os.Unsetenv("BASE_URL")
+
+		p := new(postMock)
+		l := new(likeMock)
+		c := new(commentMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "http://bishack.dev/ing/hello-42", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Session:  s,
+		})
+
+		p.On("GetPost", mock.MatchedBy(func(id string) bool {
+			return true
+		})).Return(&post.Post{
+			ID:       "hello-42",
+			Title:    "Hello",
+			Username: "ing",
+			Author:   "Ing",
+			Cover:    "/images/cover.png",
+			Content:  "# Hello\r\n\r\nWe *shipped* it.</script>",
+			Publish:  1,
+		})
+		c.On("GetComments", "hello-42").Return(nil, nil)
+		s.On("GetFlash", mock.Anything, mock.Anything).Return(nil)
+
+		GetPost(w, r)
+
+		body := w.Body.String()
+		assert.Regexp(t, regexp.MustCompile(`<link rel="canonical" href="http://bishack.dev/ing/hello-42"`), body)
+		assert.Regexp(t, regexp.MustCompile(`<meta property="og:type" content="article"`), body)
+		assert.Regexp(t, regexp.MustCompile(`<meta property="og:description" content="We shipped it.&lt;/script&gt;"`), body)
+		assert.Regexp(t, regexp.MustCompile(`<meta property="og:image" content="http://bishack.dev/images/cover.png"`), body)
+		assert.Regexp(t, regexp.MustCompile(`<meta name="twitter:card" content="summary_large_image"`), body)
+		assert.Regexp(t, regexp.MustCompile(`<script type="application/ld\+json">\{"@context":"https://schema.org","@type":"BlogPosting"`), body)
+		assert.NotRegexp(t, regexp.MustCompile(`it.</script>`), body)
+	})
+
 	t.Run("ok with likes", func(t *testing.T) {
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
+		s := new(sessionMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "user", &user.User{Username: "test"})
-		context.Set(r, "likeService", l)
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Session:  s,
+		})
 
 		p.On("GetPost", mock.MatchedBy(func(id string) bool {
 			return true
 		})).Return(&post.Post{
-			Title: "test",
-			ID:    "test",
+			Title:      "test",
+			ID:         "test",
+			Publish:    1,
+			LikesCount: 1,
 		})
 		l.On("GetLike", "test", "test").Return(&like.Like{}, nil)
-		l.On("GetLikes", "test").Return([]*like.Like{
-			{},
+		c.On("GetComments", "test").Return([]*comment.Comment{
+			{ID: "x", Username: "test", Content: "**first**"},
+			{ID: "y", Username: "ing", Content: "_reply_", Parent: "x"},
 		}, nil)
+		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		})).Return(nil)
 
 		GetPost(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Regexp(t, regexp.MustCompile("test"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile(`<strong>first</strong>`), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile(`/comments/delete`), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile(`(?s)class="replies".*<em>reply</em>`), w.Body.String())
+	})
+}
+
+func TestGetPostDraft(t *testing.T) {
+	t.Run("hidden from others", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/test/test", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "ing"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "test"})
+
+		GetPost(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("author goes to preview", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/test/test", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "test"})
+
+		GetPost(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/drafts/test", w.Header().Get("Location"))
+	})
+}
+
+func TestDrafts(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/drafts", nil)
+
+		Drafts(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/drafts", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetDrafts", "test").Return([]*post.Post{
+			{ID: "wip", Title: "Work in progress"},
+		})
+		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		})).Return(nil)
+
+		Drafts(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, regexp.MustCompile("Work in progress"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("/drafts/wip"), w.Body.String())
+		p.AssertExpectations(t)
+	})
+}
+
+func TestPreviewDraft(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/drafts/test", nil)
+
+		PreviewDraft(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/drafts/test", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(nil)
+
+		PreviewDraft(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("published", func(t *testing.T) {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/drafts/test", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "test", Publish: 1})
+
+		PreviewDraft(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/test/test", w.Header().Get("Location"))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/drafts/test", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Username: "test", Title: "Half baked"})
+		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		})).Return(nil)
+
+		PreviewDraft(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, regexp.MustCompile("DRAFT PREVIEW"), w.Body.String())
+		assert.NotRegexp(t, regexp.MustCompile("comment-form"), w.Body.String())
 	})
 }
 
@@ -164,7 +473,9 @@ func TestToggleLike(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "likeService", l)
+		r = container.With(r, &container.Container{
+			Likes: l,
+		})
 
 		l.On("ToggleLike", "", "").Return(errors.New(""))
 
@@ -181,11 +492,13 @@ func TestToggleLike(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "user", &user.User{
+		r = container.WithUser(r, &user.User{
 			Username: "test",
 		})
 
-		context.Set(r, "likeService", l)
+		r = container.With(r, &container.Container{
+			Likes: l,
+		})
 
 		l.On("ToggleLike", "", "test").Return(nil)
 
@@ -197,6 +510,80 @@ func TestToggleLike(t *testing.T) {
 	})
 }
 
+func TestLike(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		l := new(likeMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/like/test?:id=test", nil)
+
+		r = container.With(r, &container.Container{
+			Likes: l,
+		})
+
+		Like(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		l.AssertNotCalled(t, "Like", mock.Anything, mock.Anything)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		l := new(likeMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/like/test?:id=test", nil)
+
+		r = container.WithUser(r, &user.User{Username: "ing"})
+		r = container.With(r, &container.Container{
+			Likes: l,
+		})
+
+		l.On("Like", "test", "ing").Return(errors.New(""))
+
+		Like(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		l := new(likeMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/like/test?:id=test", nil)
+
+		r = container.WithUser(r, &user.User{Username: "ing"})
+		r = container.With(r, &container.Container{
+			Likes: l,
+		})
+
+		l.On("Like", "test", "ing").Return(nil)
+
+		Like(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		l.AssertExpectations(t)
+	})
+}
+
+func TestUnlike(t *testing.T) {
+	l := new(likeMock)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodDelete, "/like/test?:id=test", nil)
+
+	r = container.WithUser(r, &user.User{Username: "ing"})
+	r = container.With(r, &container.Container{
+		Likes: l,
+	})
+
+	l.On("Unlike", "test", "ing").Return(nil)
+
+	Unlike(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	l.AssertExpectations(t)
+}
+
 func TestUpdatePost(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		p := new(postMock)
@@ -205,10 +592,18 @@ func TestUpdatePost(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "session", s)
-
-		p.On("UpdatePost", "", "", "", int64(0)).Return(errors.New(""))
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test", Tags: []string{"go"}})
+		p.On("UpdatePost", "test", "", int64(42), map[string]interface{}{
+			"cover":   "",
+			"content": "",
+			"tags":    []string{},
+		}).Return(errors.New(""))
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
@@ -223,14 +618,27 @@ func TestUpdatePost(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		p := new(postMock)
 		s := new(sessionMock)
+		tg := new(tagMock)
+		idx := search.NewMemory()
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/p/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "session", s)
-
-		p.On("UpdatePost", "", "", "", int64(0)).Return(nil)
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Tags:    tg,
+			Search:  idx,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test", Tags: []string{"go"}})
+		p.On("UpdatePost", "test", "", int64(42), map[string]interface{}{
+			"cover":   "",
+			"content": "",
+			"tags":    []string{},
+		}).Return(nil)
+		tg.On("SetTags", "", int64(42), "test", 0, []string{"go"}, []string{}).Return(nil)
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
@@ -243,6 +651,236 @@ func TestUpdatePost(t *testing.T) {
 	})
 }
 
+func TestUpdatePostPublish(t *testing.T) {
+	for _, publish := range []string{"2", "-1", "yes"} {
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/update-post", nil)
+		r.Form = url.Values{"id": {"test"}, "publish": {publish}}
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test"})
+
+		UpdatePost(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, publish)
+		p.AssertNotCalled(t, "UpdatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestUpdatePostOwnership(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/update-post", nil)
+
+		UpdatePost(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/update-post", nil)
+		r.Form = url.Values{"id": {"test"}, "created": {"7"}}
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "intruder"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test"})
+		p.On("UpdatePost", "intruder", "test", int64(42), mock.Anything).Return(post.ErrForbidden)
+
+		UpdatePost(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		p.AssertExpectations(t)
+		s.AssertNotCalled(t, "SetFlash")
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/update-post", nil)
+		r.Form = url.Values{"id": {"test"}, "created": {"7"}}
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "intruder"})
+
+		p.On("GetPost").Return(nil)
+
+		UpdatePost(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		p.AssertNotCalled(t, "UpdatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeletePost(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/delete-post", nil)
+
+		DeletePost(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/delete-post", nil)
+		r.Form = url.Values{"id": {"test"}, "created": {"7"}}
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "intruder"})
+
+		p.On("GetPost").Return(nil)
+
+		DeletePost(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		p.AssertNotCalled(t, "DeletePost", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/delete-post", nil)
+		r.Form = url.Values{"id": {"test"}, "created": {"7"}}
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test"})
+		p.On("DeletePost", "test", "test", int64(42)).Return(errors.New(""))
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "error", "An error occurred. Try again.")
+
+		DeletePost(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/edit/test", w.Header().Get("Location"))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(postMock)
+		s := new(sessionMock)
+		tg := new(tagMock)
+		idx := search.NewMemory()
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/delete-post", nil)
+		r.Form = url.Values{"id": {"test"}, "created": {"7"}}
+
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Tags:    tg,
+			Search:  idx,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+
+		idx.Add(&post.Post{ID: "test", Title: "test", Publish: 1})
+		p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test", Tags: []string{"go", "meetup"}})
+		p.On("DeletePost", "test", "test", int64(42)).Return(nil)
+		tg.On("RemoveTags", "test", []string{"go", "meetup"}).Return(nil)
+		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		}), "success", "Post deleted!")
+
+		DeletePost(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/test", w.Header().Get("Location"))
+		assert.Empty(t, idx.Search("test", 0))
+		p.AssertExpectations(t)
+		s.AssertExpectations(t)
+		tg.AssertExpectations(t)
+	})
+}
+
+func TestPublishPost(t *testing.T) {
+	for publish, message := range map[string]string{
+		"1": "Post published!",
+		"0": "Post moved back to drafts!",
+	} {
+		publish, message := publish, message
+		t.Run(publish, func(t *testing.T) {
+			p := new(postMock)
+			s := new(sessionMock)
+			tg := new(tagMock)
+			idx := search.NewMemory()
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/update-post", nil)
+			r.Form = url.Values{"id": {"test"}, "created": {"42"}, "publish": {publish}, "tags": {"Go, design"}}
+
+			r = container.With(r, &container.Container{
+				Posts:   p,
+				Tags:    tg,
+				Search:  idx,
+				Session: s,
+			})
+			r = container.WithUser(r, &user.User{Username: "test"})
+
+			expected, _ := strconv.Atoi(publish)
+			p.On("GetPost").Return(&post.Post{ID: "test", Created: 42, Username: "test"})
+			p.On("UpdatePost", "test", "test", int64(42), map[string]interface{}{
+				"cover":   "",
+				"content": "",
+				"publish": expected,
+				"tags":    []string{"go", "design"},
+			}).Return(nil)
+			tg.On("SetTags", "test", int64(42), "test", expected, []string(nil), []string{"go", "design"}).Return(nil)
+			s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+				return true
+			}), mock.MatchedBy(func(r *http.Request) bool {
+				return true
+			}), "success", message).Return(nil)
+
+			UpdatePost(w, r)
+
+			assert.Equal(t, http.StatusSeeOther, w.Code)
+			assert.Equal(t, publish == "1", len(idx.Search("design", 0)) == 1)
+			p.AssertExpectations(t)
+			s.AssertExpectations(t)
+			tg.AssertExpectations(t)
+		})
+	}
+}
+
 func TestEditPost(t *testing.T) {
 	t.Run("user not found", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -259,8 +897,10 @@ func TestEditPost(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/edit/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "user", &user.User{})
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		r = container.WithUser(r, &user.User{})
 
 		p.On("GetPost", mock.MatchedBy(func(username string) bool {
 			return true
@@ -280,9 +920,11 @@ func TestEditPost(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/edit/test", nil)
 
-		context.Set(r, "postService", p)
-		context.Set(r, "session", s)
-		context.Set(r, "user", &user.User{})
+		r = container.With(r, &container.Container{
+			Posts:   p,
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{})
 
 		p.On("GetPost", mock.MatchedBy(func(username string) bool {
 			return true
@@ -300,5 +942,9 @@ func TestEditPost(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Regexp(t, regexp.MustCompile("edit-form"), w.Body.String())
+
+		// enter in a field must save, not publish or unpublish
+		submit := regexp.MustCompile(`<button type="submit"[^>]*>`).FindString(w.Body.String())
+		assert.NotContains(t, submit, "publish")
 	})
 }
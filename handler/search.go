@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"bishack.dev/container"
+	"bishack.dev/services/search"
+	"bishack.dev/utils"
+)
+
+// searchLimit is the maximum number of results shown on the search page
+const searchLimit = 20
+
+// Search ...
+func Search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	idx := container.Search(r.Context())
+
+	// the index lives in memory so we build it on the first search. If the
+	// posts can't be read we search what's there and try again next time.
+	if err := idx.Load(container.Posts(r.Context()).GetPosts); err != nil {
+		log.Println("Search error:", err.Error())
+	}
+
+	var results []*search.Result
+	if q != "" {
+		results = idx.Search(q, searchLimit)
+	}
+
+	utils.Render(w, "main", "search", map[string]interface{}{
+		"Title":   "Search",
+		"User":    container.User(r.Context()),
+		"Query":   q,
+		"Results": results,
+	})
+}
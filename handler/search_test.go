@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	"bishack.dev/services/search"
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	t.Run("builds the index", func(t *testing.T) {
+		p := new(postMock)
+		idx := search.NewMemory()
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/search?q=tutorial", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:  p,
+			Search: idx,
+		})
+
+		p.On("GetPosts").Return([]*post.Post{
+			{ID: "go", Username: "test", Title: "Go Tutorial", Content: "Hello", Publish: 1},
+			{ID: "js", Username: "test", Title: "JS Notes", Content: "World", Publish: 1},
+		}, nil)
+
+		Search(w, r)
+
+		body := w.Body.String()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, regexp.MustCompile(`href="/test/go"`), body)
+		assert.NotRegexp(t, regexp.MustCompile(`href="/test/js"`), body)
+		p.AssertExpectations(t)
+	})
+
+	t.Run("loads again after an error", func(t *testing.T) {
+		p := new(postMock)
+		idx := search.NewMemory()
+
+		r, _ := http.NewRequest(http.MethodGet, "/search?q=tutorial", nil)
+		r = container.With(r, &container.Container{
+			Posts:  p,
+			Search: idx,
+		})
+
+		p.On("GetPosts").Return(nil, errors.New("boom")).Once()
+		p.On("GetPosts").Return([]*post.Post{
+			{ID: "go", Username: "test", Title: "Go Tutorial", Content: "Hello", Publish: 1},
+		}, nil).Once()
+
+		w := httptest.NewRecorder()
+		Search(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, regexp.MustCompile("Nothing matches"), w.Body.String())
+
+		w = httptest.NewRecorder()
+		Search(w, r)
+		assert.Regexp(t, regexp.MustCompile(`href="/test/go"`), w.Body.String())
+		p.AssertExpectations(t)
+	})
+
+	t.Run("loads once posts were added", func(t *testing.T) {
+		p := new(postMock)
+		idx := search.NewMemory()
+		// an edit before the first search
+		idx.Add(&post.Post{ID: "js", Username: "test", Title: "JS Tutorial", Publish: 1})
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/search?q=tutorial", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:  p,
+			Search: idx,
+		})
+
+		p.On("GetPosts").Return([]*post.Post{
+			{ID: "go", Username: "test", Title: "Go Tutorial", Content: "Hello", Publish: 1},
+		}, nil)
+
+		Search(w, r)
+
+		body := w.Body.String()
+		assert.Regexp(t, regexp.MustCompile(`href="/test/go"`), body)
+		assert.Regexp(t, regexp.MustCompile(`href="/test/js"`), body)
+		p.AssertExpectations(t)
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		p := new(postMock)
+		idx := search.NewMemory()
+		_ = idx.Load(func() ([]*post.Post, error) {
+			return []*post.Post{{ID: "go", Title: "Go Tutorial", Publish: 1}}, nil
+		})
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/search?q=rust", nil)
+
+		r = container.With(r, &container.Container{
+			Posts:  p,
+			Search: idx,
+		})
+
+		Search(w, r)
+
+		assert.Regexp(t, regexp.MustCompile("Nothing matches"), w.Body.String())
+		p.AssertNotCalled(t, "GetPosts")
+	})
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"bishack.dev/container"
+)
+
+// RevokeSession signs the current user out of one of their sessions
+func RevokeSession(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	sess := container.Session(r.Context())
+
+	if err := sess.RevokeSession(u.Username, r.PostForm.Get("id")); err != nil {
+		sess.SetFlash(w, r, "error", "Unable to revoke session. Try again.")
+	} else {
+		sess.SetFlash(w, r, "success", "Session revoked!")
+	}
+
+	http.Redirect(w, r, "/security", http.StatusSeeOther)
+}
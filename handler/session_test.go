@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/user"
+	_ "bishack.dev/testing"
+	"bishack.dev/utils/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRevokeSession(t *testing.T) {
+	t.Run("no user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/sessions/revoke", nil)
+
+		RevokeSession(w, r)
+
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/sessions/revoke", nil)
+		r.PostForm = url.Values{"id": {"x"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
+		s.On("RevokeSession", "test", "x").Return(session.ErrNotFound)
+		s.On("SetFlash", mock.Anything, mock.Anything, "error", mock.Anything).Return()
+
+		RevokeSession(w, r)
+
+		assert.Equal(t, "/security", w.Header().Get("Location"))
+		s.AssertExpectations(t)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/sessions/revoke", nil)
+		r.PostForm = url.Values{"id": {"x"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
+		s.On("RevokeSession", "test", "x").Return(nil)
+		s.On("SetFlash", mock.Anything, mock.Anything, "success", "Session revoked!").Return()
+
+		RevokeSession(w, r)
+
+		assert.Equal(t, "/security", w.Header().Get("Location"))
+		s.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bishack.dev/container"
+	"bishack.dev/utils"
+)
+
+// sitemapSize is the most URLs a sitemap can list. Past it /sitemap.xml
+// becomes an index of /sitemap-{page}.xml files.
+var sitemapSize = 50000
+
+// sitemapTTL is how long a generated sitemap is kept. Building it reads
+// every post, which crawlers shouldn't get to trigger on each request.
+var sitemapTTL = time.Hour
+
+// sitemapCache keeps the generated sitemap for as long as the process lives
+var sitemapCache = struct {
+	sync.Mutex
+	urls     []sitemapURL
+	modified time.Time
+	expires  time.Time
+	now      func() time.Time
+}{now: time.Now}
+
+// robotsDisallow is used when ROBOTS_DISALLOW isn't set: pages that are
+// private, per user or not worth indexing
+var robotsDisallow = []string{
+	"/api/",
+	"/drafts",
+	"/edit/",
+	"/login",
+	"/new",
+	"/profile",
+	"/search",
+	"/security",
+	"/signup",
+	"/verify",
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// Sitemap lists the home page, every user with published posts and every
+// published post. Once that's more than sitemapSize URLs it's an index of
+// the sitemap pages instead.
+func Sitemap(w http.ResponseWriter, r *http.Request) {
+	base := utils.BaseURL()
+	urls, modified, err := sitemapURLs(r, base)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if len(urls) <= sitemapSize {
+		writeXML(w, r, &sitemapURLSet{URLs: urls}, "application/xml", modified)
+		return
+	}
+
+	index := &sitemapIndex{}
+	for page := 1; (page-1)*sitemapSize < len(urls); page++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{
+			Loc:     fmt.Sprintf("%s/sitemap-%d.xml", base, page),
+			LastMod: modified.Format(time.RFC3339),
+		})
+	}
+
+	writeXML(w, r, index, "application/xml", modified)
+}
+
+// SitemapPage is a page of the sitemap index
+func SitemapPage(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get(":page"))
+	urls, modified, err := sitemapURLs(r, utils.BaseURL())
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	start := (page - 1) * sitemapSize
+	if page < 1 || start >= len(urls) || len(urls) <= sitemapSize {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	end := start + sitemapSize
+	if end > len(urls) {
+		end = len(urls)
+	}
+
+	writeXML(w, r, &sitemapURLSet{URLs: urls[start:end]}, "application/xml", modified)
+}
+
+// Robots serves robots.txt. ROBOTS_DISALLOW overrides the disallowed paths
+// with a comma separated list, e.g. `/` to keep a staging stage out of
+// search engines.
+func Robots(w http.ResponseWriter, r *http.Request) {
+	disallow := robotsDisallow
+	if env := os.Getenv("ROBOTS_DISALLOW"); env != "" {
+		disallow = strings.Split(env, ",")
+	}
+
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	for _, path := range disallow {
+		if path = strings.TrimSpace(path); path != "" {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+	}
+	b.WriteString("\nSitemap: " + utils.BaseURL() + "/sitemap.xml\n")
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedCacheAge.Seconds())))
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// sitemapURLs is every URL of the sitemap along with the time the most
+// recent of them changed, built at most once every sitemapTTL. A failed
+// build isn't kept, the next request tries again.
+func sitemapURLs(r *http.Request, base string) ([]sitemapURL, time.Time, error) {
+	c := &sitemapCache
+
+	// held while building so concurrent misses read the posts once
+	c.Lock()
+	defer c.Unlock()
+
+	if c.urls == nil || !c.now().Before(c.expires) {
+		urls, modified, err := buildSitemap(r, base)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		c.urls, c.modified = urls, modified
+		c.expires = c.now().Add(sitemapTTL)
+	}
+
+	return c.urls, c.modified, nil
+}
+
+// buildSitemap builds every URL of the sitemap from all published posts
+func buildSitemap(r *http.Request, base string) ([]sitemapURL, time.Time, error) {
+	ps := container.Posts(r.Context())
+
+	posts, err := ps.GetPosts()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	last := lastModified(posts)
+
+	// a user page changes whenever one of their posts does
+	users := map[string]int64{}
+	for _, p := range posts {
+		if u := updated(p); u > users[p.Username] {
+			users[p.Username] = u
+		}
+	}
+
+	usernames := []string{}
+	for u := range users {
+		usernames = append(usernames, u)
+	}
+	sort.Strings(usernames)
+
+	urls := []sitemapURL{{Loc: base + "/", LastMod: lastMod(last)}}
+	for _, u := range usernames {
+		urls = append(urls, sitemapURL{Loc: base + "/" + u, LastMod: lastMod(users[u])})
+	}
+	for _, p := range posts {
+		urls = append(urls, sitemapURL{Loc: postURL(base, p), LastMod: lastMod(updated(p))})
+	}
+
+	return urls, feedTime(last), nil
+}
+
+func lastMod(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+
+	return feedTime(unix).Format(time.RFC3339)
+}
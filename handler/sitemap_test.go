@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+// resetSitemap drops the cached sitemap so each test builds its own
+func resetSitemap() {
+	sitemapCache.urls = nil
+	sitemapCache.now = time.Now
+}
+
+func TestSitemap(t *testing.T) {
+	os.Setenv("BASE_URL", "http://bishack.dev")
+	defer os.Unsetenv("BASE_URL")
+	defer resetSitemap()
+
+	t.Run("urlset", func(t *testing.T) {
+		resetSitemap()
+
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "http://bishack.dev/sitemap.xml", nil)
+
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+		p.On("GetPosts").Return([]*post.Post{
+			{ID: "b-2", Username: "zed", Created: 2},
+			{ID: "a-1", Username: "ing", Created: 1, Updated: 50},
+		}, nil)
+
+		Sitemap(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, "^application/xml", w.Header().Get("Content-Type"))
+
+		var set sitemapURLSet
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &set))
+		assert.Equal(t, []sitemapURL{
+			{Loc: "http://bishack.dev/", LastMod: "1970-01-01T00:00:50Z"},
+			{Loc: "http://bishack.dev/ing", LastMod: "1970-01-01T00:00:50Z"},
+			{Loc: "http://bishack.dev/zed", LastMod: "1970-01-01T00:00:02Z"},
+			{Loc: "http://bishack.dev/zed/b-2", LastMod: "1970-01-01T00:00:02Z"},
+			{Loc: "http://bishack.dev/ing/a-1", LastMod: "1970-01-01T00:00:50Z"},
+		}, set.URLs)
+	})
+
+	t.Run("index", func(t *testing.T) {
+		resetSitemap()
+
+		size := sitemapSize
+		sitemapSize = 2
+		defer func() { sitemapSize = size }()
+
+		p := new(postMock)
+		p.On("GetPosts").Return([]*post.Post{
+			{ID: "a-1", Username: "ing", Created: 1},
+			{ID: "b-2", Username: "ing", Created: 2},
+		}, nil)
+
+		// home, one user and two posts make two pages
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "http://bishack.dev/sitemap.xml", nil)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		Sitemap(w, r)
+
+		var index sitemapIndex
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &index))
+		assert.Equal(t, 2, len(index.Sitemaps))
+		assert.Equal(t, "http://bishack.dev/sitemap-2.xml", index.Sitemaps[1].Loc)
+
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest(http.MethodGet, "http://bishack.dev/sitemap-2.xml?:page=2", nil)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		SitemapPage(w, r)
+
+		var set sitemapURLSet
+		assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &set))
+		assert.Equal(t, 2, len(set.URLs))
+		assert.Equal(t, "http://bishack.dev/ing/b-2", set.URLs[1].Loc)
+
+		for _, page := range []string{"0", "3"} {
+			w = httptest.NewRecorder()
+			r, _ = http.NewRequest(http.MethodGet, "/sitemap.xml?:page="+page, nil)
+			r = container.With(r, &container.Container{
+				Posts: p,
+			})
+
+			SitemapPage(w, r)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("no index pages for small sitemaps", func(t *testing.T) {
+		resetSitemap()
+
+		p := new(postMock)
+		p.On("GetPosts").Return(nil, nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/sitemap-1.xml?:page=1", nil)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		SitemapPage(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSitemapCache(t *testing.T) {
+	os.Setenv("BASE_URL", "http://bishack.dev")
+	defer os.Unsetenv("BASE_URL")
+
+	resetSitemap()
+	defer resetSitemap()
+
+	now := time.Unix(1000, 0)
+	sitemapCache.now = func() time.Time { return now }
+
+	p := new(postMock)
+	p.On("GetPosts").Return([]*post.Post{
+		{ID: "a-1", Username: "ing", Created: 1},
+	}, nil)
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "http://bishack.dev/sitemap.xml", nil)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		Sitemap(w, r)
+
+		return w
+	}
+
+	first := get()
+	now = now.Add(sitemapTTL - time.Second)
+	assert.Equal(t, first.Body.String(), get().Body.String())
+	p.AssertNumberOfCalls(t, "GetPosts", 1)
+
+	now = now.Add(time.Second)
+	get()
+	p.AssertNumberOfCalls(t, "GetPosts", 2)
+}
+
+func TestSitemapError(t *testing.T) {
+	resetSitemap()
+	defer resetSitemap()
+
+	p := new(postMock)
+	p.On("GetPosts").Return(nil, errors.New("boom"))
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+		r = container.With(r, &container.Container{
+			Posts: p,
+		})
+
+		Sitemap(w, r)
+
+		return w
+	}
+
+	// failures aren't cached, every request tries again
+	assert.Equal(t, http.StatusInternalServerError, get().Code)
+	assert.Equal(t, http.StatusInternalServerError, get().Code)
+	p.AssertNumberOfCalls(t, "GetPosts", 2)
+}
+
+func TestRobots(t *testing.T) {
+	os.Setenv("BASE_URL", "http://bishack.dev")
+	defer os.Unsetenv("BASE_URL")
+
+	t.Run("default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "http://evil.com/robots.txt", nil)
+
+		Robots(w, r)
+
+		assert.Regexp(t, "^text/plain", w.Header().Get("Content-Type"))
+		assert.Regexp(t, regexp.MustCompile("(?m)^Disallow: /api/$"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("(?m)^Sitemap: http://bishack.dev/sitemap.xml$"), w.Body.String())
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		os.Setenv("ROBOTS_DISALLOW", "/, ")
+		defer os.Unsetenv("ROBOTS_DISALLOW")
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "http://bishack.dev/robots.txt", nil)
+
+		Robots(w, r)
+
+		assert.Equal(t, "User-agent: *\nDisallow: /\n\nSitemap: http://bishack.dev/sitemap.xml\n", w.Body.String())
+	})
+}
@@ -6,7 +6,7 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/gorilla/context"
+	"bishack.dev/container"
 )
 
 const endpoint = "https://slack.com/api/users.admin.invite?token=%s&email=%s"
@@ -19,9 +19,7 @@ func SlackInvite(w http.ResponseWriter, r *http.Request) {
 	u := fmt.Sprintf(endpoint, token, email)
 	w.Header().Set("content-type", "application/json")
 
-	client := context.Get(r, "client").(interface {
-		Get(url string) (*http.Response, error)
-	})
+	client := container.Client(r.Context())
 	resp, err := client.Get(u)
 	if err != nil {
 		fmt.Fprintln(w, `{"ok":false}`)
@@ -8,7 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/gorilla/context"
+	"bishack.dev/container"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,7 +20,9 @@ func TestSlackInvite(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "client", c)
+		r = container.With(r, &container.Container{
+			Client: c,
+		})
 
 		c.On("Get", mock.MatchedBy(func(url string) bool {
 			return true
@@ -36,7 +38,9 @@ func TestSlackInvite(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "client", c)
+		r = container.With(r, &container.Container{
+			Client: c,
+		})
 
 		resp := &http.Response{}
 		resp.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(`{"ok":true}`)))
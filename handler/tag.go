@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	"bishack.dev/utils"
+)
+
+// Tag lists the published posts under a tag
+func Tag(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get(":tag")
+
+	sess := container.Session(r.Context())
+
+	posts := taggedPosts(r, name)
+	if len(posts) == 0 {
+		NotFound(w, r)
+		return
+	}
+
+	// likes and comments are counted on the post item
+	for _, p := range posts {
+		p.ReadingTime = computeReadingTime(p.Content)
+	}
+
+	utils.Render(w, "main", "tag", map[string]interface{}{
+		"Title": "#" + name,
+		"Tag":   name,
+		"Flash": sess.GetFlash(w, r),
+		"User":  container.User(r.Context()),
+		"Posts": posts,
+		"Feed":  "/t/" + name + "/feed.xml",
+	})
+}
+
+// taggedPosts gets the published posts under a tag, newest first
+func taggedPosts(r *http.Request, name string) []*post.Post {
+	ts := container.Tags(r.Context())
+
+	tagged, err := ts.GetTagged(name)
+	if err != nil {
+		log.Println("GetTagged error", err.Error())
+	}
+
+	if len(tagged) == 0 {
+		return nil
+	}
+
+	keys := []*post.Key{}
+	for _, t := range tagged {
+		keys = append(keys, &post.Key{ID: t.ID, Created: t.Created})
+	}
+
+	ps := container.Posts(r.Context())
+
+	// batch gets are unordered and may include posts that have been
+	// unpublished since they were tagged
+	posts := []*post.Post{}
+	for _, p := range ps.BatchGetPosts(keys) {
+		if p.Publish == 1 {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].Created > posts[j].Created
+	})
+
+	return posts
+}
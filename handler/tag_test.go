@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/post"
+	"bishack.dev/services/tag"
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTag(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		s := new(sessionMock)
+		tg := new(tagMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/t/nope?:tag=nope", nil)
+
+		r = container.With(r, &container.Container{
+			Session: s,
+			Tags:    tg,
+		})
+
+		tg.On("GetTagged", "nope").Return(nil, errors.New(""))
+
+		Tag(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+		p := new(postMock)
+		c := new(commentMock)
+		tg := new(tagMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/t/go?:tag=go", nil)
+
+		r = container.With(r, &container.Container{
+			Session:  s,
+			Posts:    p,
+			Comments: c,
+			Tags:     tg,
+		})
+
+		tg.On("GetTagged", "go").Return([]*tag.Tag{
+			{Tag: "go", ID: "older", Created: 1},
+			{Tag: "go", ID: "newer", Created: 2},
+			{Tag: "go", ID: "hidden", Created: 3},
+		}, nil)
+		p.On("BatchGetPosts", mock.MatchedBy(func(keys []*post.Key) bool {
+			return len(keys) == 3 && keys[0].ID == "older" && keys[0].Created == 1
+		})).Return([]*post.Post{
+			{ID: "older", Title: "Older Post", Created: 1, Publish: 1, Tags: []string{"go"}},
+			{ID: "hidden", Title: "Hidden Post", Created: 3, Publish: 0},
+			{ID: "newer", Title: "Newer Post", Created: 2, Publish: 1},
+		})
+		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
+			return true
+		}), mock.MatchedBy(func(r *http.Request) bool {
+			return true
+		})).Return(nil)
+
+		Tag(w, r)
+
+		body := w.Body.String()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, regexp.MustCompile(`(?s)Newer Post.*Older Post`), body)
+		assert.NotRegexp(t, regexp.MustCompile("Hidden Post"), body)
+		assert.Regexp(t, regexp.MustCompile(`href="/t/go"`), body)
+		p.AssertExpectations(t)
+	})
+}
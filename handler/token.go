@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"bishack.dev/container"
+)
+
+// CreateToken creates a personal access token and shows its secret once
+func CreateToken(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	ts := container.Tokens(r.Context())
+
+	sess := container.Session(r.Context())
+
+	secret, _, err := ts.CreateToken(u.Username, r.PostForm.Get("name"), r.PostForm["scopes"])
+	if err != nil {
+		sess.SetFlash(w, r, "error", err.Error())
+		http.Redirect(w, r, "/security", http.StatusSeeOther)
+		return
+	}
+
+	// rendered rather than redirected so the secret never touches the session
+	renderSecurity(w, r, u, secret)
+}
+
+// RevokeToken deletes a personal access token of the current user
+func RevokeToken(w http.ResponseWriter, r *http.Request) {
+	u := container.User(r.Context())
+	if u == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	_ = r.ParseForm()
+
+	ts := container.Tokens(r.Context())
+
+	sess := container.Session(r.Context())
+
+	if err := ts.RevokeToken(u.Username, r.PostForm.Get("id")); err != nil {
+		sess.SetFlash(w, r, "error", "Unable to revoke token. Try again.")
+	} else {
+		sess.SetFlash(w, r, "success", "Token revoked!")
+	}
+
+	http.Redirect(w, r, "/security", http.StatusSeeOther)
+}
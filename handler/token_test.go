@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/token"
+	"bishack.dev/services/user"
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateToken(t *testing.T) {
+	t.Run("no user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/tokens", nil)
+
+		CreateToken(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := new(sessionMock)
+		tk := new(tokenMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/tokens", nil)
+		r.PostForm = url.Values{"name": {""}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Tokens:  tk,
+		})
+		tk.On("CreateToken", "test", "", []string(nil)).Return("", nil, errors.New("Token name is required"))
+		s.On("SetFlash", mock.Anything, mock.Anything, "error", "Token name is required").Return()
+
+		CreateToken(w, r)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/security", w.Header().Get("Location"))
+		s.AssertExpectations(t)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+		tk := new(tokenMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/tokens", nil)
+		r.PostForm = url.Values{
+			"name":   {"ci"},
+			"scopes": {token.ScopeRead, token.ScopeWritePosts},
+		}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Tokens:  tk,
+		})
+		tk.On("CreateToken", "test", "ci", []string{token.ScopeRead, token.ScopeWritePosts}).
+			Return("bh_secret", &token.Token{}, nil)
+		tk.On("GetTokens", "test").Return(nil, nil)
+		s.On("GetFlash", mock.Anything, mock.Anything).Return(nil)
+		s.On("GetSessions", "test").Return(nil, nil)
+		s.On("GetUser", r).Return(nil)
+
+		CreateToken(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, regexp.MustCompile("bh_secret"), w.Body.String())
+		tk.AssertExpectations(t)
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	t.Run("no user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/tokens/revoke", nil)
+
+		RevokeToken(w, r)
+
+		assert.Equal(t, "/login", w.Header().Get("Location"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := new(sessionMock)
+		tk := new(tokenMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/tokens/revoke", nil)
+		r.PostForm = url.Values{"id": {"x"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Tokens:  tk,
+		})
+		tk.On("RevokeToken", "test", "x").Return(token.ErrNotFound)
+		s.On("SetFlash", mock.Anything, mock.Anything, "error", mock.Anything).Return()
+
+		RevokeToken(w, r)
+
+		assert.Equal(t, "/security", w.Header().Get("Location"))
+		s.AssertExpectations(t)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		s := new(sessionMock)
+		tk := new(tokenMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/security/tokens/revoke", nil)
+		r.PostForm = url.Values{"id": {"x"}}
+
+		r = container.WithUser(r, &user.User{Username: "test"})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Tokens:  tk,
+		})
+		tk.On("RevokeToken", "test", "x").Return(nil)
+		s.On("SetFlash", mock.Anything, mock.Anything, "success", "Token revoked!").Return()
+
+		RevokeToken(w, r)
+
+		assert.Equal(t, "/security", w.Header().Get("Location"))
+		s.AssertExpectations(t)
+	})
+}
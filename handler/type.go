@@ -1,5 +1,11 @@
 package handler
 
+import (
+	"html/template"
+
+	"bishack.dev/services/comment"
+)
+
 type githubUser struct {
 	Bio       string
 	Name      string
@@ -9,3 +15,38 @@ type githubUser struct {
 	Location  string
 	AvatarURL string `json:"avatar_url"`
 }
+
+// commentView carries everything the recursive comment template needs
+// since nested templates can't reach the page context
+type commentView struct {
+	*comment.Comment
+	Replies   []*commentView
+	Owner     string
+	Writer    bool
+	Moderator bool
+	LoggedIn  bool
+	CSRF      template.HTML
+}
+
+// newCommentViews wraps threaded comments for rendering
+func newCommentViews(
+	comments []*comment.Comment,
+	owner,
+	viewer string,
+	csrf template.HTML,
+) []*commentView {
+	views := []*commentView{}
+	for _, c := range comments {
+		views = append(views, &commentView{
+			Comment:   c,
+			Replies:   newCommentViews(c.Replies, owner, viewer, csrf),
+			Owner:     owner,
+			Writer:    viewer != "" && viewer == c.Username,
+			Moderator: viewer != "" && viewer == owner,
+			LoggedIn:  viewer != "",
+			CSRF:      csrf,
+		})
+	}
+
+	return views
+}
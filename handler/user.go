@@ -4,30 +4,22 @@ import (
 	"log"
 	"math"
 	"net/http"
-	"sync"
 
-	"bishack.dev/services/like"
-	"bishack.dev/services/post"
+	"bishack.dev/container"
+	"bishack.dev/services/dynamo"
+	"bishack.dev/services/token"
 	"bishack.dev/services/user"
 	"bishack.dev/utils"
-	"bishack.dev/utils/session"
-	"github.com/gorilla/context"
 	"github.com/gorilla/csrf"
-
-	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 )
 
 // GetUserPosts ...
 func GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	username := r.URL.Query().Get(":username")
 
-	sess := context.Get(r, "session").(interface {
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
-	us := context.Get(r, "userService").(interface {
-		GetUser(username string) *user.User
-	})
+	us := container.Users(r.Context())
 
 	user := us.GetUser(username)
 	if user == nil {
@@ -38,32 +30,13 @@ func GetUserPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ps := context.Get(r, "postService").(interface {
-		GetUserPosts(username string) []*post.Post
-	})
+	ps := container.Posts(r.Context())
 
-	posts := ps.GetUserPosts(username)
-
-	ls := context.Get(r, "likeService").(interface {
-		GetLikes(id string) ([]*like.Like, error)
-	})
-	// populate likes/comments count
-	var wg sync.WaitGroup
-	wg.Add(len(posts))
-	for _, p := range posts {
-		go func(p *post.Post) {
-			defer wg.Done()
-
-			results, err := ls.GetLikes(p.ID)
-			if err != nil {
-				log.Println("GetLikes error", err.Error())
-				return
-			}
-
-			p.LikesCount = int64(len(results))
-		}(p)
+	posts, next, err := ps.GetUserPostsPage(username, r.URL.Query().Get("after"), pageSize)
+	if err == dynamo.ErrInvalidCursor {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
 	}
-	wg.Wait()
 
 	utils.Render(w, "main", "user-page", map[string]interface{}{
 		"Title":       user.Name,
@@ -71,20 +44,20 @@ func GetUserPosts(w http.ResponseWriter, r *http.Request) {
 		"Cover":       user.Picture,
 		"Flash":       sess.GetFlash(w, r),
 		"Posts":       posts,
+		"Next":        next,
 		"Author":      user,
-		"User":        context.Get(r, "user"),
+		"Feed":        "/" + user.Username + "/feed.xml",
+		"User":        container.User(r.Context()),
 	})
 }
 
 // Profile ...
 func Profile(w http.ResponseWriter, r *http.Request) {
 
-	sess := context.Get(r, "session").(interface {
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
+	sess := container.Session(r.Context())
 
 	// get user details from context
-	user := context.Get(r, "user")
+	user := container.User(r.Context())
 
 	if user == nil {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
@@ -101,8 +74,8 @@ func Profile(w http.ResponseWriter, r *http.Request) {
 
 // UpdateProfile ...
 func UpdateProfile(w http.ResponseWriter, r *http.Request) {
-	token := context.Get(r, "token")
-	if token == nil {
+	token := container.Token(r.Context())
+	if token == "" {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
@@ -116,15 +89,11 @@ func UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	args["profile"] = profile[:int(math.Min(128, float64(len(profile))))] // Maximum of 128 chars
 	args["website"] = r.FormValue("website")
 
-	sess := context.Get(r, "session").(interface {
-		SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
-	})
+	sess := container.Session(r.Context())
 
-	us := context.Get(r, "userService").(interface {
-		UpdateUser(token string, attrs map[string]string) (*cip.UpdateUserAttributesOutput, error)
-	})
+	us := container.Users(r.Context())
 
-	if _, err := us.UpdateUser(token.(string), args); err != nil {
+	if _, err := us.UpdateUser(token, args); err != nil {
 		sess.SetFlash(w, r, "error", err.Error())
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
@@ -136,31 +105,56 @@ func UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 // Security ...
 func Security(w http.ResponseWriter, r *http.Request) {
-
-	sess := context.Get(r, "session").(interface {
-		GetFlash(w http.ResponseWriter, r *http.Request) *session.Flash
-	})
-
 	// get user details from context
-	user := context.Get(r, "user")
+	u := container.User(r.Context())
 
-	if user == nil {
+	if u == nil {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
+	renderSecurity(w, r, u, "")
+}
+
+// renderSecurity renders the security page. The secret of a newly created
+// token is shown once, it can't be retrieved afterwards.
+func renderSecurity(w http.ResponseWriter, r *http.Request, u *user.User, secret string) {
+	sess := container.Session(r.Context())
+
+	ts := container.Tokens(r.Context())
+
+	tokens, err := ts.GetTokens(u.Username)
+	if err != nil {
+		log.Println("GetTokens error", err.Error())
+	}
+
+	sessions, err := sess.GetSessions(u.Username)
+	if err != nil {
+		log.Println("GetSessions error", err.Error())
+	}
+
+	current := ""
+	if s := sess.GetUser(r); s != nil {
+		current = s["session"]
+	}
+
 	utils.Render(w, "main", "security-form", map[string]interface{}{
-		"Title":          "Change Password",
+		"Title":          "Security",
 		"Flash":          sess.GetFlash(w, r),
-		"User":           user,
+		"User":           u,
+		"Tokens":         tokens,
+		"Scopes":         token.Scopes,
+		"Secret":         secret,
+		"Sessions":       sessions,
+		"CurrentSession": current,
 		csrf.TemplateTag: csrf.TemplateField(r),
 	})
 }
 
 // ChangePassword ...
 func ChangePassword(w http.ResponseWriter, r *http.Request) {
-	token := context.Get(r, "token")
-	if token == nil {
+	token := container.Token(r.Context())
+	if token == "" {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
@@ -170,9 +164,7 @@ func ChangePassword(w http.ResponseWriter, r *http.Request) {
 	oldpass := r.FormValue("old")
 	newpass := r.FormValue("new")
 	confirmpass := r.FormValue("confirm")
-	sess := context.Get(r, "session").(interface {
-		SetFlash(w http.ResponseWriter, r *http.Request, t, v string)
-	})
+	sess := container.Session(r.Context())
 
 	if newpass != confirmpass {
 		sess.SetFlash(w, r, "error", "Password confirmation doesn't match the password")
@@ -180,11 +172,9 @@ func ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	us := context.Get(r, "userService").(interface {
-		ChangePassword(token, previous, proposed string) (*cip.ChangePasswordOutput, error)
-	})
+	us := container.Users(r.Context())
 
-	if _, err := us.ChangePassword(token.(string), oldpass, newpass); err != nil {
+	if _, err := us.ChangePassword(token, oldpass, newpass); err != nil {
 		sess.SetFlash(w, r, "error", err.Error())
 		http.Redirect(w, r, "/security", http.StatusSeeOther)
 		return
@@ -8,11 +8,13 @@ import (
 	"regexp"
 	"testing"
 
-	"bishack.dev/services/like"
+	"bishack.dev/container"
+	"bishack.dev/services/dynamo"
 	"bishack.dev/services/post"
+	"bishack.dev/services/token"
 	"bishack.dev/services/user"
+	"bishack.dev/utils/session"
 	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
-	"github.com/gorilla/context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,48 +24,54 @@ func TestGetUserPosts(t *testing.T) {
 		s := new(sessionMock)
 		u := new(userServiceMock)
 		l := new(likeMock)
+		c := new(commentMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "userService", u)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:    u,
+			Likes:    l,
+			Comments: c,
+			Session:  s,
+		})
 
 		u.On("GetUser", "").Return(nil)
-		l.On("GetLikes", "").Return(nil, errors.New(""))
 
 		GetUserPosts(w, r)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 
-	t.Run("likes with error", func(t *testing.T) {
+	t.Run("comments with error", func(t *testing.T) {
 		s := new(sessionMock)
 		u := new(userServiceMock)
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "userService", u)
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:    u,
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Session:  s,
+		})
 
 		u.On("GetUser", "").Return(&user.User{})
-		p.On("GetUserPosts", "").Return([]*post.Post{
+		p.On("GetUserPostsPage", "", "", int64(pageSize)).Return([]*post.Post{
 			{
 				Title: "The quick brown test",
 			},
-		})
+		}, "", nil)
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
 			return true
 		})).Return(nil)
-		l.On("GetLikes", "").Return(nil, errors.New(""))
 
 		GetUserPosts(w, r)
 
@@ -71,37 +79,68 @@ func TestGetUserPosts(t *testing.T) {
 		assert.Regexp(t, regexp.MustCompile("The quick brown test"), w.Body.String())
 	})
 
-	t.Run("likes ok", func(t *testing.T) {
+	t.Run("counters", func(t *testing.T) {
 		s := new(sessionMock)
 		u := new(userServiceMock)
 		p := new(postMock)
 		l := new(likeMock)
+		c := new(commentMock)
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "userService", u)
-		context.Set(r, "postService", p)
-		context.Set(r, "likeService", l)
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Users:    u,
+			Posts:    p,
+			Likes:    l,
+			Comments: c,
+			Session:  s,
+		})
 
 		u.On("GetUser", "").Return(&user.User{})
-		p.On("GetUserPosts", "").Return([]*post.Post{
+		p.On("GetUserPostsPage", "", "", int64(pageSize)).Return([]*post.Post{
 			{
-				Title: "The quick brown test",
+				Title:         "The quick brown test",
+				LikesCount:    7,
+				CommentsCount: 4,
 			},
-		})
+		}, "", nil)
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
 			return true
 		})).Return(nil)
-		l.On("GetLikes", "").Return([]*like.Like{{}}, nil)
 
 		GetUserPosts(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Regexp(t, regexp.MustCompile("The quick brown test"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("♥ 7"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("💬 4"), w.Body.String())
+		l.AssertNotCalled(t, "GetLikes", mock.Anything)
+		c.AssertNotCalled(t, "GetComments", mock.Anything)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		s := new(sessionMock)
+		u := new(userServiceMock)
+		p := new(postMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/?after=nope", nil)
+
+		r = container.With(r, &container.Container{
+			Users:   u,
+			Posts:   p,
+			Session: s,
+		})
+
+		u.On("GetUser", "").Return(&user.User{})
+		p.On("GetUserPostsPage", "", "nope", int64(pageSize)).Return(nil, "", dynamo.ErrInvalidCursor)
+
+		GetUserPosts(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
 
@@ -113,7 +152,9 @@ func TestProfile(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/profile", nil)
 
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -133,8 +174,10 @@ func TestProfile(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/profile", nil)
 
-		context.Set(r, "session", s)
-		context.Set(r, "user", map[string]string{})
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
+		r = container.WithUser(r, &user.User{})
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -165,9 +208,11 @@ func TestUpdateProfile(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodPost, "/update", nil)
 
-		context.Set(r, "token", "test")
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.WithToken(r, "test")
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		u.On("UpdateUser", "test", mock.MatchedBy(func(args map[string]string) bool {
 			return true
@@ -190,9 +235,11 @@ func TestUpdateProfile(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodPost, "/update", nil)
 
-		context.Set(r, "token", "test")
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.WithToken(r, "test")
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		u.On("UpdateUser", "test", mock.MatchedBy(func(args map[string]string) bool {
 			return true
@@ -213,20 +260,35 @@ func TestChangePassword(t *testing.T) {
 	t.Run("show form", func(t *testing.T) {
 		s := new(sessionMock)
 		w := httptest.NewRecorder()
+		tk := new(tokenMock)
 		r, _ := http.NewRequest(http.MethodGet, "/security", nil)
 
-		context.Set(r, "session", s)
-		context.Set(r, "user", map[string]string{})
+		r = container.With(r, &container.Container{
+			Session: s,
+			Tokens:  tk,
+		})
+		r = container.WithUser(r, &user.User{Username: "test"})
+		tk.On("GetTokens", "test").Return([]*token.Token{
+			{ID: "x", Name: "ci", Prefix: "bh_abcd", Scopes: []string{token.ScopeRead}},
+		}, nil)
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
 		}), mock.MatchedBy(func(r *http.Request) bool {
 			return true
 		})).Return(nil)
+		s.On("GetSessions", "test").Return([]*session.Session{
+			{ID: "a", Device: "Firefox on Linux", IP: "127.0.0.1"},
+			{ID: "b", Device: "Safari on iOS"},
+		}, nil)
+		s.On("GetUser", r).Return(map[string]string{"session": "a"})
 
 		Security(w, r)
 
 		assert.Regexp(t, regexp.MustCompile("security-form"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("bh_abcd…"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("Firefox on Linux</strong> <em>this device</em>"), w.Body.String())
+		assert.Regexp(t, regexp.MustCompile("Safari on iOS</strong>\\s+<small>"), w.Body.String())
 		s.AssertExpectations(t)
 	})
 
@@ -235,8 +297,10 @@ func TestChangePassword(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/security", nil)
 
-		context.Set(r, "session", s)
-		context.Set(r, "user", nil)
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
+		r = container.WithUser(r, nil)
 
 		s.On("GetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -270,8 +334,10 @@ func TestChangePassword(t *testing.T) {
 		form.Add("confirm", "new_passw0rd")
 		r.PostForm = form
 
-		context.Set(r, "token", "test")
-		context.Set(r, "session", s)
+		r = container.WithToken(r, "test")
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
 
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
 			return true
@@ -297,9 +363,11 @@ func TestChangePassword(t *testing.T) {
 		form.Add("confirm", "new_password")
 		r.PostForm = form
 
-		context.Set(r, "token", "test")
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.WithToken(r, "test")
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		u.On("ChangePassword", "test", "incorrect_password", "new_password").Return(nil, errors.New("Incorrect Password"))
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
@@ -327,9 +395,11 @@ func TestChangePassword(t *testing.T) {
 		form.Add("confirm", "passs")
 		r.PostForm = form
 
-		context.Set(r, "token", "test")
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.WithToken(r, "test")
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		u.On("ChangePassword", "test", "pass", "passs").Return(nil, errors.New("Invalid Password"))
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
@@ -357,9 +427,11 @@ func TestChangePassword(t *testing.T) {
 		form.Add("confirm", "new_password")
 		r.PostForm = form
 
-		context.Set(r, "token", "test")
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.WithToken(r, "test")
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		u.On("ChangePassword", "test", "old_password", "new_password").Return(&cip.ChangePasswordOutput{}, nil)
 		s.On("SetFlash", mock.MatchedBy(func(w http.ResponseWriter) bool {
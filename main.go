@@ -5,10 +5,11 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
-	"regexp"
 
+	"bishack.dev/container"
 	"bishack.dev/handler"
 	mw "bishack.dev/middleware"
+	"bishack.dev/utils"
 
 	// autoload env
 	"github.com/aws/aws-xray-sdk-go/xray"
@@ -23,8 +24,28 @@ func main() {
 	csrfSecure := true
 
 	// env
-	rxEnv := regexp.MustCompile("`(?i)stag|prod")
-	isLive := rxEnv.MatchString(os.Getenv("UP_STAGE"))
+	isLive := utils.IsLive()
+
+	// feeds, sitemaps and share links need to know where the site lives
+	if isLive && os.Getenv("BASE_URL") == "" {
+		log.Fatal("BASE_URL must be set")
+	}
+
+	// the local stand-in for Cognito keeps users in memory, never go live with it
+	if isLive && os.Getenv("COGNITO_LOCAL") == "true" {
+		log.Fatal("COGNITO_LOCAL is for local development only")
+	}
+
+	// templates are parsed once, except on local where they reload
+	if err := utils.LoadTemplates(!isLive); err != nil {
+		log.Fatal(err)
+	}
+
+	// services are built once and shared by every request
+	deps, err := container.New()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// init route
 	r := pat.New()
@@ -43,6 +64,16 @@ func main() {
 
 	// handlers et al
 
+	// api (pat matches by prefix so specific routes go first)
+	r.Get("/api/v1/posts/{username}/{id}/like", handler.APIPostLike)
+	r.Get("/api/v1/posts/{username}/{id}", handler.APIPost)
+	r.Get("/api/v1/posts", handler.APIPosts)
+	r.Post("/api/v1/posts", handler.APICreatePost)
+	r.Get("/api/v1/users/{username}/posts", handler.APIUserPosts)
+	r.Get("/api/v1/users/{username}", handler.APIUser)
+	r.Get("/api/", handler.APINotFound)
+	r.Post("/api/", handler.APINotFound)
+
 	// auth
 	r.Get("/signup", handler.Signup)
 	r.Get("/verify", handler.Verify)
@@ -56,20 +87,55 @@ func main() {
 	r.Post("/profile", handler.UpdateProfile)
 
 	// security
+	r.Post("/security/tokens/revoke", handler.RevokeToken)
+	r.Post("/security/sessions/revoke", handler.RevokeSession)
+	r.Post("/security/tokens", handler.CreateToken)
 	r.Get("/security", handler.Security)
 	r.Post("/security", handler.ChangePassword)
 
 	// like
 	r.Put("/like/{id}", handler.ToggleLike)
+	r.Post("/like/{id}", handler.Like)
+	r.Delete("/like/{id}", handler.Unlike)
+
+	// comment
+	r.Post("/comments/new", handler.CreateComment)
+	r.Post("/comments/update", handler.UpdateComment)
+	r.Post("/comments/delete", handler.DeleteComment)
+	r.Post("/comments/moderate", handler.ModerateComment)
+
+	// search
+	r.Get("/search", handler.Search)
+
+	// stylesheets and scripts
+	r.Get("/assets/{file}", handler.Asset)
+
+	// crawlers
+	r.Get("/robots.txt", handler.Robots)
+	r.Get("/sitemap.xml", handler.Sitemap)
+	r.Get("/sitemap-{page:[0-9]+}.xml", handler.SitemapPage)
+
+	// feeds
+	r.Get("/feed.xml", handler.Feed)
+	r.Get("/rss.xml", handler.RSS)
+	r.Get("/t/{tag}/feed.xml", handler.TagFeed)
+
+	// tag
+	r.Get("/t/{tag}", handler.Tag)
 
 	// slack
 	r.Get("/slack-invite", handler.SlackInvite)
 
 	// post
 	r.Post("/update-post", handler.UpdatePost)
+	r.Post("/delete-post", handler.DeletePost)
 	r.Get("/edit/{id}", handler.EditPost)
 	r.Get("/new", handler.New)
 	r.Post("/new", handler.CreatePost)
+	r.Get("/drafts/{id}", handler.PreviewDraft)
+	r.Get("/drafts", handler.Drafts)
+	r.Get("/{username}/feed.xml", handler.UserFeed)
+	r.Get("/{username}/{id}/og.png", handler.PostImage)
 	r.Get("/{username}/{id}", handler.GetPost)
 	r.Get("/{username}", handler.GetUserPosts)
 
@@ -84,17 +150,18 @@ func main() {
 		}()
 	}
 
-	protect := csrf.Protect([]byte(
+	// bearer requests carry no cookies worth forging so they skip csrf
+	protect := mw.SkipCSRF(csrf.Protect([]byte(
 		os.Getenv("CSRF_KEY")),
 		csrf.Secure(csrfSecure),
-	)
+	))
 
 	port := ":" + os.Getenv("PORT")
 	log.Fatal(http.ListenAndServe(
 		port,
 		xray.Handler(
 			xray.NewFixedSegmentNamer("bishack.dev"),
-			protect(mw.Context(mw.Token(mw.SessionUser(mw.AuthRedirects(r))))),
+			protect(mw.Context(deps)(mw.Bearer(mw.Token(mw.SessionUser(mw.AuthRedirects(r)))))),
 		),
 	))
 }
@@ -4,7 +4,7 @@ import (
 	"net/http"
 	"regexp"
 
-	"github.com/gorilla/context"
+	"bishack.dev/container"
 )
 
 // AuthRedirects middleware will redirect user to the root page
@@ -13,7 +13,7 @@ func AuthRedirects(h http.Handler) http.Handler {
 	rx := regexp.MustCompile(`(?i)^/(signup|login|verify)`)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user := context.Get(r, "user")
+		user := container.User(r.Context())
 		if r.URL.Path != "/" && rx.MatchString(r.URL.Path) && user != nil {
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
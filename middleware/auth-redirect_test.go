@@ -5,7 +5,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/gorilla/context"
+	"bishack.dev/container"
+	"bishack.dev/services/user"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,7 +15,7 @@ func TestRedirectMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/signup", nil)
 
-		context.Set(r, "user", map[string]string{})
+		r = container.WithUser(r, &user.User{})
 
 		AuthRedirects(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		})).ServeHTTP(w, r)
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bishack.dev/container"
+	"bishack.dev/services/token"
+	"bishack.dev/services/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerMw(t *testing.T) {
+	t.Run("no header", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+		var got *http.Request
+		Bearer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+		})).ServeHTTP(w, r)
+
+		assert.NotNil(t, got)
+		assert.Nil(t, container.APIToken(got.Context()))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		ts := new(tokenServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer bh_nope")
+
+		r = container.With(r, &container.Container{
+			Tokens: ts,
+		})
+		ts.On("Verify", "bh_nope").Return(nil, token.ErrInvalidToken)
+
+		Bearer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("should not be called")
+		})).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Regexp(t, "invalid_token", w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		ts := new(tokenServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/like/test", nil)
+		r.Header.Set("Authorization", "Bearer bh_test")
+
+		r = container.With(r, &container.Container{
+			Tokens: ts,
+		})
+		ts.On("Verify", "bh_test").Return(&token.Token{
+			Username: "ing",
+			Scopes:   []string{token.ScopeRead},
+		}, nil)
+
+		Bearer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("should not be called")
+		})).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("account pages", func(t *testing.T) {
+		ts := new(tokenServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/security", nil)
+		r.Header.Set("Authorization", "Bearer bh_test")
+
+		r = container.With(r, &container.Container{
+			Tokens: ts,
+		})
+		ts.On("Verify", "bh_test").Return(&token.Token{
+			Username: "ing",
+			Scopes:   token.Scopes,
+		}, nil)
+
+		Bearer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("should not be called")
+		})).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("owner gone", func(t *testing.T) {
+		ts := new(tokenServiceMock)
+		us := new(userServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer bh_test")
+
+		r = container.With(r, &container.Container{
+			Tokens: ts,
+			Users:  us,
+		})
+		ts.On("Verify", "bh_test").Return(&token.Token{
+			Username: "ing",
+			Scopes:   []string{token.ScopeRead},
+		}, nil)
+		us.On("GetUser", "ing").Return(nil)
+
+		Bearer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("should not be called")
+		})).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ts := new(tokenServiceMock)
+		us := new(userServiceMock)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/api/v1/posts", nil)
+		r.Header.Set("Authorization", "bearer bh_test")
+
+		r = container.With(r, &container.Container{
+			Tokens: ts,
+			Users:  us,
+		})
+		ts.On("Verify", "bh_test").Return(&token.Token{
+			Username: "ing",
+			Scopes:   []string{token.ScopeWritePosts},
+		}, nil)
+		us.On("GetUser", "ing").Return(&user.User{Username: "ing"})
+
+		var got *http.Request
+		Bearer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+		})).ServeHTTP(w, r)
+
+		assert.NotNil(t, got)
+		assert.Equal(t, "ing", container.User(got.Context()).Username)
+		assert.NotNil(t, container.APIToken(got.Context()))
+	})
+}
+
+func TestSkipCSRF(t *testing.T) {
+	protect := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "", http.StatusForbidden)
+		})
+	}
+
+	h := SkipCSRF(protect)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	}))
+
+	t.Run("cookie request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/new", nil)
+
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("bearer request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "/api/v1/posts", nil)
+		r.Header.Set("Authorization", "Bearer bh_test")
+
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestTokenMwSkipsBearer(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+	// the session has no expectations: reaching for it would panic
+	r = container.With(r, &container.Container{
+		Session: new(sessionMock),
+	})
+	r = container.WithAPIToken(r, &token.Token{})
+
+	var got *http.Request
+	Token(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	})).ServeHTTP(w, r)
+
+	assert.Equal(t, "", container.Token(got.Context()))
+}
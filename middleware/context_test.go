@@ -5,25 +5,30 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/gorilla/context"
+	"bishack.dev/container"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestContextMw(t *testing.T) {
-	t.Run("should attach some shit to context", func(t *testing.T) {
+	t.Run("should attach the container to context", func(t *testing.T) {
+		c, err := container.New()
+		assert.Nil(t, err)
+
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/signup", nil)
 
-		Context(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got *http.Request
+		Context(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		us := context.Get(r, "userService")
-		assert.NotNil(t, us)
-
-		sess := context.Get(r, "session")
-		assert.NotNil(t, sess)
-
-		client := context.Get(r, "client")
-		assert.NotNil(t, client)
+		assert.Equal(t, c, container.From(got.Context()))
+		assert.Equal(t, c.Users, container.Users(got.Context()))
+		assert.Equal(t, c.Session, container.Session(got.Context()))
+		assert.Equal(t, c.Client, container.Client(got.Context()))
+		assert.Equal(t, c.Comments, container.Comments(got.Context()))
+		assert.Equal(t, c.Tags, container.Tags(got.Context()))
+		assert.Equal(t, c.Tokens, container.Tokens(got.Context()))
+		assert.Equal(t, c.Search, container.Search(got.Context()))
 	})
 }
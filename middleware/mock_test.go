@@ -3,12 +3,17 @@ package middleware
 import (
 	"net/http"
 
+	"bishack.dev/container"
+	"bishack.dev/services/token"
 	"bishack.dev/services/user"
 	"github.com/stretchr/testify/mock"
 )
 
+// userServiceMock only mocks what middleware uses, calling anything else
+// panics
 type userServiceMock struct {
 	mock.Mock
+	container.UserService
 }
 
 func (o *userServiceMock) AccountDetails(token string) *user.User {
@@ -27,8 +32,11 @@ func (o *userServiceMock) GetToken(username, token string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+// sessionMock only mocks what middleware uses, calling anything else
+// panics
 type sessionMock struct {
 	mock.Mock
+	container.SessionHelper
 }
 
 func (o *sessionMock) GetUser(r *http.Request) map[string]string {
@@ -41,3 +49,32 @@ func (o *sessionMock) GetUser(r *http.Request) map[string]string {
 
 	return resp.(map[string]string)
 }
+
+func (o *userServiceMock) GetUser(username string) *user.User {
+	args := o.Called(username)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil
+	}
+
+	return resp.(*user.User)
+}
+
+// tokenServiceMock only mocks what middleware uses, calling anything else
+// panics
+type tokenServiceMock struct {
+	mock.Mock
+	container.TokenService
+}
+
+func (o *tokenServiceMock) Verify(secret string) (*token.Token, error) {
+	args := o.Called(secret)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*token.Token), args.Error(1)
+}
@@ -3,8 +3,7 @@ package middleware
 import (
 	"net/http"
 
-	"bishack.dev/services/user"
-	"github.com/gorilla/context"
+	"bishack.dev/container"
 )
 
 // SessionUser middleware checks for the `user` session and if it exists
@@ -16,21 +15,19 @@ func SessionUser(h http.Handler) http.Handler {
 			h.ServeHTTP(w, r)
 		}()
 
-		token := context.Get(r, "token")
-		if token == nil {
+		token := container.Token(r.Context())
+		if token == "" {
 			return
 		}
 
-		u := context.Get(r, "userService").(interface {
-			AccountDetails(token string) *user.User
-		})
+		u := container.Users(r.Context())
 
-		user := u.AccountDetails(token.(string))
+		user := u.AccountDetails(token)
 		if user == nil {
 			return
 		}
 
-		context.Set(r, "user", user)
+		r = container.WithUser(r, user)
 	})
 }
 
@@ -44,18 +41,19 @@ func Token(h http.Handler) http.Handler {
 			h.ServeHTTP(w, r)
 		}()
 
-		ses := context.Get(r, "session").(interface {
-			GetUser(r *http.Request) map[string]string
-		})
+		// bearer requests don't use the cookie session
+		if container.APIToken(r.Context()) != nil {
+			return
+		}
+
+		ses := container.Session(r.Context())
 
 		su := ses.GetUser(r)
 		if su == nil {
 			return
 		}
 
-		us := context.Get(r, "userService").(interface {
-			GetToken(string, string) (string, error)
-		})
+		us := container.Users(r.Context())
 
 		username := su["username"]
 		token := su["token"]
@@ -64,6 +62,6 @@ func Token(h http.Handler) http.Handler {
 			return
 		}
 
-		context.Set(r, "token", accessToken)
+		r = container.WithToken(r, accessToken)
 	})
 }
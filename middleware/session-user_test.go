@@ -6,8 +6,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"bishack.dev/container"
 	"bishack.dev/services/user"
-	"github.com/gorilla/context"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,11 +17,12 @@ func TestSessionUserMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
+		var got *http.Request
 		SessionUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		u := context.Get(r, "user")
-		assert.Nil(t, u)
+		assert.Nil(t, container.User(got.Context()))
 	})
 
 	t.Run("error account details", func(t *testing.T) {
@@ -30,16 +31,19 @@ func TestSessionUserMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "userService", u)
-		context.Set(r, "token", "test")
+		r = container.With(r, &container.Container{
+			Users: u,
+		})
+		r = container.WithToken(r, "test")
 
 		u.On("AccountDetails", "test").Return(nil, errors.New(""))
 
+		var got *http.Request
 		SessionUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		user := context.Get(r, "user")
-		assert.Nil(t, user)
+		assert.Nil(t, container.User(got.Context()))
 		u.AssertExpectations(t)
 	})
 
@@ -49,17 +53,20 @@ func TestSessionUserMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "userService", u)
-		context.Set(r, "token", "test")
+		r = container.With(r, &container.Container{
+			Users: u,
+		})
+		r = container.WithToken(r, "test")
 
 		resp := &user.User{}
 		u.On("AccountDetails", "test").Return(resp, nil)
 
+		var got *http.Request
 		SessionUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		user := context.Get(r, "user")
-		assert.NotNil(t, user)
+		assert.NotNil(t, container.User(got.Context()))
 		u.AssertExpectations(t)
 	})
 }
@@ -71,17 +78,20 @@ func TestTokenMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "session", s)
+		r = container.With(r, &container.Container{
+			Session: s,
+		})
 
 		s.On("GetUser", mock.MatchedBy(func(r *http.Request) bool {
 			return true
 		})).Return(nil)
 
+		var got *http.Request
 		Token(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		token := context.Get(r, "token")
-		assert.Nil(t, token)
+		assert.Equal(t, "", container.Token(got.Context()))
 	})
 
 	t.Run("error", func(t *testing.T) {
@@ -91,8 +101,10 @@ func TestTokenMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		s.On("GetUser", mock.MatchedBy(func(r *http.Request) bool {
 			return true
@@ -102,11 +114,12 @@ func TestTokenMw(t *testing.T) {
 		})
 		u.On("GetToken", "test", "test").Return("", errors.New(""))
 
+		var got *http.Request
 		Token(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		token := context.Get(r, "token")
-		assert.Nil(t, token)
+		assert.Equal(t, "", container.Token(got.Context()))
 	})
 
 	t.Run("ok", func(t *testing.T) {
@@ -116,8 +129,10 @@ func TestTokenMw(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-		context.Set(r, "session", s)
-		context.Set(r, "userService", u)
+		r = container.With(r, &container.Container{
+			Session: s,
+			Users:   u,
+		})
 
 		s.On("GetUser", mock.MatchedBy(func(r *http.Request) bool {
 			return true
@@ -127,10 +142,11 @@ func TestTokenMw(t *testing.T) {
 		})
 		u.On("GetToken", "test", "test").Return("test", nil)
 
+		var got *http.Request
 		Token(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
 		})).ServeHTTP(w, r)
 
-		token := context.Get(r, "token")
-		assert.Equal(t, "test", token.(string))
+		assert.Equal(t, "test", container.Token(got.Context()))
 	})
 }
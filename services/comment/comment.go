@@ -0,0 +1,404 @@
+package comment
+
+import (
+	"sort"
+	"time"
+
+	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// Client ...
+type Client struct {
+	*dynamo.Client
+
+	// PostsTable is the table holding the `commentsCount` counter of each
+	// post. When set, comments and the counter are written in one
+	// transaction.
+	PostsTable string
+}
+
+// New ...
+func New(
+	tableName,
+	endpoint string,
+	provider dynamo.Provider,
+) *Client {
+	return &Client{
+		Client: dynamo.New(tableName, endpoint, provider),
+	}
+}
+
+// CreateComment adds a new comment to the given post. Set the `parent`
+// param to the id of another comment to post a reply.
+func (c *Client) CreateComment(params map[string]interface{}) (*Comment, error) {
+	item, _ := dynamodbattribute.MarshalMap(newComment(params))
+
+	if c.PostsTable != "" {
+		put := &dynamodb.Put{Item: item}
+		put.SetTableName(c.TableName)
+		put.SetConditionExpression("attribute_not_exists(id)")
+
+		post, _ := params["post"].(string)
+		err := c.writeWithCounter(post, 1, &dynamodb.TransactWriteItem{Put: put})
+		if err != nil {
+			return nil, errors.Wrap(err, "CreateComment/TransactWriteItems error")
+		}
+
+		var comment Comment
+		_ = dynamodbattribute.UnmarshalMap(item, &comment)
+		return &comment, nil
+	}
+
+	input := &dynamodb.PutItemInput{}
+	input.SetTableName(c.TableName)
+	input.SetItem(item)
+	input.SetConditionExpression("attribute_not_exists(id)")
+
+	_, err := c.Provider.PutItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateComment/PutItem error")
+	}
+
+	var comment Comment
+	_ = dynamodbattribute.UnmarshalMap(item, &comment)
+	return &comment, nil
+}
+
+// GetComments lists all the comments of a post, oldest first
+func (c *Client) GetComments(post string) ([]*Comment, error) {
+	ks := "post = :post"
+	vals := map[string]interface{}{
+		":post": post,
+	}
+
+	out, err := c.Query("", ks, "", vals, true, 0, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetComments/Query error")
+	}
+
+	var comments []*Comment
+	_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &comments)
+	return comments, nil
+}
+
+// UpdateComment changes the content of a comment. Only the user who wrote
+// the comment can update it.
+func (c *Client) UpdateComment(post, id, username, content string) error {
+	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"post": post,
+		"id":   id,
+	})
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":content":  content,
+		":updated":  time.Now().Unix(),
+		":username": username,
+	})
+
+	input := &dynamodb.UpdateItemInput{}
+	input.SetKey(key)
+	input.SetTableName(c.TableName)
+	input.SetUpdateExpression("SET content = :content, updated = :updated")
+	input.SetConditionExpression("username = :username")
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := c.Provider.UpdateItem(input)
+	if err != nil {
+		return errors.Wrap(err, "UpdateComment/UpdateItem error")
+	}
+
+	return nil
+}
+
+// DeleteComment removes a comment. Only the user who wrote the comment
+// can delete it.
+func (c *Client) DeleteComment(post, id, username string) error {
+	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"post": post,
+		"id":   id,
+	})
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":username": username,
+	})
+
+	if c.PostsTable != "" {
+		del := &dynamodb.Delete{Key: key}
+		del.SetTableName(c.TableName)
+		del.SetConditionExpression("username = :username")
+		del.SetExpressionAttributeValues(vals)
+
+		err := c.writeWithCounter(post, -1, &dynamodb.TransactWriteItem{Delete: del})
+		if err != nil {
+			return errors.Wrap(err, "DeleteComment/TransactWriteItems error")
+		}
+
+		return nil
+	}
+
+	input := &dynamodb.DeleteItemInput{}
+	input.SetKey(key)
+	input.SetTableName(c.TableName)
+	input.SetConditionExpression("username = :username")
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := c.Provider.DeleteItem(input)
+	if err != nil {
+		return errors.Wrap(err, "DeleteComment/DeleteItem error")
+	}
+
+	return nil
+}
+
+// HideComment hides or unhides a comment. Hidden comments are only shown to
+// the user who wrote them and to the author of the post.
+func (c *Client) HideComment(post, id string, hidden bool) error {
+	err := c.moderate(post, id, "hidden", hidden)
+	if err != nil {
+		return errors.Wrap(err, "HideComment")
+	}
+
+	return nil
+}
+
+// PinComment pins or unpins a comment. Pinned comments are shown first.
+func (c *Client) PinComment(post, id string, pinned bool) error {
+	err := c.moderate(post, id, "pinned", pinned)
+	if err != nil {
+		return errors.Wrap(err, "PinComment")
+	}
+
+	return nil
+}
+
+// RemoveComment deletes any comment regardless of who wrote it. Callers
+// must make sure the current user is the author of the post.
+func (c *Client) RemoveComment(post, id string) error {
+	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"post": post,
+		"id":   id,
+	})
+
+	if c.PostsTable != "" {
+		del := &dynamodb.Delete{Key: key}
+		del.SetTableName(c.TableName)
+		del.SetConditionExpression("attribute_exists(id)")
+
+		err := c.writeWithCounter(post, -1, &dynamodb.TransactWriteItem{Delete: del})
+		if err != nil {
+			return errors.Wrap(err, "RemoveComment/TransactWriteItems error")
+		}
+
+		return nil
+	}
+
+	input := &dynamodb.DeleteItemInput{}
+	input.SetKey(key)
+	input.SetTableName(c.TableName)
+
+	_, err := c.Provider.DeleteItem(input)
+	if err != nil {
+		return errors.Wrap(err, "RemoveComment/DeleteItem error")
+	}
+
+	return nil
+}
+
+// Thread arranges a flat list of comments into nested replies. Hidden
+// comments, together with their replies, are dropped unless the viewer
+// wrote them or is the moderator. Pinned comments come first, the rest
+// keep their chronological order.
+func Thread(comments []*Comment, viewer string, moderator bool) []*Comment {
+	nodes := map[string]*Comment{}
+	for _, c := range comments {
+		c.Replies = nil
+		nodes[c.ID] = c
+	}
+
+	var root []*Comment
+	for _, c := range comments {
+		if c.Hidden && !moderator && (viewer == "" || c.Username != viewer) {
+			continue
+		}
+
+		parent, ok := nodes[c.Parent]
+		if !ok {
+			// top-level comment or orphaned reply
+			root = append(root, c)
+			continue
+		}
+
+		parent.Replies = append(parent.Replies, c)
+	}
+
+	sort.SliceStable(root, func(i, j int) bool {
+		return root[i].Pinned && !root[j].Pinned
+	})
+
+	return root
+}
+
+// moderate sets a boolean flag on the given comment
+func (c *Client) moderate(post, id, flag string, value bool) error {
+	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"post": post,
+		"id":   id,
+	})
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":value": value,
+	})
+
+	input := &dynamodb.UpdateItemInput{}
+	input.SetKey(key)
+	input.SetTableName(c.TableName)
+	input.SetUpdateExpression("SET #flag = :value")
+	input.SetConditionExpression("attribute_exists(id)")
+	input.SetExpressionAttributeNames(map[string]*string{
+		"#flag": &flag,
+	})
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := c.Provider.UpdateItem(input)
+	return err
+}
+
+// writeWithCounter runs the given write together with an update of the
+// `commentsCount` counter of the post so the two never drift apart
+func (c *Client) writeWithCounter(
+	post string,
+	delta int,
+	write *dynamodb.TransactWriteItem,
+) error {
+	key, err := c.postKey(post)
+	if err != nil {
+		return err
+	}
+
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":delta": delta,
+	})
+
+	update := &dynamodb.Update{Key: key}
+	update.SetTableName(c.PostsTable)
+	update.SetUpdateExpression("ADD commentsCount :delta")
+	update.SetConditionExpression("attribute_exists(id)")
+	update.SetExpressionAttributeValues(vals)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+	input.SetTransactItems([]*dynamodb.TransactWriteItem{
+		write,
+		{Update: update},
+	})
+
+	_, err = c.Provider.TransactWriteItems(input)
+	return err
+}
+
+// postKey looks up the primary key of the post with the given id
+func (c *Client) postKey(id string) (map[string]*dynamodb.AttributeValue, error) {
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":id": id,
+	})
+
+	input := &dynamodb.QueryInput{}
+	input.SetTableName(c.PostsTable)
+	input.SetKeyConditionExpression("id = :id")
+	input.SetExpressionAttributeValues(vals)
+	input.SetProjectionExpression("id, created")
+	input.SetLimit(1)
+
+	out, err := c.Provider.Query(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "postKey/Query error")
+	}
+
+	if len(out.Items) == 0 {
+		return nil, errors.New("postKey/NotFound")
+	}
+
+	return out.Items[0], nil
+}
+
+// Reconcile recomputes the `commentsCount` counter of every post from the
+// items on the comments table and returns the number of posts updated
+func (c *Client) Reconcile() (int, error) {
+	if c.PostsTable == "" {
+		return 0, errors.New("Reconcile: PostsTable is not set")
+	}
+
+	input := &dynamodb.ScanInput{}
+	input.SetTableName(c.PostsTable)
+	input.SetProjectionExpression("id, created")
+
+	updated := 0
+	for {
+		out, err := c.Provider.Scan(input)
+		if err != nil {
+			return updated, errors.Wrap(err, "Reconcile/Scan error")
+		}
+
+		for _, key := range out.Items {
+			var post struct{ ID string }
+			_ = dynamodbattribute.UnmarshalMap(key, &post)
+
+			count, err := c.countComments(post.ID)
+			if err != nil {
+				return updated, errors.Wrap(err, "Reconcile")
+			}
+
+			vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+				":count": count,
+			})
+
+			update := &dynamodb.UpdateItemInput{}
+			update.SetTableName(c.PostsTable)
+			update.SetKey(key)
+			update.SetUpdateExpression("SET commentsCount = :count")
+			update.SetExpressionAttributeValues(vals)
+
+			_, err = c.Provider.UpdateItem(update)
+			if err != nil {
+				return updated, errors.Wrap(err, "Reconcile/UpdateItem error")
+			}
+			updated++
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.SetExclusiveStartKey(out.LastEvaluatedKey)
+	}
+
+	return updated, nil
+}
+
+// countComments counts the comments of a post without reading the items
+func (c *Client) countComments(post string) (int64, error) {
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":post": post,
+	})
+
+	input := &dynamodb.QueryInput{}
+	input.SetTableName(c.TableName)
+	input.SetKeyConditionExpression("post = :post")
+	input.SetExpressionAttributeValues(vals)
+	input.SetSelect(dynamodb.SelectCount)
+
+	var count int64
+	for {
+		out, err := c.Provider.Query(input)
+		if err != nil {
+			return 0, errors.Wrap(err, "countComments/Query error")
+		}
+
+		count += *out.Count
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.SetExclusiveStartKey(out.LastEvaluatedKey)
+	}
+
+	return count, nil
+}
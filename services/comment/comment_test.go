@@ -0,0 +1,370 @@
+package comment
+
+import (
+	"regexp"
+	"testing"
+
+	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateComment(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		cm, e := c.CreateComment(map[string]interface{}{
+			"post":    "test",
+			"content": "hello",
+		})
+		assert.Nil(t, cm)
+		assert.Regexp(t, regexp.MustCompile(`(?i)createcomment/putitem error: beep`), e.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			return *input.ConditionExpression == "attribute_not_exists(id)"
+		})).Return(&dynamodb.PutItemOutput{}, nil)
+
+		cm, e := c.CreateComment(map[string]interface{}{
+			"post":     "test",
+			"username": "ing",
+			"content":  "hello",
+		})
+		assert.Nil(t, e)
+		assert.NotEmpty(t, cm.ID)
+		assert.Equal(t, "test", cm.Post)
+		assert.Equal(t, "ing", cm.Username)
+		assert.Equal(t, "hello", cm.Content)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestGetComments(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		cs, e := c.GetComments("test")
+		assert.Nil(t, cs)
+		assert.Regexp(t, regexp.MustCompile(`(?i)getcomments/query error: beep`), e.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		out := &dynamodb.QueryOutput{}
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "x",
+			"post":    "test",
+			"content": "hello",
+		})
+		out.SetItems([]map[string]*dynamodb.AttributeValue{
+			item,
+		})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.ScanIndexForward
+		})).Return(out, nil)
+
+		cs, e := c.GetComments("test")
+		assert.Nil(t, e)
+		assert.Equal(t, 1, len(cs))
+		assert.Equal(t, "x", cs[0].ID)
+		assert.Equal(t, "hello", cs[0].Content)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		e := c.UpdateComment("test", "x", "ing", "hello")
+		assert.NotNil(t, e)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return *input.ConditionExpression == "username = :username" &&
+				*input.ExpressionAttributeValues[":username"].S == "ing"
+		})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		e := c.UpdateComment("test", "x", "ing", "hello")
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		e := c.DeleteComment("test", "x", "ing")
+		assert.NotNil(t, e)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return *input.ConditionExpression == "username = :username"
+		})).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+		e := c.DeleteComment("test", "x", "ing")
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestModerate(t *testing.T) {
+	t.Run("hide error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		e := c.HideComment("test", "x", true)
+		assert.Regexp(t, regexp.MustCompile(`(?i)hidecomment: beep`), e.Error())
+	})
+
+	t.Run("hide ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return *input.ExpressionAttributeNames["#flag"] == "hidden" &&
+				*input.ExpressionAttributeValues[":value"].BOOL
+		})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		e := c.HideComment("test", "x", true)
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("pin ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return *input.ExpressionAttributeNames["#flag"] == "pinned" &&
+				!*input.ExpressionAttributeValues[":value"].BOOL
+		})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		e := c.PinComment("test", "x", false)
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return input.ConditionExpression == nil
+		})).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+		e := c.RemoveComment("test", "x")
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestThread(t *testing.T) {
+	comments := func() []*Comment {
+		return []*Comment{
+			{ID: "1", Username: "a"},
+			{ID: "2", Username: "b", Parent: "1"},
+			{ID: "3", Username: "c", Parent: "2"},
+			{ID: "4", Username: "d", Hidden: true},
+			{ID: "5", Username: "a", Parent: "4"},
+			{ID: "6", Username: "e", Pinned: true},
+			{ID: "7", Username: "f", Parent: "deleted"},
+		}
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		root := Thread(comments(), "", false)
+
+		assert.Equal(t, 3, len(root))
+		assert.Equal(t, "6", root[0].ID)
+		assert.Equal(t, "1", root[1].ID)
+		assert.Equal(t, "7", root[2].ID)
+		assert.Equal(t, "2", root[1].Replies[0].ID)
+		assert.Equal(t, "3", root[1].Replies[0].Replies[0].ID)
+	})
+
+	t.Run("hidden visible to writer", func(t *testing.T) {
+		root := Thread(comments(), "d", false)
+
+		assert.Equal(t, 4, len(root))
+		assert.Equal(t, "4", root[2].ID)
+		assert.Equal(t, "5", root[2].Replies[0].ID)
+	})
+
+	t.Run("hidden visible to moderator", func(t *testing.T) {
+		root := Thread(comments(), "z", true)
+		assert.Equal(t, 4, len(root))
+	})
+}
+
+func TestCommentCounter(t *testing.T) {
+	postKey := func(m *test.DynamoProviderMock) {
+		key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "test",
+			"created": 42,
+		})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.TableName == "posts"
+		})).Return(&dynamodb.QueryOutput{
+			Items: []map[string]*dynamodb.AttributeValue{key},
+		}, nil)
+	}
+
+	t.Run("post not found", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("comments", "b", m)
+		c.PostsTable = "posts"
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(&dynamodb.QueryOutput{}, nil)
+
+		cm, e := c.CreateComment(map[string]interface{}{"post": "test"})
+		assert.Nil(t, cm)
+		assert.Regexp(t, regexp.MustCompile(`(?i)postkey/notfound`), e.Error())
+		m.AssertNotCalled(t, "TransactWriteItems", mock.Anything)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("comments", "b", m)
+		c.PostsTable = "posts"
+
+		postKey(m)
+		m.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+			put := input.TransactItems[0].Put
+			update := input.TransactItems[1].Update
+			return *put.TableName == "comments" &&
+				*update.TableName == "posts" &&
+				*update.Key["created"].N == "42" &&
+				*update.UpdateExpression == "ADD commentsCount :delta" &&
+				*update.ExpressionAttributeValues[":delta"].N == "1"
+		})).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+		cm, e := c.CreateComment(map[string]interface{}{
+			"post":    "test",
+			"content": "hello",
+		})
+		assert.Nil(t, e)
+		assert.Equal(t, "hello", cm.Content)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("comments", "b", m)
+		c.PostsTable = "posts"
+
+		postKey(m)
+		m.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+			del := input.TransactItems[0].Delete
+			update := input.TransactItems[1].Update
+			return *del.TableName == "comments" &&
+				*del.ConditionExpression == "username = :username" &&
+				*update.ExpressionAttributeValues[":delta"].N == "-1"
+		})).Return(nil, errors.New("beep"))
+
+		e := c.DeleteComment("test", "x", "ing")
+		assert.Regexp(t, regexp.MustCompile(`(?i)deletecomment/transactwriteitems error: beep`), e.Error())
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("comments", "b", m)
+		c.PostsTable = "posts"
+
+		postKey(m)
+		m.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+			del := input.TransactItems[0].Delete
+			update := input.TransactItems[1].Update
+			return *del.ConditionExpression == "attribute_exists(id)" &&
+				*update.ExpressionAttributeValues[":delta"].N == "-1"
+		})).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+		e := c.RemoveComment("test", "x")
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("no posts table", func(t *testing.T) {
+		c := New("comments", "b", new(test.DynamoProviderMock))
+
+		_, e := c.Reconcile()
+		assert.NotNil(t, e)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("comments", "b", m)
+		c.PostsTable = "posts"
+
+		key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "test",
+			"created": 42,
+		})
+		m.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			return *input.TableName == "posts"
+		})).Return(&dynamodb.ScanOutput{
+			Items: []map[string]*dynamodb.AttributeValue{key},
+		}, nil)
+		count := &dynamodb.QueryOutput{}
+		count.SetCount(3)
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.Select == dynamodb.SelectCount &&
+				*input.KeyConditionExpression == "post = :post"
+		})).Return(count, nil)
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return *input.TableName == "posts" &&
+				*input.UpdateExpression == "SET commentsCount = :count" &&
+				*input.ExpressionAttributeValues[":count"].N == "3"
+		})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		n, e := c.Reconcile()
+		assert.Nil(t, e)
+		assert.Equal(t, 1, n)
+		m.AssertExpectations(t)
+	})
+}
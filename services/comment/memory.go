@@ -0,0 +1,164 @@
+package comment
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Memory keeps comments in memory, for as long as the process lives
+type Memory struct {
+	mu       sync.Mutex
+	comments map[string]map[string]*Comment
+
+	// Posts keeps the comment counter of posts in step, when set
+	Posts Counter
+}
+
+// NewMemory ...
+func NewMemory(posts Counter) *Memory {
+	return &Memory{
+		comments: map[string]map[string]*Comment{},
+		Posts:    posts,
+	}
+}
+
+// CreateComment adds a new comment to the given post. Set the `parent`
+// param to the id of another comment to post a reply.
+func (m *Memory) CreateComment(params map[string]interface{}) (*Comment, error) {
+	c, err := fromParams(newComment(params))
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateComment")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.count(c.Post, 1); err != nil {
+		return nil, errors.Wrap(err, "CreateComment")
+	}
+
+	if m.comments[c.Post] == nil {
+		m.comments[c.Post] = map[string]*Comment{}
+	}
+	m.comments[c.Post][c.ID] = c
+
+	return copyComment(c), nil
+}
+
+// GetComments lists all the comments of a post, oldest first
+func (m *Memory) GetComments(post string) ([]*Comment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	comments := []*Comment{}
+	for _, c := range m.comments[post] {
+		comments = append(comments, copyComment(c))
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return older(comments[i], comments[j])
+	})
+
+	return comments, nil
+}
+
+// UpdateComment changes the content of a comment. Only the user who wrote
+// the comment can update it.
+func (m *Memory) UpdateComment(post, id, username, content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.comments[post][id]
+	if !ok || c.Username != username {
+		return ErrNotFound
+	}
+
+	c.Content, c.Updated = content, time.Now().Unix()
+	return nil
+}
+
+// DeleteComment removes a comment. Only the user who wrote the comment
+// can delete it.
+func (m *Memory) DeleteComment(post, id, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.comments[post][id]
+	if !ok || c.Username != username {
+		return ErrNotFound
+	}
+
+	return m.remove(post, id)
+}
+
+// RemoveComment deletes any comment regardless of who wrote it. Callers
+// must make sure the current user is the author of the post.
+func (m *Memory) RemoveComment(post, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.comments[post][id]; !ok {
+		return ErrNotFound
+	}
+
+	return m.remove(post, id)
+}
+
+// HideComment hides or unhides a comment
+func (m *Memory) HideComment(post, id string, hidden bool) error {
+	return m.moderate(post, id, func(c *Comment) { c.Hidden = hidden })
+}
+
+// PinComment pins or unpins a comment
+func (m *Memory) PinComment(post, id string, pinned bool) error {
+	return m.moderate(post, id, func(c *Comment) { c.Pinned = pinned })
+}
+
+func (m *Memory) moderate(post, id string, set func(c *Comment)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.comments[post][id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	set(c)
+	return nil
+}
+
+// remove deletes a comment known to exist, m.mu must be held
+func (m *Memory) remove(post, id string) error {
+	if err := m.count(post, -1); err != nil {
+		return errors.Wrap(err, "remove")
+	}
+
+	delete(m.comments[post], id)
+	return nil
+}
+
+func (m *Memory) count(post string, delta int64) error {
+	if m.Posts == nil {
+		return nil
+	}
+
+	return m.Posts.AddComments(post, delta)
+}
+
+// older tells if a comes before b, oldest first
+func older(a, b *Comment) bool {
+	if a.Created != b.Created {
+		return a.Created < b.Created
+	}
+
+	return a.ID < b.ID
+}
+
+func copyComment(c *Comment) *Comment {
+	cp := *c
+	cp.Replies = nil
+	return &cp
+}
@@ -0,0 +1,55 @@
+package comment
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when there's no such comment, or it isn't the
+// user's to change
+var ErrNotFound = errors.New("Comment not found")
+
+// Repository stores comments. Client keeps them on Dynamo, Memory and
+// SQLite let the site run without it.
+type Repository interface {
+	CreateComment(params map[string]interface{}) (*Comment, error)
+	GetComments(post string) ([]*Comment, error)
+	UpdateComment(post, id, username, content string) error
+	DeleteComment(post, id, username string) error
+	RemoveComment(post, id string) error
+	HideComment(post, id string, hidden bool) error
+	PinComment(post, id string, pinned bool) error
+}
+
+// Counter keeps the comment counter of posts, see post.Memory
+type Counter interface {
+	AddComments(id string, delta int64) error
+}
+
+// newComment fills in what CreateComment sets on every new comment: its
+// dates and an id
+func newComment(params map[string]interface{}) map[string]interface{} {
+	now := time.Now()
+	params["created"] = now.Unix()
+	params["updated"] = now.Unix()
+
+	// base36 nanoseconds keeps the range key unique and sortable
+	params["id"] = strconv.FormatInt(now.UnixNano(), 36)
+
+	return params
+}
+
+// fromParams makes a comment of attributes named as they are on Dynamo
+func fromParams(params map[string]interface{}) (*Comment, error) {
+	item, err := dynamodbattribute.MarshalMap(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Comment
+	err = dynamodbattribute.UnmarshalMap(item, &c)
+	return &c, err
+}
@@ -0,0 +1,143 @@
+package comment
+
+import (
+	"testing"
+
+	"bishack.dev/services/post"
+	"bishack.dev/services/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	testRepository(t, func() (Repository, post.Repository) {
+		posts := post.NewMemory()
+		return NewMemory(posts), posts
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	testRepository(t, func() (Repository, post.Repository) {
+		db, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewSQLite(db), post.NewSQLite(db)
+	})
+}
+
+// testRepository checks the behaviour every backend shares, the comment
+// counter of posts included
+func testRepository(t *testing.T, newRepo func() (Repository, post.Repository)) {
+	setup := func() (Repository, func() int64, string) {
+		r, posts := newRepo()
+		p := posts.CreatePost(map[string]interface{}{
+			"title":    "test",
+			"username": "ing",
+			"publish":  1,
+		})
+
+		count := func() int64 {
+			return posts.GetPost("ing", p.ID).CommentsCount
+		}
+
+		return r, count, p.ID
+	}
+
+	comment := func(post, username, content string) map[string]interface{} {
+		return map[string]interface{}{
+			"post":     post,
+			"username": username,
+			"author":   "Test",
+			"content":  content,
+		}
+	}
+
+	t.Run("create and list", func(t *testing.T) {
+		r, count, id := setup()
+
+		a, err := r.CreateComment(comment(id, "a", "first"))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, a.ID)
+		assert.NotZero(t, a.Created)
+
+		reply := comment(id, "b", "second")
+		reply["parent"] = a.ID
+		_, err = r.CreateComment(reply)
+		assert.Nil(t, err)
+
+		assert.Equal(t, int64(2), count())
+
+		comments, err := r.GetComments(id)
+		assert.Nil(t, err)
+		assert.Len(t, comments, 2)
+		assert.Equal(t, "first", comments[0].Content)
+		assert.Equal(t, "Test", comments[0].Author)
+		assert.Equal(t, a.ID, comments[1].Parent)
+
+		comments, err = r.GetComments("nope")
+		assert.Nil(t, err)
+		assert.Empty(t, comments)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		r, _, id := setup()
+
+		c, _ := r.CreateComment(comment(id, "a", "first"))
+
+		assert.Equal(t, ErrNotFound, r.UpdateComment(id, c.ID, "b", "nope"))
+		assert.Nil(t, r.UpdateComment(id, c.ID, "a", "edited"))
+
+		comments, _ := r.GetComments(id)
+		assert.Equal(t, "edited", comments[0].Content)
+	})
+
+	t.Run("delete and remove", func(t *testing.T) {
+		r, count, id := setup()
+
+		a, _ := r.CreateComment(comment(id, "a", "first"))
+		b, _ := r.CreateComment(comment(id, "b", "second"))
+
+		assert.Equal(t, ErrNotFound, r.DeleteComment(id, a.ID, "b"))
+		assert.Nil(t, r.DeleteComment(id, a.ID, "a"))
+		assert.Equal(t, ErrNotFound, r.DeleteComment(id, a.ID, "a"))
+		assert.Equal(t, int64(1), count())
+
+		assert.Nil(t, r.RemoveComment(id, b.ID))
+		assert.Equal(t, ErrNotFound, r.RemoveComment(id, b.ID))
+		assert.Equal(t, int64(0), count())
+
+		comments, _ := r.GetComments(id)
+		assert.Empty(t, comments)
+	})
+
+	t.Run("moderate", func(t *testing.T) {
+		r, _, id := setup()
+
+		c, _ := r.CreateComment(comment(id, "a", "first"))
+
+		assert.Nil(t, r.HideComment(id, c.ID, true))
+		assert.Nil(t, r.PinComment(id, c.ID, true))
+
+		comments, _ := r.GetComments(id)
+		assert.True(t, comments[0].Hidden)
+		assert.True(t, comments[0].Pinned)
+
+		assert.Nil(t, r.HideComment(id, c.ID, false))
+		comments, _ = r.GetComments(id)
+		assert.False(t, comments[0].Hidden)
+
+		assert.Equal(t, ErrNotFound, r.HideComment(id, "nope", true))
+		assert.Equal(t, ErrNotFound, r.PinComment(id, "nope", true))
+	})
+
+	t.Run("missing post", func(t *testing.T) {
+		r, _, _ := setup()
+
+		_, err := r.CreateComment(comment("nope", "a", "first"))
+		assert.NotNil(t, err)
+
+		comments, _ := r.GetComments("nope")
+		assert.Empty(t, comments)
+	})
+}
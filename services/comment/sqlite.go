@@ -0,0 +1,167 @@
+package comment
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SQLite keeps comments on an embedded database, see services/sqlite. The
+// comment counter on the posts table is updated along with them.
+type SQLite struct {
+	DB *sql.DB
+}
+
+// NewSQLite ...
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db}
+}
+
+// CreateComment adds a new comment to the given post. Set the `parent`
+// param to the id of another comment to post a reply.
+func (s *SQLite) CreateComment(params map[string]interface{}) (*Comment, error) {
+	c, err := fromParams(newComment(params))
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateComment")
+	}
+
+	err = s.write(
+		c.Post,
+		1,
+		`INSERT INTO comments (post, id, parent, author, username, userPic, content, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Post,
+		c.ID,
+		c.Parent,
+		c.Author,
+		c.Username,
+		c.UserPic,
+		c.Content,
+		c.Created,
+		c.Updated,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateComment")
+	}
+
+	return c, nil
+}
+
+// GetComments lists all the comments of a post, oldest first
+func (s *SQLite) GetComments(post string) ([]*Comment, error) {
+	rows, err := s.DB.Query(
+		`SELECT post, id, parent, author, username, userPic, content, hidden, pinned, created, updated
+		FROM comments WHERE post = ? ORDER BY created, id`,
+		post,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetComments/Query error")
+	}
+	defer rows.Close()
+
+	comments := []*Comment{}
+	for rows.Next() {
+		c := &Comment{}
+		err := rows.Scan(
+			&c.Post,
+			&c.ID,
+			&c.Parent,
+			&c.Author,
+			&c.Username,
+			&c.UserPic,
+			&c.Content,
+			&c.Hidden,
+			&c.Pinned,
+			&c.Created,
+			&c.Updated,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetComments/Scan error")
+		}
+
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// UpdateComment changes the content of a comment. Only the user who wrote
+// the comment can update it.
+func (s *SQLite) UpdateComment(post, id, username, content string) error {
+	return s.exec(
+		"UPDATE comments SET content = ?, updated = ? WHERE post = ? AND id = ? AND username = ?",
+		content,
+		time.Now().Unix(),
+		post,
+		id,
+		username,
+	)
+}
+
+// DeleteComment removes a comment. Only the user who wrote the comment
+// can delete it.
+func (s *SQLite) DeleteComment(post, id, username string) error {
+	return s.write(post, -1, "DELETE FROM comments WHERE post = ? AND id = ? AND username = ?", post, id, username)
+}
+
+// RemoveComment deletes any comment regardless of who wrote it. Callers
+// must make sure the current user is the author of the post.
+func (s *SQLite) RemoveComment(post, id string) error {
+	return s.write(post, -1, "DELETE FROM comments WHERE post = ? AND id = ?", post, id)
+}
+
+// HideComment hides or unhides a comment
+func (s *SQLite) HideComment(post, id string, hidden bool) error {
+	return s.exec("UPDATE comments SET hidden = ? WHERE post = ? AND id = ?", hidden, post, id)
+}
+
+// PinComment pins or unpins a comment
+func (s *SQLite) PinComment(post, id string, pinned bool) error {
+	return s.exec("UPDATE comments SET pinned = ? WHERE post = ? AND id = ?", pinned, post, id)
+}
+
+// exec runs a statement that must change a comment, ErrNotFound if none did
+func (s *SQLite) exec(query string, args ...interface{}) error {
+	res, err := s.DB.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "exec/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// write runs the given statement on the comments table and moves the
+// counter of the post by delta in the same transaction. ErrNotFound is
+// returned if the statement didn't change anything.
+func (s *SQLite) write(post string, delta int, query string, args ...interface{}) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return errors.Wrap(err, "write/Begin error")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "write/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	res, err = tx.Exec("UPDATE posts SET commentsCount = commentsCount + ? WHERE id = ?", delta, post)
+	if err != nil {
+		return errors.Wrap(err, "write/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("write/NotFound")
+	}
+
+	return tx.Commit()
+}
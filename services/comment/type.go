@@ -0,0 +1,19 @@
+package comment
+
+// Comment ...
+type Comment struct {
+	ID       string
+	Post     string
+	Parent   string
+	Author   string
+	Username string
+	UserPic  string
+	Content  string
+	Hidden   bool
+	Pinned   bool
+	Created  int64
+	Updated  int64
+
+	// Replies is populated by Thread and never persisted
+	Replies []*Comment `dynamodbav:"-"`
+}
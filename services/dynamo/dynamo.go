@@ -1,18 +1,29 @@
 package dynamo
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded
+var ErrInvalidCursor = errors.New("Invalid cursor")
+
 // Provider ...
 type Provider interface {
+	GetItem(*dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
 	PutItem(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
 	UpdateItem(*dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error)
 	DeleteItem(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
 	Query(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	Scan(*dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	BatchGetItem(*dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error)
+	TransactWriteItems(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error)
 	DescribeTable(*dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error)
 }
 
@@ -57,12 +68,13 @@ func New(
 // Query ...
 // just pass in the following:
 //
-//  in      - index name (optional)
-//  fs      - filter expression string
-//  ks      - key expression string
-//  vals    - expression attribute values
-//  forward - to ascending or not
-//  limit   - total number of returned rows
+//	in      - index name (optional)
+//	fs      - filter expression string
+//	ks      - key expression string
+//	vals    - expression attribute values
+//	forward - to ascending or not
+//	limit   - total number of returned rows
+//	start   - exclusive start key from a previous LastEvaluatedKey (optional)
 func (c *Client) Query(
 	in,
 	ks,
@@ -70,6 +82,7 @@ func (c *Client) Query(
 	vals map[string]interface{},
 	forward bool,
 	limit int64,
+	start map[string]*dynamodb.AttributeValue,
 ) (*dynamodb.QueryOutput, error) {
 	// marshal values
 	values, _ := dynamodbattribute.MarshalMap(vals)
@@ -93,6 +106,41 @@ func (c *Client) Query(
 	if fs != "" {
 		input.SetFilterExpression(fs)
 	}
+	// if start key exists
+	if len(start) > 0 {
+		input.SetExclusiveStartKey(start)
+	}
 
 	return c.Provider.Query(input)
 }
+
+// EncodeCursor turns a LastEvaluatedKey into an opaque string that is safe
+// to use on URLs. An empty key gives an empty cursor.
+func EncodeCursor(key map[string]*dynamodb.AttributeValue) string {
+	if len(key) == 0 {
+		return ""
+	}
+
+	b, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor turns a cursor from EncodeCursor back into an exclusive
+// start key. An empty cursor gives a nil key.
+func DecodeCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var key map[string]*dynamodb.AttributeValue
+	if err := json.Unmarshal(b, &key); err != nil || len(key) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return key, nil
+}
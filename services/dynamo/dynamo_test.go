@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -38,7 +39,57 @@ func TestQuery(t *testing.T) {
 		map[string]interface{}{},
 		false,
 		1,
+		nil,
 	)
 
 	assert.NotNil(t, err)
 }
+
+func TestQueryStartKey(t *testing.T) {
+	p := new(test.DynamoProviderMock)
+	c := New("", "", p)
+
+	start := map[string]*dynamodb.AttributeValue{
+		"id": {S: aws.String("test")},
+	}
+	p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.ExclusiveStartKey["id"].S == "test"
+	})).Return(&dynamodb.QueryOutput{}, nil)
+
+	_, err := c.Query("", "test", "", map[string]interface{}{}, false, 1, start)
+
+	assert.Nil(t, err)
+	p.AssertExpectations(t)
+}
+
+func TestCursor(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "", EncodeCursor(nil))
+
+		key, err := DecodeCursor("")
+		assert.Nil(t, key)
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := DecodeCursor("!!!")
+		assert.Equal(t, ErrInvalidCursor, err)
+
+		_, err = DecodeCursor("bm9wZQ")
+		assert.Equal(t, ErrInvalidCursor, err)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		key := map[string]*dynamodb.AttributeValue{
+			"id":      {S: aws.String("hello-world-42")},
+			"created": {N: aws.String("42")},
+		}
+
+		cursor := EncodeCursor(key)
+		assert.NotEmpty(t, cursor)
+
+		decoded, err := DecodeCursor(cursor)
+		assert.Nil(t, err)
+		assert.Equal(t, key, decoded)
+	})
+}
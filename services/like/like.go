@@ -4,14 +4,19 @@ import (
 	"time"
 
 	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/pkg/errors"
 )
 
-// Client ...
+// Client keeps likes on Dynamo
 type Client struct {
 	*dynamo.Client
+
+	// PostsTable is the table holding the `likes` counter of each post.
+	// When set, likes and the counter are written in one transaction.
+	PostsTable string
 }
 
 // New ...
@@ -21,19 +26,18 @@ func New(
 	provider dynamo.Provider,
 ) *Client {
 	return &Client{
-		dynamo.New(tableName, endpoint, provider),
+		Client: dynamo.New(tableName, endpoint, provider),
 	}
 }
 
 // GetLikes ...
 func (c *Client) GetLikes(id string) ([]*Like, error) {
-	ks := "id = :id and created > :created"
+	ks := "id = :id"
 	vals := map[string]interface{}{
-		":id":      id,
-		":created": 0,
+		":id": id,
 	}
 
-	out, err := c.Query("", ks, "", vals, false, 0)
+	out, err := c.Query("", ks, "", vals, false, 0, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetLikes/Query error")
 	}
@@ -48,43 +52,47 @@ func (c *Client) GetLikes(id string) ([]*Like, error) {
 	return likes, nil
 }
 
-// ToggleLike ...
+// ToggleLike likes the post if the user hasn't yet, otherwise unlikes it
 func (c *Client) ToggleLike(id, username string) error {
-	// if not found, we add
-	l, err := c.GetLike(id, username)
-	if err != nil {
-		err := c.addLike(id, username)
-		if err != nil {
-			return errors.Wrap(err, "Like")
-		}
-		return nil
-	}
+	return toggle(c, id, username)
+}
 
-	// otherwise, we delete
-	err = c.removeLike(id, l.Created)
-	if err != nil {
+// Like adds the like of a user to a post. Liking a post twice is a no-op.
+func (c *Client) Like(id, username string) error {
+	err := c.addLike(id, username)
+	if err != nil && !conditionFailed(err) {
 		return errors.Wrap(err, "Like")
 	}
+
+	return nil
+}
+
+// Unlike removes the like of a user from a post. Unliking a post that
+// wasn't liked is a no-op.
+func (c *Client) Unlike(id, username string) error {
+	err := c.removeLike(id, username)
+	if err != nil && !conditionFailed(err) {
+		return errors.Wrap(err, "Unlike")
+	}
+
 	return nil
 }
 
 // GetLike ...
 func (c *Client) GetLike(id, username string) (*Like, error) {
-	ks := "id = :id and created > :created"
-	fs := "username = :username"
+	ks := "id = :id and username = :username"
 	vals := map[string]interface{}{
 		":id":       id,
-		":created":  0,
 		":username": username,
 	}
 
-	out, err := c.Query("", ks, fs, vals, false, 0)
+	out, err := c.Query("", ks, "", vals, false, 0, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetLike/Query error")
 	}
 
 	if len(out.Items) == 0 {
-		return nil, errors.New("Not found")
+		return nil, ErrNotFound
 	}
 
 	var like Like
@@ -92,7 +100,9 @@ func (c *Client) GetLike(id, username string) (*Like, error) {
 	return &like, nil
 }
 
-// addLike adds a new item on the likes table with the given username and id
+// addLike adds a new item on the likes table with the given username and id.
+// The table is keyed by (id, username) so the write fails if the user has
+// already liked the post.
 func (c *Client) addLike(id, username string) error {
 	item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
 		"id":       id,
@@ -100,34 +110,203 @@ func (c *Client) addLike(id, username string) error {
 		"created":  time.Now().Unix(),
 	})
 
+	if c.PostsTable != "" {
+		put := &dynamodb.Put{Item: item}
+		put.SetTableName(c.TableName)
+		put.SetConditionExpression("attribute_not_exists(id)")
+
+		return c.writeWithCounter(id, 1, &dynamodb.TransactWriteItem{Put: put})
+	}
+
 	input := &dynamodb.PutItemInput{
 		Item: item,
 	}
 	input.SetTableName(c.TableName)
+	input.SetConditionExpression("attribute_not_exists(id)")
 
 	_, err := c.Provider.PutItem(input)
-	if err != nil {
-		return errors.Wrap(err, "addLike/PutItem error")
-	}
-
-	return nil
+	return err
 }
 
-// removeLike removes like
-func (c *Client) removeLike(id string, created int64) error {
+// removeLike removes the like of the user. The write fails if the user
+// hasn't liked the post.
+func (c *Client) removeLike(id, username string) error {
 	keys, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
-		"id":      id,
-		"created": created,
+		"id":       id,
+		"username": username,
 	})
 
+	if c.PostsTable != "" {
+		del := &dynamodb.Delete{Key: keys}
+		del.SetTableName(c.TableName)
+		del.SetConditionExpression("attribute_exists(id)")
+
+		return c.writeWithCounter(id, -1, &dynamodb.TransactWriteItem{Delete: del})
+	}
+
 	input := &dynamodb.DeleteItemInput{}
 	input.SetTableName(c.TableName)
 	input.SetKey(keys)
+	input.SetConditionExpression("attribute_exists(id)")
 
 	_, err := c.Provider.DeleteItem(input)
+	return err
+}
+
+// conditionFailed reports whether the write to the likes table was
+// rejected by its condition, either on its own or as the first item of a
+// transaction
+func conditionFailed(err error) bool {
+	if tx, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		reasons := tx.CancellationReasons
+		return len(reasons) > 0 &&
+			reasons[0].Code != nil &&
+			*reasons[0].Code == "ConditionalCheckFailed"
+	}
+
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+
+	return false
+}
+
+// writeWithCounter runs the given write together with an update of the
+// `likes` counter of the post so the two never drift apart
+func (c *Client) writeWithCounter(
+	id string,
+	delta int,
+	write *dynamodb.TransactWriteItem,
+) error {
+	key, err := c.postKey(id)
 	if err != nil {
-		return errors.Wrap(err, "removeLike/DeleteItem error")
+		return err
 	}
 
-	return nil
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":delta": delta,
+	})
+
+	update := &dynamodb.Update{Key: key}
+	update.SetTableName(c.PostsTable)
+	update.SetUpdateExpression("ADD likes :delta")
+	update.SetConditionExpression("attribute_exists(id)")
+	update.SetExpressionAttributeValues(vals)
+
+	input := &dynamodb.TransactWriteItemsInput{}
+	input.SetTransactItems([]*dynamodb.TransactWriteItem{
+		write,
+		{Update: update},
+	})
+
+	_, err = c.Provider.TransactWriteItems(input)
+	return err
+}
+
+// postKey looks up the primary key of the post with the given id
+func (c *Client) postKey(id string) (map[string]*dynamodb.AttributeValue, error) {
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":id": id,
+	})
+
+	input := &dynamodb.QueryInput{}
+	input.SetTableName(c.PostsTable)
+	input.SetKeyConditionExpression("id = :id")
+	input.SetExpressionAttributeValues(vals)
+	input.SetProjectionExpression("id, created")
+	input.SetLimit(1)
+
+	out, err := c.Provider.Query(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "postKey/Query error")
+	}
+
+	if len(out.Items) == 0 {
+		return nil, errors.New("postKey/NotFound")
+	}
+
+	return out.Items[0], nil
+}
+
+// Reconcile recomputes the `likes` counter of every post from the items on
+// the likes table and returns the number of posts updated
+func (c *Client) Reconcile() (int, error) {
+	if c.PostsTable == "" {
+		return 0, errors.New("Reconcile: PostsTable is not set")
+	}
+
+	input := &dynamodb.ScanInput{}
+	input.SetTableName(c.PostsTable)
+	input.SetProjectionExpression("id, created")
+
+	updated := 0
+	for {
+		out, err := c.Provider.Scan(input)
+		if err != nil {
+			return updated, errors.Wrap(err, "Reconcile/Scan error")
+		}
+
+		for _, key := range out.Items {
+			var post struct{ ID string }
+			_ = dynamodbattribute.UnmarshalMap(key, &post)
+
+			count, err := c.countLikes(post.ID)
+			if err != nil {
+				return updated, errors.Wrap(err, "Reconcile")
+			}
+
+			vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+				":count": count,
+			})
+
+			update := &dynamodb.UpdateItemInput{}
+			update.SetTableName(c.PostsTable)
+			update.SetKey(key)
+			update.SetUpdateExpression("SET likes = :count")
+			update.SetExpressionAttributeValues(vals)
+
+			_, err = c.Provider.UpdateItem(update)
+			if err != nil {
+				return updated, errors.Wrap(err, "Reconcile/UpdateItem error")
+			}
+			updated++
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.SetExclusiveStartKey(out.LastEvaluatedKey)
+	}
+
+	return updated, nil
+}
+
+// countLikes counts the likes of a post without reading the items
+func (c *Client) countLikes(id string) (int64, error) {
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":id": id,
+	})
+
+	input := &dynamodb.QueryInput{}
+	input.SetTableName(c.TableName)
+	input.SetKeyConditionExpression("id = :id")
+	input.SetExpressionAttributeValues(vals)
+	input.SetSelect(dynamodb.SelectCount)
+
+	var count int64
+	for {
+		out, err := c.Provider.Query(input)
+		if err != nil {
+			return 0, errors.Wrap(err, "countLikes/Query error")
+		}
+
+		count += *out.Count
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.SetExclusiveStartKey(out.LastEvaluatedKey)
+	}
+
+	return count, nil
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/pkg/errors"
@@ -102,7 +104,7 @@ func TestRemoveLike(t *testing.T) {
 			return true
 		})).Return(nil, errors.New("boop"))
 
-		e := c.removeLike("test", 123)
+		e := c.removeLike("test", "ing")
 		assert.NotNil(t, e)
 	})
 
@@ -114,7 +116,7 @@ func TestRemoveLike(t *testing.T) {
 			return true
 		})).Return(&dynamodb.DeleteItemOutput{}, nil)
 
-		e := c.removeLike("test", 123)
+		e := c.removeLike("test", "ing")
 		assert.Nil(t, e)
 	})
 }
@@ -254,3 +256,187 @@ func TestGetLikes(t *testing.T) {
 		assert.Equal(t, "ing", l[0].Username)
 	})
 }
+
+func TestToggleLikeCounter(t *testing.T) {
+	postKey := func(m *test.DynamoProviderMock) {
+		key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "test",
+			"created": 42,
+		})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.TableName == "posts"
+		})).Return(&dynamodb.QueryOutput{
+			Items: []map[string]*dynamodb.AttributeValue{key},
+		}, nil)
+	}
+
+	t.Run("post not found", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("likes", "b", m)
+		c.PostsTable = "posts"
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(&dynamodb.QueryOutput{}, nil)
+
+		e := c.ToggleLike("test", "ing")
+		assert.Regexp(t, regexp.MustCompile(`(?i)postkey/notfound`), e.Error())
+		m.AssertNotCalled(t, "TransactWriteItems", mock.Anything)
+	})
+
+	t.Run("like", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("likes", "b", m)
+		c.PostsTable = "posts"
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.TableName == "likes"
+		})).Return(&dynamodb.QueryOutput{}, nil)
+		postKey(m)
+		m.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+			put := input.TransactItems[0].Put
+			update := input.TransactItems[1].Update
+			return *put.TableName == "likes" &&
+				*update.TableName == "posts" &&
+				*update.Key["created"].N == "42" &&
+				*update.UpdateExpression == "ADD likes :delta" &&
+				*update.ExpressionAttributeValues[":delta"].N == "1"
+		})).Return(&dynamodb.TransactWriteItemsOutput{}, nil)
+
+		e := c.ToggleLike("test", "ing")
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("unlike", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("likes", "b", m)
+		c.PostsTable = "posts"
+
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":       "test",
+			"username": "ing",
+			"created":  1,
+		})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.TableName == "likes"
+		})).Return(&dynamodb.QueryOutput{
+			Items: []map[string]*dynamodb.AttributeValue{item},
+		}, nil)
+		postKey(m)
+		m.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+			del := input.TransactItems[0].Delete
+			update := input.TransactItems[1].Update
+			return *del.TableName == "likes" &&
+				*update.ExpressionAttributeValues[":delta"].N == "-1"
+		})).Return(nil, errors.New("beep"))
+
+		e := c.ToggleLike("test", "ing")
+		assert.Regexp(t, regexp.MustCompile(`(?i)unlike: beep`), e.Error())
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("no posts table", func(t *testing.T) {
+		c := New("likes", "b", new(test.DynamoProviderMock))
+
+		_, e := c.Reconcile()
+		assert.NotNil(t, e)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("likes", "b", m)
+		c.PostsTable = "posts"
+
+		key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "test",
+			"created": 42,
+		})
+		m.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			return *input.TableName == "posts"
+		})).Return(&dynamodb.ScanOutput{
+			Items: []map[string]*dynamodb.AttributeValue{key},
+		}, nil)
+		count := &dynamodb.QueryOutput{}
+		count.SetCount(3)
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.Select == dynamodb.SelectCount
+		})).Return(count, nil)
+		m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+			return *input.TableName == "posts" &&
+				*input.UpdateExpression == "SET likes = :count" &&
+				*input.ExpressionAttributeValues[":count"].N == "3"
+		})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+		n, e := c.Reconcile()
+		assert.Nil(t, e)
+		assert.Equal(t, 1, n)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestLikeIdempotent(t *testing.T) {
+	t.Run("already liked", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			return *input.ConditionExpression == "attribute_not_exists(id)"
+		})).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+		assert.Nil(t, c.Like("test", "ing"))
+	})
+
+	t.Run("already unliked", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return *input.Key["username"].S == "ing" &&
+				*input.ConditionExpression == "attribute_exists(id)"
+		})).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+		assert.Nil(t, c.Unlike("test", "ing"))
+	})
+
+	canceled := func(codes ...string) error {
+		reasons := []*dynamodb.CancellationReason{}
+		for _, code := range codes {
+			reasons = append(reasons, &dynamodb.CancellationReason{Code: aws.String(code)})
+		}
+		return &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+	}
+
+	for name, tc := range map[string]struct {
+		err error
+		ok  bool
+	}{
+		"like exists":    {canceled("ConditionalCheckFailed", "None"), true},
+		"post is gone":   {canceled("None", "ConditionalCheckFailed"), false},
+		"other failures": {errors.New("beep"), false},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			m := new(test.DynamoProviderMock)
+			c := New("likes", "b", m)
+			c.PostsTable = "posts"
+
+			key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+				"id":      "test",
+				"created": 42,
+			})
+			m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+				return true
+			})).Return(&dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{key},
+			}, nil)
+			m.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+				return true
+			})).Return(nil, tc.err)
+
+			e := c.Like("test", "ing")
+			assert.Equal(t, tc.ok, e == nil)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package like
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Memory keeps likes in memory, for as long as the process lives
+type Memory struct {
+	mu    sync.Mutex
+	likes map[string]map[string]*Like
+
+	// Posts keeps the like counter of posts in step, when set
+	Posts Counter
+}
+
+// NewMemory ...
+func NewMemory(posts Counter) *Memory {
+	return &Memory{
+		likes: map[string]map[string]*Like{},
+		Posts: posts,
+	}
+}
+
+// GetLike ...
+func (m *Memory) GetLike(id, username string) (*Like, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.likes[id][username]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	cp := *l
+	return &cp, nil
+}
+
+// ToggleLike likes the post if the user hasn't yet, otherwise unlikes it
+func (m *Memory) ToggleLike(id, username string) error {
+	return toggle(m, id, username)
+}
+
+// Like adds the like of a user to a post. Liking a post twice is a no-op.
+func (m *Memory) Like(id, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.likes[id][username]; ok {
+		return nil
+	}
+
+	if err := m.count(id, 1); err != nil {
+		return errors.Wrap(err, "Like")
+	}
+
+	if m.likes[id] == nil {
+		m.likes[id] = map[string]*Like{}
+	}
+	m.likes[id][username] = &Like{ID: id, Username: username, Created: time.Now().Unix()}
+
+	return nil
+}
+
+// Unlike removes the like of a user from a post. Unliking a post that
+// wasn't liked is a no-op.
+func (m *Memory) Unlike(id, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.likes[id][username]; !ok {
+		return nil
+	}
+
+	if err := m.count(id, -1); err != nil {
+		return errors.Wrap(err, "Unlike")
+	}
+
+	delete(m.likes[id], username)
+	return nil
+}
+
+func (m *Memory) count(id string, delta int64) error {
+	if m.Posts == nil {
+		return nil
+	}
+
+	return m.Posts.AddLikes(id, delta)
+}
@@ -0,0 +1,29 @@
+package like
+
+import "github.com/pkg/errors"
+
+// ErrNotFound is returned when a user hasn't liked a post
+var ErrNotFound = errors.New("Not found")
+
+// Repository stores likes. Client keeps them on Dynamo, Memory and SQLite
+// let the site run without it.
+type Repository interface {
+	GetLike(id, username string) (*Like, error)
+	ToggleLike(id, username string) error
+	Like(id, username string) error
+	Unlike(id, username string) error
+}
+
+// Counter keeps the like counter of posts, see post.Memory
+type Counter interface {
+	AddLikes(id string, delta int64) error
+}
+
+// toggle likes the post if the user hasn't yet, otherwise unlikes it
+func toggle(r Repository, id, username string) error {
+	if _, err := r.GetLike(id, username); err != nil {
+		return r.Like(id, username)
+	}
+
+	return r.Unlike(id, username)
+}
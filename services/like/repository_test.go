@@ -0,0 +1,86 @@
+package like
+
+import (
+	"testing"
+
+	"bishack.dev/services/post"
+	"bishack.dev/services/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	testRepository(t, func() (Repository, post.Repository) {
+		posts := post.NewMemory()
+		return NewMemory(posts), posts
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	testRepository(t, func() (Repository, post.Repository) {
+		db, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewSQLite(db), post.NewSQLite(db)
+	})
+}
+
+// testRepository checks the behaviour every backend shares, the like
+// counter of posts included
+func testRepository(t *testing.T, newRepo func() (Repository, post.Repository)) {
+	setup := func() (Repository, func() int64, string) {
+		r, posts := newRepo()
+		p := posts.CreatePost(map[string]interface{}{
+			"title":    "test",
+			"username": "ing",
+			"publish":  1,
+		})
+
+		count := func() int64 {
+			return posts.GetPost("ing", p.ID).LikesCount
+		}
+
+		return r, count, p.ID
+	}
+
+	t.Run("like and unlike", func(t *testing.T) {
+		r, count, id := setup()
+
+		_, err := r.GetLike(id, "test")
+		assert.Equal(t, ErrNotFound, err)
+
+		assert.Nil(t, r.Like(id, "test"))
+		assert.Nil(t, r.Like(id, "test"))
+		assert.Equal(t, int64(1), count())
+
+		l, err := r.GetLike(id, "test")
+		assert.Nil(t, err)
+		assert.Equal(t, "test", l.Username)
+		assert.NotZero(t, l.Created)
+
+		assert.Nil(t, r.Unlike(id, "test"))
+		assert.Nil(t, r.Unlike(id, "test"))
+		assert.Equal(t, int64(0), count())
+	})
+
+	t.Run("toggle", func(t *testing.T) {
+		r, count, id := setup()
+
+		assert.Nil(t, r.ToggleLike(id, "a"))
+		assert.Nil(t, r.ToggleLike(id, "b"))
+		assert.Equal(t, int64(2), count())
+
+		assert.Nil(t, r.ToggleLike(id, "a"))
+		assert.Equal(t, int64(1), count())
+	})
+
+	t.Run("missing post", func(t *testing.T) {
+		r, _, _ := setup()
+
+		assert.NotNil(t, r.Like("nope", "test"))
+
+		_, err := r.GetLike("nope", "test")
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
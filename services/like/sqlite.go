@@ -0,0 +1,90 @@
+package like
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SQLite keeps likes on an embedded database, see services/sqlite. The like
+// counter on the posts table is updated along with them.
+type SQLite struct {
+	DB *sql.DB
+}
+
+// NewSQLite ...
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db}
+}
+
+// GetLike ...
+func (s *SQLite) GetLike(id, username string) (*Like, error) {
+	l := &Like{}
+	err := s.DB.QueryRow(
+		"SELECT id, username, created FROM likes WHERE id = ? AND username = ?",
+		id,
+		username,
+	).Scan(&l.ID, &l.Username, &l.Created)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "GetLike/QueryRow error")
+	}
+
+	return l, nil
+}
+
+// ToggleLike likes the post if the user hasn't yet, otherwise unlikes it
+func (s *SQLite) ToggleLike(id, username string) error {
+	return toggle(s, id, username)
+}
+
+// Like adds the like of a user to a post. Liking a post twice is a no-op.
+func (s *SQLite) Like(id, username string) error {
+	return s.write(
+		id,
+		1,
+		"INSERT OR IGNORE INTO likes (id, username, created) VALUES (?, ?, ?)",
+		id,
+		username,
+		time.Now().Unix(),
+	)
+}
+
+// Unlike removes the like of a user from a post. Unliking a post that
+// wasn't liked is a no-op.
+func (s *SQLite) Unlike(id, username string) error {
+	return s.write(id, -1, "DELETE FROM likes WHERE id = ? AND username = ?", id, username)
+}
+
+// write runs the given statement on the likes table and, if it changed
+// anything, moves the counter of the post by delta in the same transaction
+func (s *SQLite) write(id string, delta int, query string, args ...interface{}) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return errors.Wrap(err, "write/Begin error")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, "write/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	res, err = tx.Exec("UPDATE posts SET likes = likes + ? WHERE id = ?", delta, id)
+	if err != nil {
+		return errors.Wrap(err, "write/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("write/NotFound")
+	}
+
+	return tx.Commit()
+}
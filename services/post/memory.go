@@ -0,0 +1,205 @@
+package post
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory keeps posts in memory, for as long as the process lives
+type Memory struct {
+	mu    sync.RWMutex
+	posts map[Key]*Post
+}
+
+// NewMemory ...
+func NewMemory() *Memory {
+	return &Memory{posts: map[Key]*Post{}}
+}
+
+// CreatePost creates a new post
+func (m *Memory) CreatePost(params map[string]interface{}) *Post {
+	p := &Post{}
+	if err := apply(p, newPost(params)); err != nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	m.posts[Key{p.ID, p.Created}] = p
+	m.mu.Unlock()
+
+	return copyPost(p)
+}
+
+// GetPost ...
+func (m *Memory) GetPost(username, id string) *Post {
+	posts := m.find(func(p *Post) bool {
+		return p.ID == id && p.Username == username
+	})
+	if len(posts) == 0 {
+		return nil
+	}
+
+	return posts[0]
+}
+
+// GetPosts gets every published post
+func (m *Memory) GetPosts() ([]*Post, error) {
+	return m.find(published), nil
+}
+
+// GetPostsPage gets a page of published posts, newest first
+func (m *Memory) GetPostsPage(after string, limit int64) ([]*Post, string, error) {
+	return m.page(published, after, limit)
+}
+
+// GetUserPostsPage is GetPostsPage for the posts of a single user
+func (m *Memory) GetUserPostsPage(username, after string, limit int64) ([]*Post, string, error) {
+	return m.page(func(p *Post) bool {
+		return p.Username == username && published(p)
+	}, after, limit)
+}
+
+// GetDrafts gets all the unpublished posts of a user
+func (m *Memory) GetDrafts(username string) []*Post {
+	return m.find(func(p *Post) bool {
+		return p.Username == username && p.Publish == 0
+	})
+}
+
+// BatchGetPosts gets the posts of the given keys, skipping the ones that
+// don't exist
+func (m *Memory) BatchGetPosts(keys []*Key) []*Post {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	posts := []*Post{}
+	for _, k := range keys {
+		if p, ok := m.posts[*k]; ok {
+			posts = append(posts, copyPost(p))
+		}
+	}
+
+	return posts
+}
+
+// UpdatePost sets the given attributes on an existing post. ErrForbidden is
+// returned if there's no such post of the given username.
+func (m *Memory) UpdatePost(username, id string, created int64, params map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.posts[Key{id, created}]
+	if !ok || p.Username != username {
+		return ErrForbidden
+	}
+
+	params["updated"] = time.Now().Unix()
+
+	updated := copyPost(p)
+	if err := apply(updated, params); err != nil {
+		return err
+	}
+	// the key can't change
+	updated.ID, updated.Created = id, created
+
+	m.posts[Key{id, created}] = updated
+	return nil
+}
+
+// DeletePost removes a post. ErrForbidden is returned if there's no such
+// post of the given username.
+func (m *Memory) DeletePost(username, id string, created int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.posts[Key{id, created}]
+	if !ok || p.Username != username {
+		return ErrForbidden
+	}
+
+	delete(m.posts, Key{id, created})
+	return nil
+}
+
+// AddLikes adds delta to the like counter of the post with the given id
+func (m *Memory) AddLikes(id string, delta int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, p := range m.posts {
+		if k.ID == id {
+			p.LikesCount += delta
+			return nil
+		}
+	}
+
+	return errors.New("AddLikes/NotFound")
+}
+
+// AddComments adds delta to the comment counter of the post with the given id
+func (m *Memory) AddComments(id string, delta int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for k, p := range m.posts {
+		if k.ID == id {
+			p.CommentsCount += delta
+			return nil
+		}
+	}
+
+	return errors.New("AddComments/NotFound")
+}
+
+// find gets the matching posts, newest first
+func (m *Memory) find(match func(p *Post) bool) []*Post {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var posts []*Post
+	for _, p := range m.posts {
+		if match(p) {
+			posts = append(posts, copyPost(p))
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return newer(posts[i], posts[j])
+	})
+
+	return posts
+}
+
+func (m *Memory) page(match func(p *Post) bool, after string, limit int64) ([]*Post, string, error) {
+	start, err := decodeCursor(after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	posts := []*Post{}
+	for _, p := range m.find(match) {
+		if start != nil && !newer(start, p) {
+			continue
+		}
+
+		if int64(len(posts)) == limit {
+			return posts, encodeCursor(posts[len(posts)-1]), nil
+		}
+		posts = append(posts, p)
+	}
+
+	return posts, "", nil
+}
+
+func published(p *Post) bool {
+	return p.Publish == 1
+}
+
+// copyPost keeps callers from changing what's stored
+func copyPost(p *Post) *Post {
+	cp := *p
+	cp.Tags = append([]string(nil), p.Tags...)
+	return &cp
+}
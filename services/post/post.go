@@ -1,18 +1,23 @@
 package post
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
-// Client ...
+// ErrForbidden is returned when a user tries to change a post they don't own
+var ErrForbidden = errors.New("Forbidden")
+
+// Client keeps posts on Dynamo
 type Client struct {
 	*dynamo.Client
 }
@@ -44,32 +49,84 @@ func (c *Client) GetCount() int64 {
 	return *resp.Table.ItemCount
 }
 
-// UpdatePost ...
+// UpdatePost sets the given attributes on an existing post. The `updated`
+// timestamp is refreshed on every call. ErrForbidden is returned if the post
+// doesn't belong to the given username.
 func (c *Client) UpdatePost(
-	id,
-	cover,
-	content string,
+	username,
+	id string,
 	created int64,
+	params map[string]interface{},
 ) error {
 	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
 		"id":      id,
 		"created": created,
 	})
-	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
-		":cover":   cover,
-		":content": content,
-	})
+
+	params["updated"] = time.Now().Unix()
+
+	// sort attributes so we get the same expression for the same input
+	attrs := []string{}
+	for k := range params {
+		attrs = append(attrs, k)
+	}
+	sort.Strings(attrs)
+
+	sets := []string{}
+	names := map[string]*string{}
+	values := map[string]interface{}{
+		":owner": username,
+	}
+	for _, k := range attrs {
+		k := k
+		sets = append(sets, fmt.Sprintf("#%s = :%s", k, k))
+		names["#"+k] = &k
+		values[":"+k] = params[k]
+	}
+	vals, _ := dynamodbattribute.MarshalMap(values)
 
 	input := &dynamodb.UpdateItemInput{}
 	input.SetKey(key)
 	input.SetTableName(c.TableName)
-	input.SetUpdateExpression("SET content = :content, cover = :cover")
+	input.SetUpdateExpression("SET " + strings.Join(sets, ", "))
+	input.SetConditionExpression("username = :owner")
+	input.SetExpressionAttributeNames(names)
 	input.SetExpressionAttributeValues(vals)
 
 	_, err := c.Provider.UpdateItem(input)
 	if err != nil {
 		log.Println("UpdateItem error:", err.Error())
-		return err
+		return ownerError(err)
+	}
+
+	return nil
+}
+
+// DeletePost removes a post. ErrForbidden is returned if the post doesn't
+// belong to the given username.
+func (c *Client) DeletePost(
+	username,
+	id string,
+	created int64,
+) error {
+	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id":      id,
+		"created": created,
+	})
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":owner": username,
+	})
+
+	input := &dynamodb.DeleteItemInput{}
+	input.SetKey(key)
+	input.SetTableName(c.TableName)
+	input.SetConditionExpression("username = :owner")
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := c.Provider.DeleteItem(input)
+	if err != nil {
+		log.Println("DeleteItem error:", err.Error())
+		return ownerError(err)
 	}
 
 	return nil
@@ -77,21 +134,7 @@ func (c *Client) UpdatePost(
 
 // CreatePost creates a new post
 func (c *Client) CreatePost(params map[string]interface{}) *Post {
-	// dates
-	now := time.Now().Unix()
-	params["created"] = now
-	params["updated"] = now
-
-	// parse title to create slug for id
-	title := params["title"].(string)
-	// remove extra spaces between
-	slug := regexp.MustCompile("[^a-zA-Z0-9 ]").ReplaceAllString(title, "")
-	// remove outer spaces
-	slug = strings.Trim(regexp.MustCompile(`\s+`).ReplaceAllString(slug, " "), " ")
-	slug = strings.Replace(slug, " ", "-", -1)
-	slug = strings.ToLower(slug)
-	// combine
-	params["id"] = fmt.Sprintf("%s-%d", slug, now)
+	params = newPost(params)
 
 	item, _ := dynamodbattribute.MarshalMap(params)
 
@@ -123,7 +166,7 @@ func (c *Client) GetPost(username, id string) *Post {
 
 	// we set index name to blank since we're not querying
 	// global secondary index
-	out, err := c.Query("", ks, fs, vals, false, 0)
+	out, err := c.Query("", ks, fs, vals, false, 0, nil)
 	if err != nil {
 		log.Println("Query error:", err.Error())
 		return nil
@@ -150,7 +193,7 @@ func (c *Client) GetUserPosts(username string) []*Post {
 		":username": username,
 	}
 
-	out, err := c.Query("publish_index", ks, fs, vals, false, 0)
+	out, err := c.Query("publish_index", ks, fs, vals, false, 0, nil)
 	if err != nil || len(out.Items) == 0 {
 		return nil
 	}
@@ -160,15 +203,77 @@ func (c *Client) GetUserPosts(username string) []*Post {
 	return posts
 }
 
-// GetPosts ...
-func (c *Client) GetPosts() []*Post {
+// GetPosts gets every published post, following Dynamo's result pages
+// until there are no more. A failing page fails the whole listing.
+func (c *Client) GetPosts() ([]*Post, error) {
+	ks := "publish = :publish and created > :created"
+	vals := map[string]interface{}{
+		":publish": 1,
+		":created": 0,
+	}
+
+	var posts []*Post
+	var start map[string]*dynamodb.AttributeValue
+	for {
+		out, err := c.Query("publish_index", ks, "", vals, false, 0, start)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []*Post
+		_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &batch)
+		posts = append(posts, batch...)
+
+		start = out.LastEvaluatedKey
+		if len(start) == 0 {
+			break
+		}
+	}
+
+	return posts, nil
+}
+
+// GetPostsPage gets a page of published posts, newest first. Pass the
+// returned cursor as `after` to get the next page; the cursor is empty
+// once there are no more posts.
+func (c *Client) GetPostsPage(after string, limit int64) ([]*Post, string, error) {
 	ks := "publish = :publish and created > :created"
 	vals := map[string]interface{}{
 		":publish": 1,
 		":created": 0,
 	}
 
-	out, err := c.Query("publish_index", ks, "", vals, false, 0)
+	return c.page("publish_index", ks, "", vals, after, limit)
+}
+
+// GetUserPostsPage is GetPostsPage for the posts of a single user
+func (c *Client) GetUserPostsPage(
+	username,
+	after string,
+	limit int64,
+) ([]*Post, string, error) {
+	ks := "username = :username and created > :created"
+	fs := "publish = :publish"
+	vals := map[string]interface{}{
+		":publish":  1,
+		":created":  0,
+		":username": username,
+	}
+
+	return c.page("username_index", ks, fs, vals, after, limit)
+}
+
+// GetDrafts gets all the unpublished posts of a user
+func (c *Client) GetDrafts(username string) []*Post {
+	ks := "username = :username and created > :created"
+	fs := "publish = :publish"
+	vals := map[string]interface{}{
+		":publish":  0,
+		":created":  0,
+		":username": username,
+	}
+
+	out, err := c.Query("username_index", ks, fs, vals, false, 0, nil)
 	if err != nil || len(out.Items) == 0 {
 		return nil
 	}
@@ -177,3 +282,96 @@ func (c *Client) GetPosts() []*Post {
 	_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &posts)
 	return posts
 }
+
+// BatchGetPosts gets the posts of the given keys. Keys that no longer
+// exist are skipped and the order of the result is not guaranteed.
+func (c *Client) BatchGetPosts(keys []*Key) []*Post {
+	posts := []*Post{}
+
+	// BatchGetItem takes at most 100 keys per request
+	for i := 0; i < len(keys); i += 100 {
+		end := i + 100
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var items []map[string]*dynamodb.AttributeValue
+		for _, k := range keys[i:end] {
+			item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+				"id":      k.ID,
+				"created": k.Created,
+			})
+			items = append(items, item)
+		}
+
+		request := map[string]*dynamodb.KeysAndAttributes{
+			c.TableName: {Keys: items},
+		}
+
+		for len(request) > 0 {
+			input := &dynamodb.BatchGetItemInput{}
+			input.SetRequestItems(request)
+
+			out, err := c.Provider.BatchGetItem(input)
+			if err != nil {
+				log.Println("BatchGetItem error:", err.Error())
+				return posts
+			}
+
+			var batch []*Post
+			_ = dynamodbattribute.UnmarshalListOfMaps(out.Responses[c.TableName], &batch)
+			posts = append(posts, batch...)
+
+			request = out.UnprocessedKeys
+		}
+	}
+
+	return posts
+}
+
+// page queries up to `limit` posts starting after the given cursor. Dynamo
+// applies the limit before filtering so a filtered query can come back
+// short; we keep querying from where it stopped until the page is full.
+func (c *Client) page(
+	in,
+	ks,
+	fs string,
+	vals map[string]interface{},
+	after string,
+	limit int64,
+) ([]*Post, string, error) {
+	start, err := dynamo.DecodeCursor(after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	posts := []*Post{}
+	for {
+		out, err := c.Query(in, ks, fs, vals, false, limit-int64(len(posts)), start)
+		if err != nil {
+			log.Println("Query error:", err.Error())
+			return nil, "", err
+		}
+
+		var batch []*Post
+		_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &batch)
+		posts = append(posts, batch...)
+
+		start = out.LastEvaluatedKey
+		if len(start) == 0 || int64(len(posts)) >= limit {
+			break
+		}
+	}
+
+	return posts, dynamo.EncodeCursor(start), nil
+}
+
+// ownerError translates failed ownership conditions to ErrForbidden
+func ownerError(err error) error {
+	if aerr, ok := err.(awserr.Error); ok &&
+		aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return ErrForbidden
+	}
+
+	return err
+}
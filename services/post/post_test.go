@@ -4,7 +4,9 @@ import (
 	"errors"
 	"testing"
 
+	"bishack.dev/services/dynamo"
 	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/stretchr/testify/assert"
@@ -33,7 +35,9 @@ func TestUpdatePost(t *testing.T) {
 			return true
 		})).Return(nil, errors.New(""))
 
-		err := c.UpdatePost("test", "test", "test", int64(42))
+		err := c.UpdatePost("test", "test", int64(42), map[string]interface{}{
+			"content": "test",
+		})
 		assert.NotNil(t, err)
 	})
 
@@ -42,11 +46,75 @@ func TestUpdatePost(t *testing.T) {
 		c := New("beep", "boop", provider)
 
 		provider.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
-			return true
+			return *input.UpdateExpression == "SET #content = :content, #cover = :cover, #publish = :publish, #updated = :updated" &&
+				*input.ConditionExpression == "username = :owner" &&
+				*input.ExpressionAttributeValues[":owner"].S == "test" &&
+				*input.ExpressionAttributeNames["#publish"] == "publish" &&
+				*input.ExpressionAttributeValues[":publish"].N == "0"
 		})).Return(&dynamodb.UpdateItemOutput{}, nil)
 
-		err := c.UpdatePost("test", "test", "test", int64(42))
+		err := c.UpdatePost("test", "test", int64(42), map[string]interface{}{
+			"cover":   "test",
+			"content": "test",
+			"publish": 0,
+		})
 		assert.Nil(t, err)
+		provider.AssertExpectations(t)
+	})
+}
+
+func TestUpdatePostForbidden(t *testing.T) {
+	provider := new(test.DynamoProviderMock)
+	c := New("beep", "boop", provider)
+
+	provider.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return true
+	})).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+	err := c.UpdatePost("intruder", "test", int64(42), map[string]interface{}{
+		"content": "pwned",
+	})
+	assert.Equal(t, ErrForbidden, err)
+}
+
+func TestDeletePost(t *testing.T) {
+	t.Run("forbidden", func(t *testing.T) {
+		provider := new(test.DynamoProviderMock)
+		c := New("beep", "boop", provider)
+
+		provider.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+		err := c.DeletePost("intruder", "test", int64(42))
+		assert.Equal(t, ErrForbidden, err)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		provider := new(test.DynamoProviderMock)
+		c := New("beep", "boop", provider)
+
+		provider.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, errors.New("boop"))
+
+		err := c.DeletePost("test", "test", int64(42))
+		assert.NotNil(t, err)
+		assert.NotEqual(t, ErrForbidden, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		provider := new(test.DynamoProviderMock)
+		c := New("beep", "boop", provider)
+
+		provider.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return *input.ConditionExpression == "username = :owner" &&
+				*input.ExpressionAttributeValues[":owner"].S == "test"
+		})).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+		err := c.DeletePost("test", "test", int64(42))
+		assert.Nil(t, err)
+		provider.AssertExpectations(t)
 	})
 }
 
@@ -140,7 +208,7 @@ func TestQuery(t *testing.T) {
 			return true
 		})).Return(nil, errors.New(""))
 
-		_, err := c.Query("x", "", "", map[string]interface{}{}, false, 0)
+		_, err := c.Query("x", "", "", map[string]interface{}{}, false, 0, nil)
 		assert.NotNil(t, err)
 	})
 
@@ -154,7 +222,7 @@ func TestQuery(t *testing.T) {
 			return true
 		})).Return(out, nil)
 
-		out, err := c.Query("x", "", "", map[string]interface{}{}, false, 0)
+		out, err := c.Query("x", "", "", map[string]interface{}{}, false, 0, nil)
 		assert.Nil(t, err)
 		assert.Empty(t, out.Items)
 	})
@@ -176,7 +244,7 @@ func TestQuery(t *testing.T) {
 			return true
 		})).Return(out, nil)
 
-		out, err := c.Query("x", "", "", map[string]interface{}{}, false, 0)
+		out, err := c.Query("x", "", "", map[string]interface{}{}, false, 0, nil)
 		assert.Nil(t, err)
 		assert.NotNil(t, out)
 	})
@@ -191,7 +259,21 @@ func TestGetAll(t *testing.T) {
 			return true
 		})).Return(&dynamodb.QueryOutput{}, nil)
 
-		posts := c.GetPosts()
+		posts, err := c.GetPosts()
+		assert.Nil(t, err)
+		assert.Nil(t, posts)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := new(test.DynamoProviderMock)
+		c := New("bee", "boop", p)
+
+		p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(nil, errors.New("boom"))
+
+		posts, err := c.GetPosts()
+		assert.NotNil(t, err)
 		assert.Nil(t, posts)
 	})
 
@@ -212,7 +294,8 @@ func TestGetAll(t *testing.T) {
 			return true
 		})).Return(out, nil)
 
-		posts := c.GetPosts()
+		posts, err := c.GetPosts()
+		assert.Nil(t, err)
 		assert.NotNil(t, posts)
 	})
 }
@@ -301,3 +384,172 @@ func TestGetUserPosts(t *testing.T) {
 		assert.Equal(t, "test", posts[0].Username)
 	})
 }
+
+func TestGetDrafts(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		p := new(test.DynamoProviderMock)
+		c := New("bee", "boop", p)
+
+		p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(nil, errors.New(""))
+
+		posts := c.GetDrafts("test")
+		assert.Nil(t, posts)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(test.DynamoProviderMock)
+		c := New("bee", "boop", p)
+
+		out := &dynamodb.QueryOutput{}
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"title":    "test",
+			"id":       "testing",
+			"username": "test",
+			"publish":  0,
+		})
+		out.SetItems([]map[string]*dynamodb.AttributeValue{
+			item,
+		})
+		p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.IndexName == "username_index" &&
+				*input.ExpressionAttributeValues[":publish"].N == "0"
+		})).Return(out, nil)
+
+		posts := c.GetDrafts("test")
+		assert.Equal(t, 1, len(posts))
+		assert.Equal(t, "testing", posts[0].ID)
+		assert.Equal(t, 0, posts[0].Publish)
+	})
+}
+
+func TestBatchGetPosts(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		provider := new(test.DynamoProviderMock)
+		c := New("beep", "boop", provider)
+
+		provider.On("BatchGetItem", mock.MatchedBy(func(input *dynamodb.BatchGetItemInput) bool {
+			return true
+		})).Return(nil, errors.New(""))
+
+		posts := c.BatchGetPosts([]*Key{{ID: "test", Created: 42}})
+		assert.Equal(t, 0, len(posts))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		provider := new(test.DynamoProviderMock)
+		c := New("beep", "boop", provider)
+
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "test",
+			"created": 42,
+			"tags":    []string{"go"},
+		})
+		out := &dynamodb.BatchGetItemOutput{}
+		out.SetResponses(map[string][]map[string]*dynamodb.AttributeValue{
+			"beep": {item},
+		})
+
+		provider.On("BatchGetItem", mock.MatchedBy(func(input *dynamodb.BatchGetItemInput) bool {
+			keys := input.RequestItems["beep"].Keys
+			return len(keys) == 1 && *keys[0]["id"].S == "test" && *keys[0]["created"].N == "42"
+		})).Return(out, nil)
+
+		posts := c.BatchGetPosts([]*Key{{ID: "test", Created: 42}})
+		assert.Equal(t, 1, len(posts))
+		assert.Equal(t, []string{"go"}, posts[0].Tags)
+	})
+}
+
+func TestGetPostsPage(t *testing.T) {
+	t.Run("invalid cursor", func(t *testing.T) {
+		p := new(test.DynamoProviderMock)
+		c := New("bee", "boop", p)
+
+		posts, next, err := c.GetPostsPage("!!!", 10)
+		assert.Nil(t, posts)
+		assert.Empty(t, next)
+		assert.Equal(t, dynamo.ErrInvalidCursor, err)
+		p.AssertNotCalled(t, "Query", mock.Anything)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := new(test.DynamoProviderMock)
+		c := New("bee", "boop", p)
+
+		p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(nil, errors.New(""))
+
+		_, _, err := c.GetPostsPage("", 10)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		p := new(test.DynamoProviderMock)
+		c := New("bee", "boop", p)
+
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "testing",
+			"created": 42,
+		})
+		key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":      "testing",
+			"created": 42,
+			"publish": 1,
+		})
+		out := &dynamodb.QueryOutput{}
+		out.SetItems([]map[string]*dynamodb.AttributeValue{item})
+		out.SetLastEvaluatedKey(key)
+
+		p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.IndexName == "publish_index" &&
+				*input.Limit == 1 &&
+				input.ExclusiveStartKey == nil
+		})).Return(out, nil)
+
+		posts, next, err := c.GetPostsPage("", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(posts))
+		assert.Equal(t, dynamo.EncodeCursor(key), next)
+	})
+}
+
+func TestGetUserPostsPage(t *testing.T) {
+	p := new(test.DynamoProviderMock)
+	c := New("bee", "boop", p)
+
+	key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id":       "first",
+		"created":  42,
+		"username": "test",
+	})
+	item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id":      "second",
+		"created": 41,
+	})
+
+	// the first query is all drafts so the filter leaves nothing
+	first := &dynamodb.QueryOutput{}
+	first.SetItems([]map[string]*dynamodb.AttributeValue{})
+	first.SetLastEvaluatedKey(key)
+	second := &dynamodb.QueryOutput{}
+	second.SetItems([]map[string]*dynamodb.AttributeValue{item})
+
+	p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.IndexName == "username_index" &&
+			*input.FilterExpression == "publish = :publish" &&
+			input.ExclusiveStartKey == nil
+	})).Return(first, nil).Once()
+	p.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey != nil && *input.ExclusiveStartKey["id"].S == "first"
+	})).Return(second, nil).Once()
+
+	posts, next, err := c.GetUserPostsPage("test", "", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(posts))
+	assert.Equal(t, "second", posts[0].ID)
+	assert.Empty(t, next)
+	p.AssertExpectations(t)
+}
@@ -0,0 +1,107 @@
+package post
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// ErrInvalidCursor is returned when a page cursor can't be decoded,
+// whichever the backend
+var ErrInvalidCursor = dynamo.ErrInvalidCursor
+
+// Repository stores posts. Client keeps them on Dynamo, Memory and SQLite
+// let the site run without it.
+type Repository interface {
+	CreatePost(params map[string]interface{}) *Post
+	GetPost(username, id string) *Post
+	GetPosts() ([]*Post, error)
+	GetPostsPage(after string, limit int64) ([]*Post, string, error)
+	GetUserPostsPage(username, after string, limit int64) ([]*Post, string, error)
+	GetDrafts(username string) []*Post
+	BatchGetPosts(keys []*Key) []*Post
+	UpdatePost(username, id string, created int64, params map[string]interface{}) error
+	DeletePost(username, id string, created int64) error
+}
+
+var (
+	rxSlugStrip = regexp.MustCompile("[^a-zA-Z0-9 ]")
+	rxSpaces    = regexp.MustCompile(`\s+`)
+)
+
+// newPost fills in what CreatePost sets on every new post: its dates, a
+// zero like and comment counters and an id made of the title and creation time
+func newPost(params map[string]interface{}) map[string]interface{} {
+	now := time.Now().Unix()
+	params["created"] = now
+	params["updated"] = now
+	params["likes"] = 0
+	params["commentsCount"] = 0
+
+	// parse title to create slug for id
+	title, _ := params["title"].(string)
+	// remove extra spaces between
+	slug := rxSlugStrip.ReplaceAllString(title, "")
+	// remove outer spaces
+	slug = strings.Trim(rxSpaces.ReplaceAllString(slug, " "), " ")
+	slug = strings.Replace(slug, " ", "-", -1)
+	slug = strings.ToLower(slug)
+	// combine
+	params["id"] = fmt.Sprintf("%s-%d", slug, now)
+
+	return params
+}
+
+// apply sets post attributes, named as they are on Dynamo, on p
+func apply(p *Post, params map[string]interface{}) error {
+	item, err := dynamodbattribute.MarshalMap(params)
+	if err != nil {
+		return err
+	}
+
+	return dynamodbattribute.UnmarshalMap(item, p)
+}
+
+// newer tells if a comes before b, newest first
+func newer(a, b *Post) bool {
+	if a.Created != b.Created {
+		return a.Created > b.Created
+	}
+
+	return a.ID > b.ID
+}
+
+// encodeCursor points right after the given post, for backends that page
+// through posts newest first
+func encodeCursor(p *Post) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", p.Created, p.ID)))
+}
+
+func decodeCursor(cursor string) (*Post, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	chunks := strings.SplitN(string(b), ":", 2)
+	if len(chunks) != 2 || chunks[1] == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	created, err := strconv.ParseInt(chunks[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Post{ID: chunks[1], Created: created}, nil
+}
@@ -0,0 +1,156 @@
+package post
+
+import (
+	"testing"
+
+	"bishack.dev/services/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	testRepository(t, func() Repository {
+		return NewMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	testRepository(t, func() Repository {
+		db, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewSQLite(db)
+	})
+}
+
+// testRepository checks the behaviour every backend shares
+func testRepository(t *testing.T, newRepo func() Repository) {
+	create := func(r Repository, username, title string, publish int) *Post {
+		return r.CreatePost(map[string]interface{}{
+			"title":    title,
+			"content":  "content of " + title,
+			"username": username,
+			"publish":  publish,
+			"tags":     []string{"go"},
+		})
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		r := newRepo()
+
+		p := create(r, "ing", "Hello, World!", 1)
+		assert.Regexp(t, "^hello-world-[0-9]+$", p.ID)
+		assert.NotZero(t, p.Created)
+		assert.Equal(t, p.Created, p.Updated)
+		assert.Equal(t, int64(0), p.LikesCount)
+
+		got := r.GetPost("ing", p.ID)
+		assert.Equal(t, p, got)
+		assert.Equal(t, []string{"go"}, got.Tags)
+
+		assert.Nil(t, r.GetPost("someone", p.ID))
+		assert.Nil(t, r.GetPost("ing", "nope"))
+	})
+
+	t.Run("published and drafts", func(t *testing.T) {
+		r := newRepo()
+
+		published := create(r, "ing", "published", 1)
+		draft := create(r, "ing", "draft", 0)
+		create(r, "other", "other draft", 0)
+
+		posts, err := r.GetPosts()
+		assert.Nil(t, err)
+		assert.Len(t, posts, 1)
+		assert.Equal(t, published.ID, posts[0].ID)
+
+		drafts := r.GetDrafts("ing")
+		assert.Len(t, drafts, 1)
+		assert.Equal(t, draft.ID, drafts[0].ID)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		r := newRepo()
+
+		a := create(r, "ing", "a", 1)
+		b := create(r, "ing", "b", 1)
+		c := create(r, "other", "c", 1)
+		create(r, "ing", "d", 0)
+
+		posts, cursor, err := r.GetPostsPage("", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{c.ID, b.ID}, ids(posts))
+		assert.NotEmpty(t, cursor)
+
+		posts, cursor, err = r.GetPostsPage(cursor, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{a.ID}, ids(posts))
+		assert.Empty(t, cursor)
+
+		posts, cursor, err = r.GetUserPostsPage("ing", "", 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{b.ID, a.ID}, ids(posts))
+		assert.Empty(t, cursor)
+
+		_, _, err = r.GetPostsPage("nope", 2)
+		assert.Equal(t, ErrInvalidCursor, err)
+	})
+
+	t.Run("batch get", func(t *testing.T) {
+		r := newRepo()
+
+		a := create(r, "ing", "a", 1)
+
+		posts := r.BatchGetPosts([]*Key{
+			{ID: a.ID, Created: a.Created},
+			{ID: "gone", Created: 1},
+		})
+		assert.Equal(t, []string{a.ID}, ids(posts))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		r := newRepo()
+
+		p := create(r, "ing", "a", 0)
+
+		err := r.UpdatePost("intruder", p.ID, p.Created, map[string]interface{}{"content": "x"})
+		assert.Equal(t, ErrForbidden, err)
+
+		err = r.UpdatePost("ing", "nope", p.Created, map[string]interface{}{"content": "x"})
+		assert.Equal(t, ErrForbidden, err)
+
+		err = r.UpdatePost("ing", p.ID, p.Created, map[string]interface{}{
+			"content": "updated",
+			"publish": 1,
+		})
+		assert.Nil(t, err)
+
+		got := r.GetPost("ing", p.ID)
+		assert.Equal(t, "updated", got.Content)
+		assert.Equal(t, "a", got.Title)
+		assert.Equal(t, 1, got.Publish)
+		posts, _ := r.GetPosts()
+		assert.Len(t, posts, 1)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		r := newRepo()
+
+		p := create(r, "ing", "a", 1)
+
+		assert.Equal(t, ErrForbidden, r.DeletePost("intruder", p.ID, p.Created))
+		assert.Nil(t, r.DeletePost("ing", p.ID, p.Created))
+		assert.Nil(t, r.GetPost("ing", p.ID))
+		assert.Equal(t, ErrForbidden, r.DeletePost("ing", p.ID, p.Created))
+	})
+}
+
+func ids(posts []*Post) []string {
+	ids := []string{}
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,234 @@
+package post
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// columns of the posts table, in the order scan reads them
+const columns = "id, created, updated, username, author, userPic, title, cover, content, publish, readingTime, likes, commentsCount, tags"
+
+// SQLite keeps posts on an embedded database, see services/sqlite
+type SQLite struct {
+	DB *sql.DB
+}
+
+// NewSQLite ...
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db}
+}
+
+// CreatePost creates a new post
+func (s *SQLite) CreatePost(params map[string]interface{}) *Post {
+	p := &Post{}
+	if err := apply(p, newPost(params)); err != nil {
+		log.Println("CreatePost error:", err.Error())
+		return nil
+	}
+
+	if err := s.put(s.DB, "INSERT", p); err != nil {
+		log.Println("CreatePost/Exec error:", err.Error())
+		return nil
+	}
+
+	return p
+}
+
+// GetPost ...
+func (s *SQLite) GetPost(username, id string) *Post {
+	posts, err := s.query("WHERE id = ? AND username = ? ORDER BY created DESC, id DESC", id, username)
+	if err != nil {
+		log.Println("GetPost error:", err.Error())
+		return nil
+	}
+
+	if len(posts) == 0 {
+		return nil
+	}
+
+	return posts[0]
+}
+
+// GetPosts gets every published post
+func (s *SQLite) GetPosts() ([]*Post, error) {
+	posts, err := s.query("WHERE publish = 1 ORDER BY created DESC, id DESC")
+	if err != nil {
+		return nil, errors.Wrap(err, "GetPosts")
+	}
+
+	return posts, nil
+}
+
+// GetPostsPage gets a page of published posts, newest first
+func (s *SQLite) GetPostsPage(after string, limit int64) ([]*Post, string, error) {
+	return s.page("publish = 1", nil, after, limit)
+}
+
+// GetUserPostsPage is GetPostsPage for the posts of a single user
+func (s *SQLite) GetUserPostsPage(username, after string, limit int64) ([]*Post, string, error) {
+	return s.page("publish = 1 AND username = ?", []interface{}{username}, after, limit)
+}
+
+// GetDrafts gets all the unpublished posts of a user
+func (s *SQLite) GetDrafts(username string) []*Post {
+	posts, err := s.query("WHERE publish = 0 AND username = ? ORDER BY created DESC, id DESC", username)
+	if err != nil {
+		log.Println("GetDrafts error:", err.Error())
+		return nil
+	}
+
+	return posts
+}
+
+// BatchGetPosts gets the posts of the given keys, skipping the ones that
+// don't exist
+func (s *SQLite) BatchGetPosts(keys []*Key) []*Post {
+	posts := []*Post{}
+	for _, k := range keys {
+		found, err := s.query("WHERE id = ? AND created = ?", k.ID, k.Created)
+		if err != nil {
+			log.Println("BatchGetPosts error:", err.Error())
+			return posts
+		}
+		posts = append(posts, found...)
+	}
+
+	return posts
+}
+
+// UpdatePost sets the given attributes on an existing post. ErrForbidden is
+// returned if there's no such post of the given username.
+func (s *SQLite) UpdatePost(username, id string, created int64, params map[string]interface{}) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return errors.Wrap(err, "UpdatePost/Begin error")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	p, err := scan(tx.QueryRow("SELECT "+columns+" FROM posts WHERE id = ? AND created = ?", id, created))
+	if err == sql.ErrNoRows {
+		return ErrForbidden
+	}
+	if err != nil {
+		return errors.Wrap(err, "UpdatePost/QueryRow error")
+	}
+
+	if p.Username != username {
+		return ErrForbidden
+	}
+
+	params["updated"] = time.Now().Unix()
+	if err := apply(p, params); err != nil {
+		return err
+	}
+	// the key can't change
+	p.ID, p.Created = id, created
+
+	if err := s.put(tx, "REPLACE", p); err != nil {
+		return errors.Wrap(err, "UpdatePost/Exec error")
+	}
+
+	return tx.Commit()
+}
+
+// DeletePost removes a post. ErrForbidden is returned if there's no such
+// post of the given username.
+func (s *SQLite) DeletePost(username, id string, created int64) error {
+	res, err := s.DB.Exec("DELETE FROM posts WHERE id = ? AND created = ? AND username = ?", id, created, username)
+	if err != nil {
+		return errors.Wrap(err, "DeletePost/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+func (s *SQLite) page(where string, args []interface{}, after string, limit int64) ([]*Post, string, error) {
+	start, err := decodeCursor(after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if start != nil {
+		where += " AND (created < ? OR (created = ? AND id < ?))"
+		args = append(args, start.Created, start.Created, start.ID)
+	}
+
+	// one more than asked tells if there's a next page
+	args = append(args, limit+1)
+	posts, err := s.query("WHERE "+where+" ORDER BY created DESC, id DESC LIMIT ?", args...)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "page")
+	}
+
+	if int64(len(posts)) <= limit {
+		return posts, "", nil
+	}
+
+	posts = posts[:limit]
+	return posts, encodeCursor(posts[len(posts)-1]), nil
+}
+
+func (s *SQLite) query(clause string, args ...interface{}) ([]*Post, error) {
+	rows, err := s.DB.Query("SELECT "+columns+" FROM posts "+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*Post{}
+	for rows.Next() {
+		p, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+
+	return posts, rows.Err()
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// put writes every column of p, verb being INSERT or REPLACE
+func (s *SQLite) put(db execer, verb string, p *Post) error {
+	tags, _ := json.Marshal(p.Tags)
+
+	_, err := db.Exec(
+		verb+" INTO posts ("+columns+") VALUES (?"+strings.Repeat(", ?", 13)+")",
+		p.ID, p.Created, p.Updated, p.Username, p.Author, p.UserPic, p.Title, p.Cover,
+		p.Content, p.Publish, p.ReadingTime, p.LikesCount, p.CommentsCount, string(tags),
+	)
+
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner) (*Post, error) {
+	p := &Post{}
+
+	var tags string
+	err := row.Scan(
+		&p.ID, &p.Created, &p.Updated, &p.Username, &p.Author, &p.UserPic, &p.Title, &p.Cover,
+		&p.Content, &p.Publish, &p.ReadingTime, &p.LikesCount, &p.CommentsCount, &tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal([]byte(tags), &p.Tags)
+	return p, nil
+}
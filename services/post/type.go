@@ -13,6 +13,13 @@ type Post struct {
 	UserPic       string
 	Content       string
 	ReadingTime   int
-	LikesCount    int64
-	CommentsCount int64
+	LikesCount    int64 `dynamodbav:"likes"`
+	CommentsCount int64 `dynamodbav:"commentsCount"`
+	Tags          []string
+}
+
+// Key identifies a single post on the table
+type Key struct {
+	ID      string
+	Created int64
 }
@@ -0,0 +1,313 @@
+package search
+
+import (
+	"html/template"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"bishack.dev/services/post"
+)
+
+const (
+	// titleBoost is how much more a term in the title or tags counts
+	titleBoost = 3.0
+
+	// snippetLength is the number of characters around the first hit
+	snippetLength = 160
+)
+
+var (
+	// markdown syntax we don't want to show up on snippets
+	rxCode   = regexp.MustCompile("(?s)```.*?```")
+	rxImage  = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	rxLink   = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	rxSyntax = regexp.MustCompile("[#*_`>~|]+")
+	rxSpace  = regexp.MustCompile(`\s+`)
+)
+
+// Memory is an in-process inverted index. It's safe for concurrent use.
+type Memory struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]float64
+
+	// touched are the posts added or removed before the index is loaded,
+	// nil once it is
+	touched map[string]bool
+
+	// loading keeps concurrent loads from reading the posts twice
+	loading sync.Mutex
+}
+
+type document struct {
+	post  *post.Post
+	text  string
+	terms map[string]float64
+}
+
+// NewMemory creates an empty in-memory index
+func NewMemory() *Memory {
+	return &Memory{
+		docs:     map[string]*document{},
+		postings: map[string]map[string]float64{},
+		touched:  map[string]bool{},
+	}
+}
+
+// Add ...
+func (m *Memory) Add(p *post.Post) {
+	doc := newDocument(p)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.touch(p.ID)
+	m.put(p.ID, doc)
+}
+
+// Remove ...
+func (m *Memory) Remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.touch(id)
+	m.remove(id)
+}
+
+// Load ...
+func (m *Memory) Load(load func() ([]*post.Post, error)) error {
+	m.loading.Lock()
+	defer m.loading.Unlock()
+
+	if m.loaded() {
+		return nil
+	}
+
+	posts, err := load()
+	if err != nil {
+		return err
+	}
+
+	docs := make([]*document, len(posts))
+	for i, p := range posts {
+		docs[i] = newDocument(p)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, p := range posts {
+		if !m.touched[p.ID] {
+			m.put(p.ID, docs[i])
+		}
+	}
+	m.touched = nil
+
+	return nil
+}
+
+func (m *Memory) loaded() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.touched == nil
+}
+
+// newDocument analyzes a post, nil if it isn't published
+func newDocument(p *post.Post) *document {
+	if p.Publish != 1 {
+		return nil
+	}
+
+	text := plain(p.Content)
+	terms := map[string]float64{}
+	for _, t := range tokenize(p.Title + " " + strings.Join(p.Tags, " ")) {
+		terms[t] += titleBoost
+	}
+	for _, t := range tokenize(text) {
+		terms[t]++
+	}
+
+	return &document{post: p, text: text, terms: terms}
+}
+
+// put replaces the document of a post, removing it when doc is nil. The
+// caller holds the lock.
+func (m *Memory) put(id string, doc *document) {
+	m.remove(id)
+
+	if doc == nil {
+		return
+	}
+
+	m.docs[id] = doc
+	for t, w := range doc.terms {
+		if m.postings[t] == nil {
+			m.postings[t] = map[string]float64{}
+		}
+		m.postings[t][id] = w
+	}
+}
+
+// remove drops the document of a post. The caller holds the lock.
+func (m *Memory) remove(id string) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+
+	for t := range doc.terms {
+		delete(m.postings[t], id)
+		if len(m.postings[t]) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	delete(m.docs, id)
+}
+
+// touch remembers a post changed before the index was loaded. The caller
+// holds the lock.
+func (m *Memory) touch(id string) {
+	if m.touched != nil {
+		m.touched[id] = true
+	}
+}
+
+// Search ranks documents with tf-idf. Documents matching every term of the
+// query always rank above documents matching only some of them.
+func (m *Memory) Search(query string, limit int) []*Result {
+	terms := unique(tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scores := map[string]float64{}
+	matches := map[string]int{}
+	total := float64(len(m.docs))
+	for _, t := range terms {
+		docs := m.postings[t]
+		idf := math.Log(1 + total/float64(len(docs)+1))
+		for id, w := range docs {
+			scores[id] += (1 + math.Log(w)) * idf
+			matches[id]++
+		}
+	}
+
+	results := []*Result{}
+	for id, score := range scores {
+		doc := m.docs[id]
+		results = append(results, &Result{
+			Post:    doc.post,
+			Score:   score + float64(matches[id]),
+			Snippet: snippet(doc.text, terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Post.Created > results[j].Post.Created
+		}
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// tokenize splits text into lowercase words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func unique(terms []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// plain strips the most common markdown syntax from the content
+func plain(content string) string {
+	text := rxCode.ReplaceAllString(content, " ")
+	text = rxImage.ReplaceAllString(text, " ")
+	text = rxLink.ReplaceAllString(text, "$1")
+	text = rxSyntax.ReplaceAllString(text, " ")
+	return strings.TrimSpace(rxSpace.ReplaceAllString(text, " "))
+}
+
+// snippet cuts a window of text around the first matched term and wraps
+// every matched word in <mark>
+func snippet(text string, terms []string) template.HTML {
+	lower := strings.ToLower(text)
+
+	start := -1
+	for _, t := range terms {
+		i := strings.Index(lower, t)
+		if i != -1 && (start == -1 || i < start) {
+			start = i
+		}
+	}
+	if start == -1 {
+		start = 0
+	}
+
+	if start > len(text) {
+		start = 0
+	}
+
+	runes := []rune(text)
+	from := len([]rune(text[:start])) - snippetLength/4
+	if from < 0 {
+		from = 0
+	}
+	to := from + snippetLength
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	// escape around the matches so terms never match inside entities
+	rx := regexp.MustCompile(`(?i)\b(` + strings.Join(quote(terms), "|") + `)`)
+	raw := string(runes[from:to])
+	window := ""
+	last := 0
+	for _, loc := range rx.FindAllStringIndex(raw, -1) {
+		window += template.HTMLEscapeString(raw[last:loc[0]])
+		window += "<mark>" + template.HTMLEscapeString(raw[loc[0]:loc[1]]) + "</mark>"
+		last = loc[1]
+	}
+	window += template.HTMLEscapeString(raw[last:])
+
+	if from > 0 {
+		window = "…" + window
+	}
+	if to < len(runes) {
+		window += "…"
+	}
+
+	return template.HTML(window)
+}
+
+func quote(terms []string) []string {
+	out := make([]string, len(terms))
+	for i, t := range terms {
+		out[i] = regexp.QuoteMeta(t)
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"bishack.dev/services/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	assert.Empty(t, m.Search("go", 0))
+
+	m.Add(&post.Post{
+		ID:      "go-tutorial",
+		Title:   "A Go Tutorial",
+		Content: "# Intro\n\nLearn how to write **concurrent** programs.",
+		Publish: 1,
+		Created: 1,
+	})
+	m.Add(&post.Post{
+		ID:      "meetup",
+		Title:   "Meetup Notes",
+		Content: "We talked about Go & concurrency at the meetup.",
+		Publish: 1,
+		Created: 2,
+	})
+	m.Add(&post.Post{
+		ID:      "draft",
+		Title:   "Go Draft",
+		Publish: 0,
+	})
+
+	t.Run("title ranks first", func(t *testing.T) {
+		results := m.Search("go", 0)
+		assert.Equal(t, 2, len(results))
+		assert.Equal(t, "go-tutorial", results[0].Post.ID)
+		assert.Equal(t, "meetup", results[1].Post.ID)
+	})
+
+	t.Run("all terms rank first", func(t *testing.T) {
+		results := m.Search("concurrent tutorial", 0)
+		assert.Equal(t, "go-tutorial", results[0].Post.ID)
+	})
+
+	t.Run("snippets", func(t *testing.T) {
+		results := m.Search("meetup", 1)
+		assert.Equal(t, 1, len(results))
+		assert.Equal(
+			t,
+			"We talked about Go &amp; concurrency at the <mark>meetup</mark>.",
+			string(results[0].Snippet),
+		)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		assert.Empty(t, m.Search("rust", 0))
+		assert.Empty(t, m.Search("  ", 0))
+	})
+
+	t.Run("update and remove", func(t *testing.T) {
+		m.Add(&post.Post{ID: "meetup", Title: "Meetup Notes", Content: "Rust", Publish: 1})
+		assert.Equal(t, 1, len(m.Search("go", 0)))
+		assert.Equal(t, 1, len(m.Search("rust", 0)))
+
+		m.Add(&post.Post{ID: "meetup", Title: "Meetup Notes", Publish: 0})
+		assert.Empty(t, m.Search("rust", 0))
+
+		m.Remove("go-tutorial")
+		assert.Empty(t, m.Search("go", 0))
+	})
+}
+
+func TestMemoryLoad(t *testing.T) {
+	m := NewMemory()
+
+	// changed before the load, newer than what it reads
+	m.Add(&post.Post{ID: "edited", Title: "Edited Rust", Publish: 1})
+	m.Remove("deleted")
+
+	loads := 0
+	load := func() ([]*post.Post, error) {
+		loads++
+		return []*post.Post{
+			{ID: "edited", Title: "Stale Rust", Publish: 1},
+			{ID: "deleted", Title: "Deleted Rust", Publish: 1},
+			{ID: "other", Title: "Other Rust", Publish: 1},
+		}, nil
+	}
+
+	// a failed load leaves the index to be loaded again
+	err := m.Load(func() ([]*post.Post, error) {
+		return nil, errors.New("boom")
+	})
+	assert.NotNil(t, err)
+
+	assert.Nil(t, m.Load(load))
+	assert.Nil(t, m.Load(load))
+	assert.Equal(t, 1, loads)
+
+	results := m.Search("rust", 0)
+	assert.Equal(t, 2, len(results))
+
+	ids := []string{results[0].Post.ID, results[1].Post.ID}
+	assert.ElementsMatch(t, []string{"edited", "other"}, ids)
+	assert.Empty(t, m.Search("stale", 0))
+
+	// changes after the load apply as usual
+	m.Remove("edited")
+	assert.Equal(t, 1, len(m.Search("rust", 0)))
+}
@@ -0,0 +1,32 @@
+// Package search provides full-text search over published posts. The index
+// lives behind the Index interface so the in-process implementation can be
+// swapped for an external engine without touching the handlers.
+package search
+
+import (
+	"html/template"
+
+	"bishack.dev/services/post"
+)
+
+// Index ...
+type Index interface {
+	// Add indexes a post, replacing any previous version of it. Posts that
+	// aren't published are removed from the index instead.
+	Add(p *post.Post)
+	// Remove drops a post from the index
+	Remove(id string)
+	// Search returns the posts matching the query, best match first
+	Search(query string, limit int) []*Result
+	// Load indexes the posts load returns the first time it succeeds, later
+	// calls do nothing. Posts added or removed before then are kept as they
+	// are, they're newer than what load reads.
+	Load(load func() ([]*post.Post, error)) error
+}
+
+// Result is a single search hit
+type Result struct {
+	Post    *post.Post
+	Score   float64
+	Snippet template.HTML
+}
@@ -0,0 +1,97 @@
+// Package sqlite opens the embedded database posts, likes, comments, tags
+// and tokens are kept in when running without Dynamo
+package sqlite
+
+import (
+	"database/sql"
+
+	// registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// schema mirrors the Dynamo tables. Posts are keyed by (id, created), likes
+// by (id, username), comments by (post, id), tags by (tag, id) and tokens by
+// id, same as there.
+const schema = `
+CREATE TABLE IF NOT EXISTS posts (
+	id            TEXT NOT NULL,
+	created       INTEGER NOT NULL,
+	updated       INTEGER NOT NULL DEFAULT 0,
+	username      TEXT NOT NULL,
+	author        TEXT NOT NULL DEFAULT '',
+	userPic       TEXT NOT NULL DEFAULT '',
+	title         TEXT NOT NULL DEFAULT '',
+	cover         TEXT NOT NULL DEFAULT '',
+	content       TEXT NOT NULL DEFAULT '',
+	publish       INTEGER NOT NULL DEFAULT 0,
+	readingTime   INTEGER NOT NULL DEFAULT 0,
+	likes         INTEGER NOT NULL DEFAULT 0,
+	commentsCount INTEGER NOT NULL DEFAULT 0,
+	tags          TEXT NOT NULL DEFAULT '[]',
+	PRIMARY KEY (id, created)
+);
+CREATE INDEX IF NOT EXISTS posts_publish ON posts (publish, created);
+CREATE INDEX IF NOT EXISTS posts_username ON posts (username, created);
+
+CREATE TABLE IF NOT EXISTS likes (
+	id       TEXT NOT NULL,
+	username TEXT NOT NULL,
+	created  INTEGER NOT NULL,
+	PRIMARY KEY (id, username)
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+	post     TEXT NOT NULL,
+	id       TEXT NOT NULL,
+	parent   TEXT NOT NULL DEFAULT '',
+	author   TEXT NOT NULL DEFAULT '',
+	username TEXT NOT NULL,
+	userPic  TEXT NOT NULL DEFAULT '',
+	content  TEXT NOT NULL DEFAULT '',
+	hidden   INTEGER NOT NULL DEFAULT 0,
+	pinned   INTEGER NOT NULL DEFAULT 0,
+	created  INTEGER NOT NULL,
+	updated  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (post, id)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+	tag      TEXT NOT NULL,
+	id       TEXT NOT NULL,
+	created  INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	publish  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (tag, id)
+);
+
+CREATE TABLE IF NOT EXISTS tokens (
+	id       TEXT NOT NULL PRIMARY KEY,
+	username TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	prefix   TEXT NOT NULL,
+	scopes   TEXT NOT NULL DEFAULT '[]',
+	created  INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tokens_username ON tokens (username, created);
+`
+
+// Open opens the database at path, creating it and its tables if need be.
+// Use ":memory:" for a database that only lives as long as the process.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Open")
+	}
+
+	// sqlite takes one writer at a time, and an in memory database only
+	// lives on the connection that created it
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "Open/Exec error")
+	}
+
+	return db, nil
+}
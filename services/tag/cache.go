@@ -0,0 +1,84 @@
+package tag
+
+import (
+	"sync"
+	"time"
+)
+
+// CloudTTL is how long the tag cloud is kept. Tagging through the cache
+// drops it right away, tags written elsewhere show up after this.
+const CloudTTL = 10 * time.Minute
+
+// Cache wraps a Client, keeping the tag cloud for CloudTTL so the home page
+// doesn't scan the whole table on every view. It's safe for concurrent use
+// and meant to live for as long as the process.
+type Cache struct {
+	*Client
+
+	mu      sync.Mutex
+	cloud   []*Count
+	expires time.Time
+	now     func() time.Time
+}
+
+// NewCache wraps c with a cache
+func NewCache(c *Client) *Cache {
+	return &Cache{
+		Client: c,
+		now:    time.Now,
+	}
+}
+
+// GetCloud counts the published posts of every tag, from the cache if it
+// hasn't expired
+func (c *Cache) GetCloud() ([]*Count, error) {
+	// held while loading so concurrent misses scan the table once
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cloud == nil || !c.now().Before(c.expires) {
+		cloud, err := c.Client.GetCloud()
+		if err != nil {
+			return nil, err
+		}
+
+		c.cloud, c.expires = cloud, c.now().Add(CloudTTL)
+	}
+
+	return copyCloud(c.cloud), nil
+}
+
+// SetTags syncs the tag index of a post and drops the cached cloud
+func (c *Cache) SetTags(
+	id string,
+	created int64,
+	username string,
+	publish int,
+	previous,
+	tags []string,
+) error {
+	defer c.reset()
+	return c.Client.SetTags(id, created, username, publish, previous, tags)
+}
+
+// RemoveTags removes a post from the given tags and drops the cached cloud
+func (c *Cache) RemoveTags(id string, tags []string) error {
+	defer c.reset()
+	return c.Client.RemoveTags(id, tags)
+}
+
+func (c *Cache) reset() {
+	c.mu.Lock()
+	c.cloud = nil
+	c.mu.Unlock()
+}
+
+func copyCloud(cloud []*Count) []*Count {
+	cp := make([]*Count, len(cloud))
+	for i, t := range cloud {
+		count := *t
+		cp[i] = &count
+	}
+
+	return cp
+}
@@ -0,0 +1,103 @@
+package tag
+
+import (
+	"testing"
+	"time"
+
+	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCache(m *test.DynamoProviderMock) (*Cache, *time.Time) {
+	now := time.Unix(1000, 0)
+	c := NewCache(New("a", "b", m))
+	c.now = func() time.Time { return now }
+
+	return c, &now
+}
+
+func cloudScan(tags ...string) *dynamodb.ScanOutput {
+	out := &dynamodb.ScanOutput{}
+	for _, t := range tags {
+		av, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"tag":     t,
+			"publish": 1,
+		})
+		out.Items = append(out.Items, av)
+	}
+
+	return out
+}
+
+func TestCacheGetCloud(t *testing.T) {
+	t.Run("kept until it expires", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c, now := newTestCache(m)
+
+		m.On("Scan", mock.Anything).Return(cloudScan("go"), nil).Once()
+
+		cloud, e := c.GetCloud()
+		assert.Nil(t, e)
+		assert.Equal(t, []*Count{{"go", 1}}, cloud)
+
+		// callers can't change what's cached
+		cloud[0].Count = 42
+
+		*now = now.Add(CloudTTL - time.Second)
+		cloud, _ = c.GetCloud()
+		assert.Equal(t, []*Count{{"go", 1}}, cloud)
+		m.AssertNumberOfCalls(t, "Scan", 1)
+
+		m.On("Scan", mock.Anything).Return(cloudScan("go", "js"), nil).Once()
+
+		*now = now.Add(time.Second)
+		cloud, _ = c.GetCloud()
+		assert.Len(t, cloud, 2)
+		m.AssertNumberOfCalls(t, "Scan", 2)
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c, _ := newTestCache(m)
+
+		m.On("Scan", mock.Anything).Return(nil, errors.New("beep")).Once()
+		m.On("Scan", mock.Anything).Return(cloudScan("go"), nil).Once()
+
+		_, e := c.GetCloud()
+		assert.NotNil(t, e)
+
+		cloud, e := c.GetCloud()
+		assert.Nil(t, e)
+		assert.Len(t, cloud, 1)
+	})
+}
+
+func TestCacheSetTags(t *testing.T) {
+	m := new(test.DynamoProviderMock)
+	c, _ := newTestCache(m)
+
+	m.On("Scan", mock.Anything).Return(cloudScan("go"), nil).Once()
+	m.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+	m.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	_, _ = c.GetCloud()
+
+	e := c.SetTags("test", 1, "ing", 1, nil, []string{"js"})
+	assert.Nil(t, e)
+
+	m.On("Scan", mock.Anything).Return(cloudScan("go", "js"), nil).Once()
+	cloud, _ := c.GetCloud()
+	assert.Len(t, cloud, 2)
+
+	e = c.RemoveTags("test", []string{"js"})
+	assert.Nil(t, e)
+
+	m.On("Scan", mock.Anything).Return(cloudScan("go"), nil).Once()
+	cloud, _ = c.GetCloud()
+	assert.Len(t, cloud, 1)
+	m.AssertNumberOfCalls(t, "Scan", 3)
+}
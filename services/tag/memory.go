@@ -0,0 +1,107 @@
+package tag
+
+import (
+	"sort"
+	"sync"
+)
+
+// Memory keeps the tag index in memory, for as long as the process lives
+type Memory struct {
+	mu   sync.Mutex
+	tags map[string]map[string]*Tag
+}
+
+// NewMemory ...
+func NewMemory() *Memory {
+	return &Memory{tags: map[string]map[string]*Tag{}}
+}
+
+// SetTags syncs the tag index of a post, see Client.SetTags
+func (m *Memory) SetTags(
+	id string,
+	created int64,
+	username string,
+	publish int,
+	previous,
+	tags []string,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(id, stale(previous, tags))
+
+	for _, t := range tags {
+		if m.tags[t] == nil {
+			m.tags[t] = map[string]*Tag{}
+		}
+
+		m.tags[t][id] = &Tag{
+			Tag:      t,
+			ID:       id,
+			Created:  created,
+			Username: username,
+			Publish:  publish,
+		}
+	}
+
+	return nil
+}
+
+// RemoveTags removes a post from the given tags
+func (m *Memory) RemoveTags(id string, tags []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(id, tags)
+	return nil
+}
+
+// GetTagged lists the published posts under a tag, newest first
+func (m *Memory) GetTagged(tag string) ([]*Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var tags []*Tag
+	for _, t := range m.tags[tag] {
+		if t.Publish == 1 {
+			cp := *t
+			tags = append(tags, &cp)
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Created == tags[j].Created {
+			return tags[i].ID > tags[j].ID
+		}
+		return tags[i].Created > tags[j].Created
+	})
+
+	return tags, nil
+}
+
+// GetCloud counts the published posts of every tag, most used first
+func (m *Memory) GetCloud() ([]*Count, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := map[string]int{}
+	for tag, posts := range m.tags {
+		for _, t := range posts {
+			if t.Publish == 1 {
+				counts[tag]++
+			}
+		}
+	}
+
+	return sortCloud(counts), nil
+}
+
+// remove takes a post off the given tags, m.mu must be held
+func (m *Memory) remove(id string, tags []string) {
+	for _, t := range tags {
+		delete(m.tags[t], id)
+		if len(m.tags[t]) == 0 {
+			delete(m.tags, t)
+		}
+	}
+}
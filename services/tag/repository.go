@@ -0,0 +1,46 @@
+package tag
+
+import "sort"
+
+// Repository stores the tag index. Client keeps it on Dynamo, Memory and
+// SQLite let the site run without it.
+type Repository interface {
+	GetCloud() ([]*Count, error)
+	GetTagged(tag string) ([]*Tag, error)
+	SetTags(id string, created int64, username string, publish int, previous, tags []string) error
+	RemoveTags(id string, tags []string) error
+}
+
+// stale are the tags on the previous list that are no longer used
+func stale(previous, tags []string) []string {
+	current := map[string]bool{}
+	for _, t := range tags {
+		current[t] = true
+	}
+
+	var removed []string
+	for _, t := range previous {
+		if !current[t] {
+			removed = append(removed, t)
+		}
+	}
+
+	return removed
+}
+
+// sortCloud turns the post count of each tag into a cloud, most used first
+func sortCloud(counts map[string]int) []*Count {
+	cloud := []*Count{}
+	for t, n := range counts {
+		cloud = append(cloud, &Count{t, n})
+	}
+
+	sort.Slice(cloud, func(i, j int) bool {
+		if cloud[i].Count == cloud[j].Count {
+			return cloud[i].Tag < cloud[j].Tag
+		}
+		return cloud[i].Count > cloud[j].Count
+	})
+
+	return cloud
+}
@@ -0,0 +1,74 @@
+package tag
+
+import (
+	"testing"
+
+	"bishack.dev/services/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	testRepository(t, func() Repository {
+		return NewMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	testRepository(t, func() Repository {
+		db, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewSQLite(db)
+	})
+}
+
+// testRepository checks the behaviour every backend shares
+func testRepository(t *testing.T, newRepo func() Repository) {
+	t.Run("set and list", func(t *testing.T) {
+		r := newRepo()
+
+		assert.Nil(t, r.SetTags("a", 1, "ing", 1, nil, []string{"go", "js"}))
+		assert.Nil(t, r.SetTags("b", 2, "ing", 1, nil, []string{"go"}))
+		assert.Nil(t, r.SetTags("draft", 3, "ing", 0, nil, []string{"go"}))
+
+		tagged, err := r.GetTagged("go")
+		assert.Nil(t, err)
+		assert.Equal(t, []*Tag{
+			{Tag: "go", ID: "b", Created: 2, Username: "ing", Publish: 1},
+			{Tag: "go", ID: "a", Created: 1, Username: "ing", Publish: 1},
+		}, tagged)
+
+		cloud, err := r.GetCloud()
+		assert.Nil(t, err)
+		assert.Equal(t, []*Count{{"go", 2}, {"js", 1}}, cloud)
+
+		tagged, err = r.GetTagged("nope")
+		assert.Nil(t, err)
+		assert.Empty(t, tagged)
+	})
+
+	t.Run("retag", func(t *testing.T) {
+		r := newRepo()
+
+		assert.Nil(t, r.SetTags("a", 1, "ing", 0, nil, []string{"go", "js"}))
+		assert.Nil(t, r.SetTags("a", 1, "ing", 1, []string{"go", "js"}, []string{"go", "rust"}))
+
+		cloud, _ := r.GetCloud()
+		assert.Equal(t, []*Count{{"go", 1}, {"rust", 1}}, cloud)
+
+		tagged, _ := r.GetTagged("js")
+		assert.Empty(t, tagged)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		r := newRepo()
+
+		assert.Nil(t, r.SetTags("a", 1, "ing", 1, nil, []string{"go", "js"}))
+		assert.Nil(t, r.RemoveTags("a", []string{"go", "js"}))
+
+		cloud, _ := r.GetCloud()
+		assert.Empty(t, cloud)
+	})
+}
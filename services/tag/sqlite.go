@@ -0,0 +1,119 @@
+package tag
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// SQLite keeps the tag index on an embedded database, see services/sqlite
+type SQLite struct {
+	DB *sql.DB
+}
+
+// NewSQLite ...
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db}
+}
+
+// SetTags syncs the tag index of a post, see Client.SetTags
+func (s *SQLite) SetTags(
+	id string,
+	created int64,
+	username string,
+	publish int,
+	previous,
+	tags []string,
+) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return errors.Wrap(err, "SetTags/Begin error")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, t := range stale(previous, tags) {
+		if _, err := tx.Exec("DELETE FROM tags WHERE tag = ? AND id = ?", t, id); err != nil {
+			return errors.Wrap(err, "SetTags/Exec error")
+		}
+	}
+
+	for _, t := range tags {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO tags (tag, id, created, username, publish) VALUES (?, ?, ?, ?, ?)",
+			t,
+			id,
+			created,
+			username,
+			publish,
+		)
+		if err != nil {
+			return errors.Wrap(err, "SetTags/Exec error")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RemoveTags removes a post from the given tags
+func (s *SQLite) RemoveTags(id string, tags []string) error {
+	for _, t := range tags {
+		if _, err := s.DB.Exec("DELETE FROM tags WHERE tag = ? AND id = ?", t, id); err != nil {
+			return errors.Wrap(err, "RemoveTags/Exec error")
+		}
+	}
+
+	return nil
+}
+
+// GetTagged lists the published posts under a tag, newest first
+func (s *SQLite) GetTagged(tag string) ([]*Tag, error) {
+	rows, err := s.DB.Query(
+		`SELECT tag, id, created, username, publish FROM tags
+		WHERE tag = ? AND publish = 1 ORDER BY created DESC, id DESC`,
+		tag,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetTagged/Query error")
+	}
+	defer rows.Close()
+
+	var tags []*Tag
+	for rows.Next() {
+		t := &Tag{}
+		if err := rows.Scan(&t.Tag, &t.ID, &t.Created, &t.Username, &t.Publish); err != nil {
+			return nil, errors.Wrap(err, "GetTagged/Scan error")
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetCloud counts the published posts of every tag, most used first
+func (s *SQLite) GetCloud() ([]*Count, error) {
+	rows, err := s.DB.Query("SELECT tag, COUNT(*) FROM tags WHERE publish = 1 GROUP BY tag")
+	if err != nil {
+		return nil, errors.Wrap(err, "GetCloud/Query error")
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var (
+			tag   string
+			count int
+		)
+		if err := rows.Scan(&tag, &count); err != nil {
+			return nil, errors.Wrap(err, "GetCloud/Scan error")
+		}
+
+		counts[tag] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "GetCloud/Rows error")
+	}
+
+	return sortCloud(counts), nil
+}
@@ -0,0 +1,174 @@
+package tag
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// MaxTags is the maximum number of tags a post can have
+const MaxTags = 5
+
+var (
+	rxSeparator = regexp.MustCompile(`[\s,]+`)
+	rxInvalid   = regexp.MustCompile(`[^a-z0-9\-]`)
+)
+
+// Client ...
+type Client struct {
+	*dynamo.Client
+}
+
+// New ...
+func New(
+	tableName,
+	endpoint string,
+	provider dynamo.Provider,
+) *Client {
+	return &Client{
+		dynamo.New(tableName, endpoint, provider),
+	}
+}
+
+// Parse turns a comma or space separated string into a list of normalized
+// tags: lowercased, alphanumeric (and dashes), unique and at most MaxTags.
+func Parse(input string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for _, t := range rxSeparator.Split(strings.ToLower(input), -1) {
+		t = strings.Trim(rxInvalid.ReplaceAllString(t, ""), "-")
+		if t == "" || seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		tags = append(tags, t)
+
+		if len(tags) == MaxTags {
+			break
+		}
+	}
+
+	return tags
+}
+
+// SetTags syncs the tag index of a post. Tags on the previous list that are
+// no longer used are removed, the rest are (re)written so the index picks up
+// the current publish state of the post.
+func (c *Client) SetTags(
+	id string,
+	created int64,
+	username string,
+	publish int,
+	previous,
+	tags []string,
+) error {
+	err := c.RemoveTags(id, stale(previous, tags))
+	if err != nil {
+		return errors.Wrap(err, "SetTags")
+	}
+
+	for _, t := range tags {
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"tag":      t,
+			"id":       id,
+			"created":  created,
+			"username": username,
+			"publish":  publish,
+		})
+
+		input := &dynamodb.PutItemInput{}
+		input.SetTableName(c.TableName)
+		input.SetItem(item)
+
+		_, err := c.Provider.PutItem(input)
+		if err != nil {
+			return errors.Wrap(err, "SetTags/PutItem error")
+		}
+	}
+
+	return nil
+}
+
+// RemoveTags removes a post from the given tags
+func (c *Client) RemoveTags(id string, tags []string) error {
+	for _, t := range tags {
+		key, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"tag": t,
+			"id":  id,
+		})
+
+		input := &dynamodb.DeleteItemInput{}
+		input.SetTableName(c.TableName)
+		input.SetKey(key)
+
+		_, err := c.Provider.DeleteItem(input)
+		if err != nil {
+			return errors.Wrap(err, "RemoveTags/DeleteItem error")
+		}
+	}
+
+	return nil
+}
+
+// GetTagged lists the published posts under a tag, newest first
+func (c *Client) GetTagged(tag string) ([]*Tag, error) {
+	ks := "tag = :tag"
+	fs := "publish = :publish"
+	vals := map[string]interface{}{
+		":tag":     tag,
+		":publish": 1,
+	}
+
+	out, err := c.Query("", ks, fs, vals, false, 0, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetTagged/Query error")
+	}
+
+	var tags []*Tag
+	_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &tags)
+
+	// range key is the post id so we sort by date ourselves
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Created > tags[j].Created
+	})
+
+	return tags, nil
+}
+
+// GetCloud counts the published posts of every tag, most used first
+func (c *Client) GetCloud() ([]*Count, error) {
+	counts := map[string]int{}
+
+	input := &dynamodb.ScanInput{}
+	input.SetTableName(c.TableName)
+	input.SetProjectionExpression("tag, publish")
+
+	for {
+		out, err := c.Provider.Scan(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetCloud/Scan error")
+		}
+
+		var tags []*Tag
+		_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &tags)
+		for _, t := range tags {
+			if t.Publish == 1 {
+				counts[t.Tag]++
+			}
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.SetExclusiveStartKey(out.LastEvaluatedKey)
+	}
+
+	return sortCloud(counts), nil
+}
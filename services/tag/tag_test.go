@@ -0,0 +1,160 @@
+package tag
+
+import (
+	"regexp"
+	"testing"
+
+	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, []string{}, Parse(""))
+	assert.Equal(t, []string{"go", "design"}, Parse("Go, design"))
+	assert.Equal(t, []string{"go", "js"}, Parse("#go go,,  JS"))
+	assert.Equal(t, []string{"aws-lambda"}, Parse("-aws-lambda-"))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, Parse("a b c d e f g"))
+}
+
+func TestSetTags(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		e := c.SetTags("x", 42, "ing", 1, nil, []string{"go"})
+		assert.Regexp(t, regexp.MustCompile(`(?i)settags/putitem error: beep`), e.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return *input.Key["tag"].S == "js" && *input.Key["id"].S == "x"
+		})).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			return *input.Item["id"].S == "x" &&
+				*input.Item["username"].S == "ing" &&
+				*input.Item["publish"].N == "1"
+		})).Return(&dynamodb.PutItemOutput{}, nil).Twice()
+
+		e := c.SetTags("x", 42, "ing", 1, []string{"go", "js"}, []string{"go", "design"})
+		assert.Nil(t, e)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestRemoveTags(t *testing.T) {
+	m := new(test.DynamoProviderMock)
+	c := New("a", "b", m)
+
+	m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return true
+	})).Return(nil, errors.New("beep"))
+
+	e := c.RemoveTags("x", []string{"go"})
+	assert.Regexp(t, regexp.MustCompile(`(?i)removetags/deleteitem error: beep`), e.Error())
+}
+
+func TestGetTagged(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		tags, e := c.GetTagged("go")
+		assert.Nil(t, tags)
+		assert.Regexp(t, regexp.MustCompile(`(?i)gettagged/query error: beep`), e.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		out := &dynamodb.QueryOutput{}
+		older, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"tag":     "go",
+			"id":      "a",
+			"created": 1,
+		})
+		newer, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"tag":     "go",
+			"id":      "b",
+			"created": 2,
+		})
+		out.SetItems([]map[string]*dynamodb.AttributeValue{older, newer})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.KeyConditionExpression == "tag = :tag" &&
+				*input.FilterExpression == "publish = :publish"
+		})).Return(out, nil)
+
+		tags, e := c.GetTagged("go")
+		assert.Nil(t, e)
+		assert.Equal(t, 2, len(tags))
+		assert.Equal(t, "b", tags[0].ID)
+		assert.Equal(t, "a", tags[1].ID)
+	})
+}
+
+func TestGetCloud(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		cloud, e := c.GetCloud()
+		assert.Nil(t, cloud)
+		assert.Regexp(t, regexp.MustCompile(`(?i)getcloud/scan error: beep`), e.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		item := func(tag string, publish int) map[string]*dynamodb.AttributeValue {
+			av, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+				"tag":     tag,
+				"publish": publish,
+			})
+			return av
+		}
+
+		first := &dynamodb.ScanOutput{}
+		first.SetItems([]map[string]*dynamodb.AttributeValue{
+			item("js", 1),
+			item("go", 1),
+		})
+		first.SetLastEvaluatedKey(item("go", 1))
+		second := &dynamodb.ScanOutput{}
+		second.SetItems([]map[string]*dynamodb.AttributeValue{
+			item("go", 1),
+			item("design", 0),
+		})
+
+		m.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			return input.ExclusiveStartKey == nil
+		})).Return(first, nil).Once()
+		m.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			return input.ExclusiveStartKey != nil
+		})).Return(second, nil).Once()
+
+		cloud, e := c.GetCloud()
+		assert.Nil(t, e)
+		assert.Equal(t, []*Count{{"go", 2}, {"js", 1}}, cloud)
+		m.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,16 @@
+package tag
+
+// Tag is an entry on the tag index that points to a post
+type Tag struct {
+	Tag      string
+	ID       string
+	Created  int64
+	Username string
+	Publish  int
+}
+
+// Count is a tag and the number of published posts using it
+type Count struct {
+	Tag   string
+	Count int
+}
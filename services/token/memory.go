@@ -0,0 +1,99 @@
+package token
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory keeps tokens in memory, for as long as the process lives
+type Memory struct {
+	mu     sync.Mutex
+	tokens map[string]*Token
+}
+
+// NewMemory ...
+func NewMemory() *Memory {
+	return &Memory{tokens: map[string]*Token{}}
+}
+
+// CreateToken creates a new token for the user and returns its secret.
+// The secret is only ever available here.
+func (m *Memory) CreateToken(username, name string, scopes []string) (string, *Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	secret, t, err := newToken(username, name, scopes, func() (int, error) {
+		return len(m.find(username)), nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	m.tokens[t.ID] = copyToken(t)
+	return secret, t, nil
+}
+
+// GetTokens lists the tokens of a user, newest first
+func (m *Memory) GetTokens(username string) ([]*Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.find(username), nil
+}
+
+// Verify looks up the token of the given secret
+func (m *Memory) Verify(secret string) (*Token, error) {
+	if !strings.HasPrefix(secret, secretPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tokens[hash(secret)]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return copyToken(t), nil
+}
+
+// RevokeToken deletes a token of the user
+func (m *Memory) RevokeToken(username, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tokens[id]
+	if !ok || t.Username != username {
+		return ErrNotFound
+	}
+
+	delete(m.tokens, id)
+	return nil
+}
+
+// find gets the tokens of a user, newest first. m.mu must be held.
+func (m *Memory) find(username string) []*Token {
+	tokens := []*Token{}
+	for _, t := range m.tokens {
+		if t.Username == username {
+			tokens = append(tokens, copyToken(t))
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Created == tokens[j].Created {
+			return tokens[i].ID > tokens[j].ID
+		}
+		return tokens[i].Created > tokens[j].Created
+	})
+
+	return tokens
+}
+
+func copyToken(t *Token) *Token {
+	cp := *t
+	cp.Scopes = append([]string{}, t.Scopes...)
+	return &cp
+}
@@ -0,0 +1,68 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Repository stores tokens. Client keeps them on Dynamo, Memory and SQLite
+// let the site run without it.
+type Repository interface {
+	CreateToken(username, name string, scopes []string) (string, *Token, error)
+	GetTokens(username string) ([]*Token, error)
+	RevokeToken(username, id string) error
+	Verify(secret string) (*Token, error)
+}
+
+// newToken checks what the user asked for, and that count, the number of
+// tokens they have, leaves room for another, then makes the token and its
+// secret. It's up to the caller to store it.
+func newToken(
+	username,
+	name string,
+	scopes []string,
+	count func() (int, error),
+) (string, *Token, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("Token name is required")
+	}
+
+	if len(scopes) == 0 {
+		return "", nil, errors.New("Pick at least one scope")
+	}
+
+	for _, s := range scopes {
+		if !validScope(s) {
+			return "", nil, errors.Errorf("Unknown scope %q", s)
+		}
+	}
+
+	n, err := count()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "CreateToken")
+	}
+
+	if n >= MaxTokens {
+		return "", nil, errors.Errorf("You can only have %d tokens", MaxTokens)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, errors.Wrap(err, "CreateToken/Read error")
+	}
+	secret := secretPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return secret, &Token{
+		ID:       hash(secret),
+		Username: username,
+		Name:     name,
+		Prefix:   secret[:len(secretPrefix)+4],
+		Scopes:   scopes,
+		Created:  time.Now().Unix(),
+	}, nil
+}
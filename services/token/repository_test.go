@@ -0,0 +1,89 @@
+package token
+
+import (
+	"testing"
+
+	"bishack.dev/services/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	testRepository(t, func() Repository {
+		return NewMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	testRepository(t, func() Repository {
+		db, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewSQLite(db)
+	})
+}
+
+// testRepository checks the behaviour every backend shares
+func testRepository(t *testing.T, newRepo func() Repository) {
+	t.Run("create, verify and revoke", func(t *testing.T) {
+		r := newRepo()
+
+		secret, tk, err := r.CreateToken("ing", " laptop ", []string{ScopeRead, ScopeLike})
+		assert.Nil(t, err)
+		assert.Equal(t, "laptop", tk.Name)
+		assert.Equal(t, secret[:len(tk.Prefix)], tk.Prefix)
+
+		v, err := r.Verify(secret)
+		assert.Nil(t, err)
+		assert.Equal(t, tk, v)
+
+		tokens, err := r.GetTokens("ing")
+		assert.Nil(t, err)
+		assert.Equal(t, []*Token{tk}, tokens)
+
+		tokens, _ = r.GetTokens("other")
+		assert.Empty(t, tokens)
+
+		assert.Equal(t, ErrNotFound, r.RevokeToken("other", tk.ID))
+		assert.Nil(t, r.RevokeToken("ing", tk.ID))
+		assert.Equal(t, ErrNotFound, r.RevokeToken("ing", tk.ID))
+
+		_, err = r.Verify(secret)
+		assert.Equal(t, ErrInvalidToken, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := newRepo()
+
+		_, _, err := r.CreateToken("ing", " ", []string{ScopeRead})
+		assert.NotNil(t, err)
+
+		_, _, err = r.CreateToken("ing", "laptop", nil)
+		assert.NotNil(t, err)
+
+		_, _, err = r.CreateToken("ing", "laptop", []string{"admin"})
+		assert.NotNil(t, err)
+
+		_, err = r.Verify("nope")
+		assert.Equal(t, ErrInvalidToken, err)
+
+		_, err = r.Verify(secretPrefix + "nope")
+		assert.Equal(t, ErrInvalidToken, err)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		r := newRepo()
+
+		for i := 0; i < MaxTokens; i++ {
+			_, _, err := r.CreateToken("ing", "laptop", []string{ScopeRead})
+			assert.Nil(t, err)
+		}
+
+		_, _, err := r.CreateToken("ing", "laptop", []string{ScopeRead})
+		assert.EqualError(t, err, "You can only have 10 tokens")
+
+		_, _, err = r.CreateToken("other", "laptop", []string{ScopeRead})
+		assert.Nil(t, err)
+	})
+}
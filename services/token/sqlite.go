@@ -0,0 +1,130 @@
+package token
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// columns of the tokens table, in the order scan reads them
+const columns = "id, username, name, prefix, scopes, created"
+
+// SQLite keeps tokens on an embedded database, see services/sqlite
+type SQLite struct {
+	DB *sql.DB
+}
+
+// NewSQLite ...
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db}
+}
+
+// CreateToken creates a new token for the user and returns its secret.
+// The secret is only ever available here.
+func (s *SQLite) CreateToken(username, name string, scopes []string) (string, *Token, error) {
+	secret, t, err := newToken(username, name, scopes, func() (int, error) {
+		var n int
+		err := s.DB.QueryRow("SELECT COUNT(*) FROM tokens WHERE username = ?", username).Scan(&n)
+		return n, err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	b, _ := json.Marshal(t.Scopes)
+
+	_, err = s.DB.Exec(
+		"INSERT INTO tokens ("+columns+") VALUES (?, ?, ?, ?, ?, ?)",
+		t.ID,
+		t.Username,
+		t.Name,
+		t.Prefix,
+		string(b),
+		t.Created,
+	)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "CreateToken/Exec error")
+	}
+
+	return secret, t, nil
+}
+
+// GetTokens lists the tokens of a user, newest first
+func (s *SQLite) GetTokens(username string) ([]*Token, error) {
+	rows, err := s.DB.Query(
+		"SELECT "+columns+" FROM tokens WHERE username = ? ORDER BY created DESC, id DESC",
+		username,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetTokens/Query error")
+	}
+	defer rows.Close()
+
+	tokens := []*Token{}
+	for rows.Next() {
+		t, err := scan(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "GetTokens/Scan error")
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Verify looks up the token of the given secret
+func (s *SQLite) Verify(secret string) (*Token, error) {
+	if !strings.HasPrefix(secret, secretPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	t, err := scan(s.DB.QueryRow("SELECT "+columns+" FROM tokens WHERE id = ?", hash(secret)))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Verify/Scan error")
+	}
+
+	return t, nil
+}
+
+// RevokeToken deletes a token of the user
+func (s *SQLite) RevokeToken(username, id string) error {
+	res, err := s.DB.Exec("DELETE FROM tokens WHERE id = ? AND username = ?", id, username)
+	if err != nil {
+		return errors.Wrap(err, "RevokeToken/Exec error")
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan reads a row of columns
+func scan(row scanner) (*Token, error) {
+	var (
+		t      Token
+		scopes string
+	)
+
+	err := row.Scan(&t.ID, &t.Username, &t.Name, &t.Prefix, &scopes, &t.Created)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(scopes), &t.Scopes); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,172 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// Scopes a token can be granted
+const (
+	ScopeRead       = "read"
+	ScopeWritePosts = "write:posts"
+	ScopeLike       = "like"
+)
+
+// Scopes lists every scope in the order they're shown to the user
+var Scopes = []string{ScopeRead, ScopeWritePosts, ScopeLike}
+
+// MaxTokens is the maximum number of tokens a user can have
+const MaxTokens = 10
+
+// secretPrefix makes leaked tokens easy to spot
+const secretPrefix = "bh_"
+
+var (
+	// ErrInvalidToken is returned when a secret doesn't match any token
+	ErrInvalidToken = errors.New("Invalid token")
+
+	// ErrNotFound is returned when revoking a token the user doesn't own
+	ErrNotFound = errors.New("Token not found")
+)
+
+// Client ...
+type Client struct {
+	*dynamo.Client
+}
+
+// New ...
+func New(
+	tableName,
+	endpoint string,
+	provider dynamo.Provider,
+) *Client {
+	return &Client{
+		dynamo.New(tableName, endpoint, provider),
+	}
+}
+
+// CreateToken creates a new token for the user and returns its secret.
+// The secret is only ever available here.
+func (c *Client) CreateToken(username, name string, scopes []string) (string, *Token, error) {
+	secret, t, err := newToken(username, name, scopes, func() (int, error) {
+		tokens, err := c.GetTokens(username)
+		return len(tokens), err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id":       t.ID,
+		"username": t.Username,
+		"name":     t.Name,
+		"prefix":   t.Prefix,
+		"scopes":   t.Scopes,
+		"created":  t.Created,
+	})
+
+	input := &dynamodb.PutItemInput{
+		Item: item,
+	}
+	input.SetTableName(c.TableName)
+	input.SetConditionExpression("attribute_not_exists(id)")
+
+	if _, err := c.Provider.PutItem(input); err != nil {
+		return "", nil, errors.Wrap(err, "CreateToken/PutItem error")
+	}
+
+	return secret, t, nil
+}
+
+// GetTokens lists the tokens of a user, newest first
+func (c *Client) GetTokens(username string) ([]*Token, error) {
+	ks := "username = :username"
+	vals := map[string]interface{}{
+		":username": username,
+	}
+
+	out, err := c.Query("username_index", ks, "", vals, false, 0, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetTokens/Query error")
+	}
+
+	tokens := []*Token{}
+	_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &tokens)
+	return tokens, nil
+}
+
+// Verify looks up the token of the given secret
+func (c *Client) Verify(secret string) (*Token, error) {
+	if !strings.HasPrefix(secret, secretPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	ks := "id = :id"
+	vals := map[string]interface{}{
+		":id": hash(secret),
+	}
+
+	out, err := c.Query("", ks, "", vals, false, 0, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Verify/Query error")
+	}
+
+	if len(out.Items) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	var t Token
+	_ = dynamodbattribute.UnmarshalMap(out.Items[0], &t)
+	return &t, nil
+}
+
+// RevokeToken deletes a token of the user
+func (c *Client) RevokeToken(username, id string) error {
+	keys, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id": id,
+	})
+
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":username": username,
+	})
+
+	input := &dynamodb.DeleteItemInput{}
+	input.SetTableName(c.TableName)
+	input.SetKey(keys)
+	input.SetConditionExpression("username = :username")
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := c.Provider.DeleteItem(input)
+	if aerr, ok := err.(awserr.Error); ok &&
+		aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "RevokeToken/DeleteItem error")
+	}
+
+	return nil
+}
+
+func validScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
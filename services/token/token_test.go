@@ -0,0 +1,212 @@
+package token
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHasScope(t *testing.T) {
+	tk := &Token{Scopes: []string{ScopeRead}}
+	assert.True(t, tk.HasScope(ScopeRead))
+	assert.False(t, tk.HasScope(ScopeLike))
+	assert.False(t, tk.HasScope(""))
+}
+
+func TestCreateToken(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		c := New("a", "b", new(test.DynamoProviderMock))
+
+		_, _, err := c.CreateToken("ing", " ", []string{ScopeRead})
+		assert.Regexp(t, regexp.MustCompile("name is required"), err.Error())
+
+		_, _, err = c.CreateToken("ing", "ci", nil)
+		assert.Regexp(t, regexp.MustCompile("at least one scope"), err.Error())
+
+		_, _, err = c.CreateToken("ing", "ci", []string{"admin"})
+		assert.Regexp(t, regexp.MustCompile(`Unknown scope "admin"`), err.Error())
+	})
+
+	t.Run("too many", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		var items []map[string]*dynamodb.AttributeValue
+		for i := 0; i < MaxTokens; i++ {
+			item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+				"id": strconv.Itoa(i),
+			})
+			items = append(items, item)
+		}
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(&dynamodb.QueryOutput{Items: items}, nil)
+
+		_, _, err := c.CreateToken("ing", "ci", []string{ScopeRead})
+		assert.Regexp(t, regexp.MustCompile("only have 10 tokens"), err.Error())
+	})
+
+	t.Run("put error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(&dynamodb.QueryOutput{}, nil)
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		_, _, err := c.CreateToken("ing", "ci", []string{ScopeRead})
+		assert.Regexp(t, regexp.MustCompile("(?i)createtoken/putitem error: beep"), err.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		var stored map[string]*dynamodb.AttributeValue
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.IndexName == "username_index"
+		})).Return(&dynamodb.QueryOutput{}, nil)
+		m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			stored = input.Item
+			return true
+		})).Return(&dynamodb.PutItemOutput{}, nil)
+
+		secret, tk, err := c.CreateToken("ing", " ci ", []string{ScopeRead, ScopeWritePosts})
+		assert.Nil(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^bh_[A-Za-z0-9_-]{43}$`), secret)
+		assert.Equal(t, "ci", tk.Name)
+		assert.Equal(t, secret[:7], tk.Prefix)
+
+		// only the hash of the secret is stored
+		assert.Equal(t, hash(secret), *stored["id"].S)
+		assert.NotEqual(t, secret, *stored["id"].S)
+		assert.Equal(t, "ing", *stored["username"].S)
+	})
+}
+
+func TestGetTokens(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		_, err := c.GetTokens("ing")
+		assert.Regexp(t, regexp.MustCompile("(?i)gettokens/query error: beep"), err.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":     "x",
+			"name":   "ci",
+			"scopes": []string{ScopeRead},
+		})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.ExpressionAttributeValues[":username"].S == "ing"
+		})).Return(&dynamodb.QueryOutput{
+			Items: []map[string]*dynamodb.AttributeValue{item},
+		}, nil)
+
+		tokens, err := c.GetTokens("ing")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(tokens))
+		assert.Equal(t, "ci", tokens[0].Name)
+		assert.True(t, tokens[0].HasScope(ScopeRead))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("wrong prefix", func(t *testing.T) {
+		c := New("a", "b", new(test.DynamoProviderMock))
+
+		_, err := c.Verify("nope")
+		assert.Equal(t, ErrInvalidToken, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return *input.ExpressionAttributeValues[":id"].S == hash("bh_test")
+		})).Return(&dynamodb.QueryOutput{}, nil)
+
+		_, err := c.Verify("bh_test")
+		assert.Equal(t, ErrInvalidToken, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":       hash("bh_test"),
+			"username": "ing",
+		})
+		m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+			return true
+		})).Return(&dynamodb.QueryOutput{
+			Items: []map[string]*dynamodb.AttributeValue{item},
+		}, nil)
+
+		tk, err := c.Verify("bh_test")
+		assert.Nil(t, err)
+		assert.Equal(t, "ing", tk.Username)
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	t.Run("not owned", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+		assert.Equal(t, ErrNotFound, c.RevokeToken("ing", "x"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		err := c.RevokeToken("ing", "x")
+		assert.Regexp(t, regexp.MustCompile("(?i)revoketoken/deleteitem error: beep"), err.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		c := New("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return *input.Key["id"].S == "x" &&
+				*input.ExpressionAttributeValues[":username"].S == "ing"
+		})).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+		assert.Nil(t, c.RevokeToken("ing", "x"))
+		m.AssertExpectations(t)
+	})
+}
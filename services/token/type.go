@@ -0,0 +1,23 @@
+package token
+
+// Token is a personal access token. The secret itself is never stored,
+// only its hash which doubles as the id.
+type Token struct {
+	ID       string
+	Username string
+	Name     string
+	Prefix   string
+	Scopes   []string
+	Created  int64
+}
+
+// HasScope reports whether the token grants the given scope
+func (t *Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,187 @@
+package user
+
+import (
+	"sync"
+	"time"
+
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+const (
+	// defaultTokenExpiry is how long Cognito access tokens last when it
+	// doesn't say
+	defaultTokenExpiry = time.Hour
+
+	// tokenMargin is how long before it expires an access token stops being
+	// handed out, so it doesn't expire halfway through a request
+	tokenMargin = 5 * time.Minute
+
+	// DetailsTTL is how long user details are kept. Updates through the
+	// cache drop them right away, changes made elsewhere show up after this.
+	DetailsTTL = 5 * time.Minute
+
+	// sweepEvery is how many writes go between sweeps of expired entries
+	sweepEvery = 256
+)
+
+// Cache wraps a Client, reusing access tokens until they're about to expire
+// and keeping user details for DetailsTTL. It's safe for concurrent use and
+// meant to live for as long as the process.
+type Cache struct {
+	*Client
+
+	tokens   *ttlMap
+	details  *ttlMap
+	profiles *ttlMap
+	now      func() time.Time
+}
+
+// NewCache wraps c with a cache
+func NewCache(c *Client) *Cache {
+	return &Cache{
+		Client:   c,
+		tokens:   newTTLMap(),
+		details:  newTTLMap(),
+		profiles: newTTLMap(),
+		now:      time.Now,
+	}
+}
+
+// GetToken gets an access token for the refresh token, from the cache if
+// one is still good
+func (c *Cache) GetToken(username, token string) (string, error) {
+	key := username + "\x00" + token
+	if v, ok := c.tokens.get(key, c.now()); ok {
+		return v.(string), nil
+	}
+
+	accessToken, expires, err := c.Client.refresh(username, token)
+	if err != nil {
+		return "", err
+	}
+
+	if expires > tokenMargin {
+		c.tokens.set(key, accessToken, c.now(), expires-tokenMargin)
+	}
+
+	return accessToken, nil
+}
+
+// AccountDetails gets the user an access token belongs to
+func (c *Cache) AccountDetails(token string) *User {
+	if v, ok := c.details.get(token, c.now()); ok {
+		return copyUser(v.(*User))
+	}
+
+	u := c.Client.AccountDetails(token)
+	if u == nil {
+		return nil
+	}
+
+	c.details.set(token, copyUser(u), c.now(), DetailsTTL)
+	return u
+}
+
+// GetUser gets a user by username
+func (c *Cache) GetUser(username string) *User {
+	if v, ok := c.profiles.get(username, c.now()); ok {
+		return copyUser(v.(*User))
+	}
+
+	u := c.Client.GetUser(username)
+	if u == nil {
+		return nil
+	}
+
+	c.profiles.set(username, copyUser(u), c.now(), DetailsTTL)
+	return u
+}
+
+// UpdateUser updates the user's attributes and drops everything cached of
+// them: their profile and their details under any access token
+func (c *Cache) UpdateUser(token string, attributes map[string]string) (*cip.UpdateUserAttributesOutput, error) {
+	// the username doesn't change, so it's looked up before the update
+	username := ""
+	if u := c.AccountDetails(token); u != nil {
+		username = u.Username
+	}
+
+	out, err := c.Client.UpdateUser(token, attributes)
+
+	c.details.delete(token)
+	if username != "" {
+		c.profiles.delete(username)
+		c.details.deleteWhere(func(v interface{}) bool {
+			return v.(*User).Username == username
+		})
+	}
+
+	return out, err
+}
+
+func copyUser(u *User) *User {
+	cp := *u
+	return &cp
+}
+
+type ttlEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// ttlMap is a map whose entries expire. Expired entries are swept every so
+// many writes so it doesn't grow without bounds.
+type ttlMap struct {
+	sync.Mutex
+	entries map[string]ttlEntry
+	writes  int
+}
+
+func newTTLMap() *ttlMap {
+	return &ttlMap{entries: map[string]ttlEntry{}}
+}
+
+func (m *ttlMap) get(key string, now time.Time) (interface{}, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+
+	return e.value, true
+}
+
+func (m *ttlMap) set(key string, value interface{}, now time.Time, ttl time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries[key] = ttlEntry{value, now.Add(ttl)}
+
+	if m.writes++; m.writes%sweepEvery == 0 {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+	}
+}
+
+func (m *ttlMap) delete(key string) {
+	m.Lock()
+	delete(m.entries, key)
+	m.Unlock()
+}
+
+// deleteWhere deletes every entry whose value matches, expired or not
+func (m *ttlMap) deleteWhere(match func(value interface{}) bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	for k, e := range m.entries {
+		if match(e.value) {
+			delete(m.entries, k)
+		}
+	}
+}
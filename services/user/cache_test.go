@@ -0,0 +1,166 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCache(to *MockedUserService) (*Cache, *time.Time) {
+	client := New("id", "secret")
+	client.Provider = to
+
+	now := time.Unix(1000, 0)
+	c := NewCache(client)
+	c.now = func() time.Time { return now }
+
+	return c, &now
+}
+
+func userOutput(nickname string) []*cip.AttributeType {
+	return []*cip.AttributeType{
+		{Name: aws.String("nickname"), Value: aws.String(nickname)},
+	}
+}
+
+func TestCacheGetToken(t *testing.T) {
+	t.Run("reused until near expiry", func(t *testing.T) {
+		to := new(MockedUserService)
+		c, now := newTestCache(to)
+
+		to.On("InitiateAuth", mock.Anything).Return(&cip.InitiateAuthOutput{
+			AuthenticationResult: &cip.AuthenticationResultType{
+				AccessToken: aws.String("a"),
+				ExpiresIn:   aws.Int64(3600),
+			},
+		}, nil).Once()
+
+		token, err := c.GetToken("test", "ing")
+		assert.Nil(t, err)
+		assert.Equal(t, "a", token)
+
+		*now = now.Add(50 * time.Minute)
+		token, _ = c.GetToken("test", "ing")
+		assert.Equal(t, "a", token)
+		to.AssertNumberOfCalls(t, "InitiateAuth", 1)
+
+		to.On("InitiateAuth", mock.Anything).Return(&cip.InitiateAuthOutput{
+			AuthenticationResult: &cip.AuthenticationResultType{
+				AccessToken: aws.String("b"),
+				ExpiresIn:   aws.Int64(3600),
+			},
+		}, nil).Once()
+
+		*now = now.Add(6 * time.Minute)
+		token, _ = c.GetToken("test", "ing")
+		assert.Equal(t, "b", token)
+		to.AssertNumberOfCalls(t, "InitiateAuth", 2)
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		to := new(MockedUserService)
+		c, _ := newTestCache(to)
+
+		to.On("InitiateAuth", mock.Anything).Return(nil, assert.AnError)
+
+		_, err := c.GetToken("test", "ing")
+		assert.NotNil(t, err)
+		_, err = c.GetToken("test", "ing")
+		assert.NotNil(t, err)
+		to.AssertNumberOfCalls(t, "InitiateAuth", 2)
+	})
+}
+
+func TestCacheAccountDetails(t *testing.T) {
+	to := new(MockedUserService)
+	c, now := newTestCache(to)
+
+	to.On("GetUser", mock.Anything).Return(&cip.GetUserOutput{
+		UserAttributes: userOutput("test"),
+	}, nil)
+
+	u := c.AccountDetails("a")
+	assert.Equal(t, "test", u.Username)
+
+	// callers can't change what's cached
+	u.Username = "changed"
+	assert.Equal(t, "test", c.AccountDetails("a").Username)
+	to.AssertNumberOfCalls(t, "GetUser", 1)
+
+	*now = now.Add(DetailsTTL)
+	c.AccountDetails("a")
+	to.AssertNumberOfCalls(t, "GetUser", 2)
+}
+
+func TestCacheGetUser(t *testing.T) {
+	to := new(MockedUserService)
+	c, _ := newTestCache(to)
+
+	to.On("AdminGetUser", mock.Anything).Return(nil, assert.AnError).Once()
+	assert.Nil(t, c.GetUser("test"))
+
+	to.On("AdminGetUser", mock.Anything).Return(&cip.AdminGetUserOutput{
+		UserAttributes: userOutput("test"),
+	}, nil).Once()
+	assert.Equal(t, "test", c.GetUser("test").Username)
+	assert.Equal(t, "test", c.GetUser("test").Username)
+	to.AssertNumberOfCalls(t, "AdminGetUser", 2)
+}
+
+func TestCacheUpdateUser(t *testing.T) {
+	t.Run("details cached under the token", func(t *testing.T) {
+		to := new(MockedUserService)
+		c, _ := newTestCache(to)
+
+		to.On("GetUser", mock.Anything).Return(&cip.GetUserOutput{
+			UserAttributes: userOutput("test"),
+		}, nil)
+		to.On("AdminGetUser", mock.Anything).Return(&cip.AdminGetUserOutput{
+			UserAttributes: userOutput("test"),
+		}, nil)
+		to.On("UpdateUserAttributes", mock.Anything).Return(&cip.UpdateUserAttributesOutput{}, nil)
+
+		c.AccountDetails("a")
+		c.GetUser("test")
+
+		_, err := c.UpdateUser("a", map[string]string{"name": "Test"})
+		assert.Nil(t, err)
+
+		c.AccountDetails("a")
+		c.GetUser("test")
+		to.AssertNumberOfCalls(t, "GetUser", 2)
+		to.AssertNumberOfCalls(t, "AdminGetUser", 2)
+	})
+
+	t.Run("details not cached under the token", func(t *testing.T) {
+		to := new(MockedUserService)
+		c, _ := newTestCache(to)
+
+		to.On("GetUser", mock.Anything).Return(&cip.GetUserOutput{
+			UserAttributes: userOutput("test"),
+		}, nil)
+		to.On("AdminGetUser", mock.Anything).Return(&cip.AdminGetUserOutput{
+			UserAttributes: userOutput("test"),
+		}, nil)
+		to.On("UpdateUserAttributes", mock.Anything).Return(&cip.UpdateUserAttributesOutput{}, nil)
+
+		// the same user signed in elsewhere
+		c.AccountDetails("other session")
+		c.GetUser("test")
+
+		_, err := c.UpdateUser("a", map[string]string{"name": "Test"})
+		assert.Nil(t, err)
+
+		// one lookup for the username, then nothing of theirs is left
+		to.AssertNumberOfCalls(t, "GetUser", 2)
+
+		c.AccountDetails("other session")
+		c.GetUser("test")
+		to.AssertNumberOfCalls(t, "GetUser", 3)
+		to.AssertNumberOfCalls(t, "AdminGetUser", 2)
+	})
+}
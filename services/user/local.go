@@ -0,0 +1,477 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+const (
+	// LocalTokenExpiry is how long access tokens from Local last, same as
+	// Cognito's default
+	LocalTokenExpiry = time.Hour
+
+	// LocalRefreshExpiry is how long refresh tokens from Local last
+	LocalRefreshExpiry = 30 * 24 * time.Hour
+
+	// LocalCodeExpiry is how long confirmation codes are good for
+	LocalCodeExpiry = 24 * time.Hour
+
+	// localMinPassword is the shortest password the pool takes
+	localMinPassword = 6
+)
+
+// Local is an in-process stand-in for Cognito, so signing up, verifying,
+// logging in and changing passwords work without AWS. It answers with the
+// same outputs and error codes Cognito does. Users last as long as the
+// process and the pool id is ignored. Not for production.
+type Local struct {
+	ClientID     string
+	ClientSecret string
+
+	// Deliver gets the confirmation code Cognito would have emailed, it
+	// logs it by default
+	Deliver func(username, code string)
+
+	mu      sync.Mutex
+	users   map[string]*localUser
+	access  map[string]localToken
+	refresh map[string]localToken
+	now     func() time.Time
+}
+
+type localUser struct {
+	sub       string
+	username  string
+	salt      []byte
+	password  []byte
+	confirmed bool
+	code      string
+	codeUntil time.Time
+	created   time.Time
+	modified  time.Time
+	attrs     map[string]string
+}
+
+type localToken struct {
+	username string
+	expires  time.Time
+}
+
+// NewLocal creates a Local pool for the app client id and secret
+func NewLocal(id, secret string) *Local {
+	return &Local{
+		ClientID:     id,
+		ClientSecret: secret,
+		Deliver: func(username, code string) {
+			log.Printf("Local Cognito: confirmation code for %s is %s", username, code)
+		},
+		users:   map[string]*localUser{},
+		access:  map[string]localToken{},
+		refresh: map[string]localToken{},
+		now:     time.Now,
+	}
+}
+
+// SignUp adds an unconfirmed user and delivers their confirmation code
+func (l *Local) SignUp(in *cip.SignUpInput) (*cip.SignUpOutput, error) {
+	username, password := aws.StringValue(in.Username), aws.StringValue(in.Password)
+	if err := l.checkClient(in.ClientId, username, in.SecretHash); err != nil {
+		return nil, err
+	}
+
+	if username == "" {
+		return nil, localError(cip.ErrCodeInvalidParameterException, "1 validation error detected: Value at 'username' failed to satisfy constraint: Member must have length greater than or equal to 1")
+	}
+
+	if err := checkPassword(password); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.users[username]; ok {
+		return nil, localError(cip.ErrCodeUsernameExistsException, "User already exists")
+	}
+
+	now := l.now()
+	u := &localUser{
+		sub:      randomUUID(),
+		username: username,
+		salt:     randomBytes(16),
+		created:  now,
+		modified: now,
+		attrs:    map[string]string{},
+	}
+	u.password = hashPassword(u.salt, password)
+
+	for _, a := range in.UserAttributes {
+		if a == nil || aws.StringValue(a.Name) == "sub" {
+			continue
+		}
+		u.attrs[aws.StringValue(a.Name)] = aws.StringValue(a.Value)
+	}
+	u.attrs["sub"] = u.sub
+
+	u.code, u.codeUntil = randomCode(), now.Add(LocalCodeExpiry)
+	l.users[username] = u
+	l.Deliver(username, u.code)
+
+	return &cip.SignUpOutput{
+		UserConfirmed: aws.Bool(false),
+		UserSub:       aws.String(u.sub),
+		CodeDeliveryDetails: &cip.CodeDeliveryDetailsType{
+			AttributeName:  aws.String("email"),
+			DeliveryMedium: aws.String(cip.DeliveryMediumTypeEmail),
+			Destination:    aws.String(maskEmail(u.attrs["email"])),
+		},
+	}, nil
+}
+
+// ConfirmSignUp confirms a user with the code they were sent
+func (l *Local) ConfirmSignUp(in *cip.ConfirmSignUpInput) (*cip.ConfirmSignUpOutput, error) {
+	username := aws.StringValue(in.Username)
+	if err := l.checkClient(in.ClientId, username, in.SecretHash); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, ok := l.users[username]
+	if !ok {
+		return nil, localError(cip.ErrCodeUserNotFoundException, "Username/client id combination not found.")
+	}
+
+	if u.confirmed {
+		return nil, localError(cip.ErrCodeNotAuthorizedException, "User cannot be confirmed. Current status is CONFIRMED")
+	}
+
+	if !hmac.Equal([]byte(u.code), []byte(aws.StringValue(in.ConfirmationCode))) {
+		return nil, localError(cip.ErrCodeCodeMismatchException, "Invalid verification code provided, please try again.")
+	}
+
+	if !l.now().Before(u.codeUntil) {
+		return nil, localError(cip.ErrCodeExpiredCodeException, "Invalid code provided, please request a code again.")
+	}
+
+	u.confirmed, u.code = true, ""
+	u.modified = l.now()
+
+	return &cip.ConfirmSignUpOutput{}, nil
+}
+
+// InitiateAuth logs in with USER_PASSWORD_AUTH or trades a refresh token
+// for a new access token with REFRESH_TOKEN_AUTH
+func (l *Local) InitiateAuth(in *cip.InitiateAuthInput) (*cip.InitiateAuthOutput, error) {
+	params := aws.StringValueMap(in.AuthParameters)
+
+	switch aws.StringValue(in.AuthFlow) {
+	case cip.AuthFlowTypeUserPasswordAuth:
+		return l.passwordAuth(in.ClientId, params)
+	case cip.AuthFlowTypeRefreshToken, cip.AuthFlowTypeRefreshTokenAuth:
+		return l.refreshAuth(in.ClientId, params)
+	}
+
+	return nil, localError(cip.ErrCodeInvalidParameterException, "Initiate Auth method not supported.")
+}
+
+// GetUser gets the user an access token belongs to
+func (l *Local) GetUser(in *cip.GetUserInput) (*cip.GetUserOutput, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, err := l.authorize(aws.StringValue(in.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cip.GetUserOutput{
+		Username:       aws.String(u.username),
+		UserAttributes: u.attributes(),
+	}, nil
+}
+
+// AdminGetUser gets a user by username, whatever the pool id
+func (l *Local) AdminGetUser(in *cip.AdminGetUserInput) (*cip.AdminGetUserOutput, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, ok := l.users[aws.StringValue(in.Username)]
+	if !ok {
+		return nil, localError(cip.ErrCodeUserNotFoundException, "User does not exist.")
+	}
+
+	status := cip.UserStatusTypeUnconfirmed
+	if u.confirmed {
+		status = cip.UserStatusTypeConfirmed
+	}
+
+	return &cip.AdminGetUserOutput{
+		Enabled:              aws.Bool(true),
+		Username:             aws.String(u.username),
+		UserAttributes:       u.attributes(),
+		UserCreateDate:       aws.Time(u.created),
+		UserLastModifiedDate: aws.Time(u.modified),
+		UserStatus:           aws.String(status),
+	}, nil
+}
+
+// UpdateUserAttributes sets attributes on the user an access token
+// belongs to
+func (l *Local) UpdateUserAttributes(in *cip.UpdateUserAttributesInput) (*cip.UpdateUserAttributesOutput, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, err := l.authorize(aws.StringValue(in.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range in.UserAttributes {
+		if a != nil && aws.StringValue(a.Name) == "sub" {
+			return nil, localError(cip.ErrCodeInvalidParameterException, "Cannot modify an unmodifiable attribute: sub")
+		}
+	}
+
+	for _, a := range in.UserAttributes {
+		if a == nil {
+			continue
+		}
+		u.attrs[aws.StringValue(a.Name)] = aws.StringValue(a.Value)
+	}
+	u.modified = l.now()
+
+	return &cip.UpdateUserAttributesOutput{}, nil
+}
+
+// ChangePassword changes the password of the user an access token belongs
+// to, given their current one
+func (l *Local) ChangePassword(in *cip.ChangePasswordInput) (*cip.ChangePasswordOutput, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, err := l.authorize(aws.StringValue(in.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.checkPassword(aws.StringValue(in.PreviousPassword)) {
+		return nil, localError(cip.ErrCodeNotAuthorizedException, "Incorrect username or password.")
+	}
+
+	proposed := aws.StringValue(in.ProposedPassword)
+	if err := checkPassword(proposed); err != nil {
+		return nil, err
+	}
+
+	u.salt = randomBytes(16)
+	u.password = hashPassword(u.salt, proposed)
+	u.modified = l.now()
+
+	return &cip.ChangePasswordOutput{}, nil
+}
+
+//
+// PRIVATE
+//
+
+func (l *Local) passwordAuth(clientID *string, params map[string]string) (*cip.InitiateAuthOutput, error) {
+	username := params["USERNAME"]
+	if err := l.checkClient(clientID, username, aws.String(params["SECRET_HASH"])); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// unknown users get the same answer as wrong passwords, like a pool
+	// that prevents user existence errors
+	u, ok := l.users[username]
+	if !ok || !u.checkPassword(params["PASSWORD"]) {
+		return nil, localError(cip.ErrCodeNotAuthorizedException, "Incorrect username or password.")
+	}
+
+	if !u.confirmed {
+		return nil, localError(cip.ErrCodeUserNotConfirmedException, "User is not confirmed.")
+	}
+
+	now := l.now()
+	refresh := randomToken()
+	l.refresh[refresh] = localToken{username, now.Add(LocalRefreshExpiry)}
+
+	out := l.authResult(username, now)
+	out.AuthenticationResult.RefreshToken = aws.String(refresh)
+
+	return out, nil
+}
+
+func (l *Local) refreshAuth(clientID *string, params map[string]string) (*cip.InitiateAuthOutput, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	t, ok := l.refresh[params["REFRESH_TOKEN"]]
+	if !ok || !now.Before(t.expires) {
+		delete(l.refresh, params["REFRESH_TOKEN"])
+		return nil, localError(cip.ErrCodeNotAuthorizedException, "Invalid Refresh Token")
+	}
+
+	if err := l.checkClient(clientID, t.username, aws.String(params["SECRET_HASH"])); err != nil {
+		return nil, err
+	}
+
+	// Cognito doesn't hand out a new refresh token on refresh
+	return l.authResult(t.username, now), nil
+}
+
+// authResult mints an access token for username. The caller holds the lock.
+func (l *Local) authResult(username string, now time.Time) *cip.InitiateAuthOutput {
+	access := randomToken()
+	l.access[access] = localToken{username, now.Add(LocalTokenExpiry)}
+
+	return &cip.InitiateAuthOutput{
+		ChallengeParameters: map[string]*string{},
+		AuthenticationResult: &cip.AuthenticationResultType{
+			AccessToken: aws.String(access),
+			ExpiresIn:   aws.Int64(int64(LocalTokenExpiry / time.Second)),
+			IdToken:     aws.String(randomToken()),
+			TokenType:   aws.String("Bearer"),
+		},
+	}
+}
+
+// authorize gets the user an access token belongs to. The caller holds
+// the lock.
+func (l *Local) authorize(token string) (*localUser, error) {
+	t, ok := l.access[token]
+	if !ok {
+		return nil, localError(cip.ErrCodeNotAuthorizedException, "Invalid Access Token")
+	}
+
+	if !l.now().Before(t.expires) {
+		delete(l.access, token)
+		return nil, localError(cip.ErrCodeNotAuthorizedException, "Access Token has expired")
+	}
+
+	u, ok := l.users[t.username]
+	if !ok {
+		return nil, localError(cip.ErrCodeUserNotFoundException, "User does not exist.")
+	}
+
+	return u, nil
+}
+
+// checkClient checks the client id and the secret hash Client computes
+func (l *Local) checkClient(clientID *string, username string, secretHash *string) error {
+	id := aws.StringValue(clientID)
+	if id != l.ClientID {
+		return localError(cip.ErrCodeResourceNotFoundException, fmt.Sprintf("User pool client %s does not exist.", id))
+	}
+
+	want := hash(username, l.ClientID, l.ClientSecret)
+	if !hmac.Equal([]byte(want), []byte(aws.StringValue(secretHash))) {
+		return localError(cip.ErrCodeNotAuthorizedException, fmt.Sprintf("Unable to verify secret hash for client %s", id))
+	}
+
+	return nil
+}
+
+func (u *localUser) checkPassword(password string) bool {
+	return hmac.Equal(u.password, hashPassword(u.salt, password))
+}
+
+// attributes are sorted by name so output is stable
+func (u *localUser) attributes() []*cip.AttributeType {
+	names := make([]string, 0, len(u.attrs))
+	for name := range u.attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]*cip.AttributeType, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, &cip.AttributeType{
+			Name:  aws.String(name),
+			Value: aws.String(u.attrs[name]),
+		})
+	}
+
+	return attrs
+}
+
+func checkPassword(password string) error {
+	if len(password) < localMinPassword {
+		return localError(cip.ErrCodeInvalidPasswordException, "Password did not conform with policy: Password not long enough")
+	}
+
+	return nil
+}
+
+// hashPassword is a salted, stretched sha256. Good enough for a pool that
+// only lives in memory.
+func hashPassword(salt []byte, password string) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), password...))
+	for i := 0; i < 10000; i++ {
+		sum = sha256.Sum256(append(sum[:], salt...))
+	}
+
+	return sum[:]
+}
+
+func localError(code, message string) error {
+	return awserr.New(code, message, nil)
+}
+
+// maskEmail hides an email the way Cognito does, j***@e***
+func maskEmail(email string) string {
+	at := strings.Index(email, "@")
+	if at < 1 || at == len(email)-1 {
+		return ""
+	}
+
+	return email[:1] + "***@" + email[at+1:at+2] + "***"
+}
+
+func randomCode() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf("%06d", n)
+}
+
+func randomToken() string {
+	return hex.EncodeToString(randomBytes(32))
+}
+
+func randomUUID() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return b
+}
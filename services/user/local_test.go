@@ -0,0 +1,163 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLocal() (*Client, *Local, map[string]string, *time.Time) {
+	codes := map[string]string{}
+	now := time.Unix(1000, 0)
+
+	l := NewLocal("id", "secret")
+	l.Deliver = func(username, code string) { codes[username] = code }
+	l.now = func() time.Time { return now }
+
+	client := New("id", "secret")
+	client.Provider = l
+
+	return client, l, codes, &now
+}
+
+func errCode(err error) string {
+	if aerr, ok := errors.Cause(err).(awserr.Error); ok {
+		return aerr.Code()
+	}
+
+	return ""
+}
+
+func TestLocal(t *testing.T) {
+	client, _, codes, now := newTestLocal()
+
+	out, err := client.Signup("beep", "password", map[string]string{
+		"email":    "beep@example.com",
+		"name":     "Beep Boop",
+		"nickname": "beep",
+	})
+	assert.Nil(t, err)
+	assert.False(t, *out.UserConfirmed)
+	assert.Equal(t, "b***@e***", *out.CodeDeliveryDetails.Destination)
+	assert.Len(t, codes["beep"], 6)
+
+	_, err = client.Signup("beep", "password", nil)
+	assert.Equal(t, cip.ErrCodeUsernameExistsException, errCode(err))
+
+	_, err = client.Login("beep", "password")
+	assert.Equal(t, cip.ErrCodeUserNotConfirmedException, errCode(err))
+
+	_, err = client.Verify("beep", "nope")
+	assert.Equal(t, cip.ErrCodeCodeMismatchException, errCode(err))
+
+	_, err = client.Verify("beep", codes["beep"])
+	assert.Nil(t, err)
+
+	_, err = client.Verify("beep", codes["beep"])
+	assert.Equal(t, cip.ErrCodeNotAuthorizedException, errCode(err))
+
+	_, err = client.Login("beep", "wrong password")
+	assert.Equal(t, cip.ErrCodeNotAuthorizedException, errCode(err))
+
+	login, err := client.Login("beep", "password")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3600), *login.AuthenticationResult.ExpiresIn)
+
+	access := *login.AuthenticationResult.AccessToken
+	refresh := *login.AuthenticationResult.RefreshToken
+
+	u := client.AccountDetails(access)
+	assert.Equal(t, "beep", u.Username)
+	assert.Equal(t, "Beep Boop", u.Name)
+	assert.NotEmpty(t, u.ID)
+	assert.Equal(t, u, client.GetUser("beep"))
+
+	// access tokens expire, refresh tokens get new ones
+	*now = now.Add(time.Hour)
+	assert.Nil(t, client.AccountDetails(access))
+
+	access, err = client.GetToken("beep", refresh)
+	assert.Nil(t, err)
+	assert.Equal(t, "beep", client.AccountDetails(access).Username)
+
+	_, err = client.GetToken("beep", "nope")
+	assert.Equal(t, cip.ErrCodeNotAuthorizedException, errCode(err))
+
+	_, err = client.UpdateUser(access, map[string]string{"locale": "Bisaya"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Bisaya", client.AccountDetails(access).Location)
+
+	_, err = client.ChangePassword(access, "wrong password", "new password")
+	assert.EqualError(t, err, "Password is incorrect")
+
+	_, err = client.ChangePassword(access, "password", "short")
+	assert.EqualError(t, err, "Password must be atleast six characters")
+
+	_, err = client.ChangePassword(access, "password", "new password")
+	assert.Nil(t, err)
+
+	_, err = client.Login("beep", "password")
+	assert.Equal(t, cip.ErrCodeNotAuthorizedException, errCode(err))
+
+	_, err = client.Login("beep", "new password")
+	assert.Nil(t, err)
+}
+
+func TestLocalSignUp(t *testing.T) {
+	client, l, codes, now := newTestLocal()
+
+	t.Run("weak password", func(t *testing.T) {
+		_, err := client.Signup("beep", "short", nil)
+		assert.Equal(t, cip.ErrCodeInvalidPasswordException, errCode(err))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		other := New("id", "other secret")
+		other.Provider = l
+
+		_, err := other.Signup("beep", "password", nil)
+		assert.Equal(t, cip.ErrCodeNotAuthorizedException, errCode(err))
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		_, err := client.Signup("boop", "password", nil)
+		assert.Nil(t, err)
+
+		*now = now.Add(LocalCodeExpiry)
+		_, err = client.Verify("boop", codes["boop"])
+		assert.Equal(t, cip.ErrCodeExpiredCodeException, errCode(err))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := client.Verify("nobody", "123456")
+		assert.Equal(t, cip.ErrCodeUserNotFoundException, errCode(err))
+
+		_, err = client.Login("nobody", "password")
+		assert.Equal(t, cip.ErrCodeNotAuthorizedException, errCode(err))
+
+		assert.Nil(t, client.GetUser("nobody"))
+	})
+
+	t.Run("sub can't change", func(t *testing.T) {
+		_, err := client.Signup("bop", "password", map[string]string{"sub": "mine"})
+		assert.Nil(t, err)
+		_, _ = client.Verify("bop", codes["bop"])
+
+		login, _ := client.Login("bop", "password")
+		access := *login.AuthenticationResult.AccessToken
+		assert.NotEqual(t, "mine", client.AccountDetails(access).ID)
+
+		_, err = l.UpdateUserAttributes(&cip.UpdateUserAttributesInput{
+			AccessToken: aws.String(access),
+			UserAttributes: []*cip.AttributeType{
+				{Name: aws.String("sub"), Value: aws.String("mine")},
+			},
+		})
+		assert.Equal(t, cip.ErrCodeInvalidParameterException, errCode(err))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"log"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -84,6 +85,13 @@ func (c *Client) GetToken(
 	username,
 	token string,
 ) (string, error) {
+	accessToken, _, err := c.refresh(username, token)
+	return accessToken, err
+}
+
+// refresh trades the refresh token for an access token, along with how
+// long the access token is good for
+func (c *Client) refresh(username, token string) (string, time.Duration, error) {
 	input := &cip.InitiateAuthInput{}
 
 	input.SetClientId(c.ClientID)
@@ -97,10 +105,15 @@ func (c *Client) GetToken(
 
 	out, err := c.Provider.InitiateAuth(input)
 	if err != nil {
-		return "", errors.Wrap(err, "InitiateAuth")
+		return "", 0, errors.Wrap(err, "InitiateAuth")
+	}
+
+	expires := defaultTokenExpiry
+	if out.AuthenticationResult.ExpiresIn != nil {
+		expires = time.Duration(*out.AuthenticationResult.ExpiresIn) * time.Second
 	}
 
-	return *out.AuthenticationResult.AccessToken, nil
+	return *out.AuthenticationResult.AccessToken, expires, nil
 }
 
 // AccountDetails ...
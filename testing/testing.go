@@ -81,6 +81,62 @@ func (p *DynamoProviderMock) Query(
 	return resp.(*dynamodb.QueryOutput), args.Error(1)
 }
 
+// Scan ...
+func (p *DynamoProviderMock) Scan(
+	input *dynamodb.ScanInput,
+) (*dynamodb.ScanOutput, error) {
+	args := p.Called(input)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*dynamodb.ScanOutput), args.Error(1)
+}
+
+// GetItem ...
+func (p *DynamoProviderMock) GetItem(
+	input *dynamodb.GetItemInput,
+) (*dynamodb.GetItemOutput, error) {
+	args := p.Called(input)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+// BatchGetItem ...
+func (p *DynamoProviderMock) BatchGetItem(
+	input *dynamodb.BatchGetItemInput,
+) (*dynamodb.BatchGetItemOutput, error) {
+	args := p.Called(input)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*dynamodb.BatchGetItemOutput), args.Error(1)
+}
+
+// TransactWriteItems ...
+func (p *DynamoProviderMock) TransactWriteItems(
+	input *dynamodb.TransactWriteItemsInput,
+) (*dynamodb.TransactWriteItemsOutput, error) {
+	args := p.Called(input)
+
+	resp := args.Get(0)
+	if resp == nil {
+		return nil, args.Error(1)
+	}
+
+	return resp.(*dynamodb.TransactWriteItemsOutput), args.Error(1)
+}
+
 // DescribeTable ...
 func (p *DynamoProviderMock) DescribeTable(
 	input *dynamodb.DescribeTableInput,
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bishack.dev/utils/minify"
+)
+
+// AssetCacheAge is how long browsers keep fingerprinted assets. Their url
+// changes with their content, so this is as long as it gets.
+const AssetCacheAge = 365 * 24 * time.Hour
+
+// assetDir is where built assets go. It's under public so up serves them as
+// static files, immutable like everything under /assets/.
+var assetDir = "public/assets"
+
+// assetManifestFile maps asset names to their built files. It changes with
+// every build so it stays out of public, where it would be cached for good.
+var assetManifestFile = "assets/manifest.json"
+
+// assets are the stylesheets and scripts served under /assets/, by name
+var assets = map[string]func() ([]byte, error){
+	"main.css":      readFile("assets/css/main.css"),
+	"highlight.css": func() ([]byte, error) { return []byte(HighlightCSS()), nil },
+	"axios.js":      readFile("assets/scripts/axios.js"),
+	"main.js":       readFile("assets/scripts/main.js"),
+	"turbolinks.js": readFile("assets/scripts/turbolinks.js"),
+}
+
+// manifest maps asset names to their fingerprinted files, it's empty until
+// assets are built
+var manifest = struct {
+	sync.RWMutex
+	files map[string]string
+}{}
+
+// AssetPath is the url of an asset, fingerprinted once built. In dev, or
+// without a build, it's the plain name which is served from source.
+func AssetPath(name string) string {
+	templates.RLock()
+	dev := templates.dev
+	templates.RUnlock()
+
+	if file, ok := assetManifest()[name]; ok && !dev {
+		return "/assets/" + file
+	}
+
+	return "/assets/" + name
+}
+
+// ReadAsset reads a file served under /assets/. Fingerprinted files never
+// change and say so, plain names are read from source.
+func ReadAsset(file string) (content []byte, immutable bool, err error) {
+	for _, f := range assetManifest() {
+		if f == file {
+			content, err = ioutil.ReadFile(filepath.Join(assetDir, f))
+			return content, true, err
+		}
+	}
+
+	read, ok := assets[file]
+	if !ok {
+		return nil, false, os.ErrNotExist
+	}
+
+	content, err = read()
+	return content, false, err
+}
+
+// BuildAssets minifies every asset into dir, naming each after a hash of
+// its content, and writes the manifest mapping names to those files
+func BuildAssets(dir string) (map[string]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+	for name, read := range assets {
+		src, err := read()
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %v", name, err)
+		}
+
+		out, err := minifyAsset(name, src)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %v", name, err)
+		}
+
+		// 12 hex characters of the hash are plenty
+		sum := sha256.Sum256(out)
+		ext := filepath.Ext(name)
+		file := fmt.Sprintf("%s.%x%s", strings.TrimSuffix(name, ext), sum[:6], ext)
+
+		if err := ioutil.WriteFile(filepath.Join(dir, file), out, 0644); err != nil {
+			return nil, err
+		}
+		files[name] = file
+	}
+
+	b, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(assetManifestFile, b, 0644); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func minifyAsset(name string, src []byte) ([]byte, error) {
+	switch filepath.Ext(name) {
+	case ".css":
+		return minify.CSS(src)
+	case ".js":
+		return minify.JS(src)
+	}
+
+	return src, nil
+}
+
+// assetManifest reads the manifest the first time it's needed
+func assetManifest() map[string]string {
+	manifest.RLock()
+	files := manifest.files
+	manifest.RUnlock()
+
+	if files != nil {
+		return files
+	}
+
+	files = map[string]string{}
+	if b, err := ioutil.ReadFile(assetManifestFile); err == nil {
+		_ = json.Unmarshal(b, &files)
+	}
+
+	manifest.Lock()
+	manifest.files = files
+	manifest.Unlock()
+
+	return files
+}
+
+func readFile(path string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return ioutil.ReadFile(path)
+	}
+}
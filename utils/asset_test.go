@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "assets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	prev, prevManifest := assetDir, assetManifestFile
+	assetDir, assetManifestFile = dir, filepath.Join(dir, "manifest.json")
+	manifest.files = nil
+	defer func() {
+		assetDir, assetManifestFile = prev, prevManifest
+		manifest.files = nil
+	}()
+
+	t.Run("before a build", func(t *testing.T) {
+		assert.Equal(t, "/assets/main.css", AssetPath("main.css"))
+
+		b, immutable, err := ReadAsset("main.css")
+		assert.Nil(t, err)
+		assert.False(t, immutable)
+		assert.Regexp(t, regexp.MustCompile(`\.container \.article`), string(b))
+
+		_, _, err = ReadAsset("../../go.mod")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	files, err := BuildAssets(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, len(assets))
+	manifest.files = nil
+
+	t.Run("built", func(t *testing.T) {
+		assert.Regexp(t, regexp.MustCompile(`^main\.[0-9a-f]{12}\.css$`), files["main.css"])
+		assert.Equal(t, "/assets/"+files["main.css"], AssetPath("main.css"))
+
+		b, immutable, err := ReadAsset(files["main.js"])
+		assert.Nil(t, err)
+		assert.True(t, immutable)
+
+		src, _ := ioutil.ReadFile("assets/scripts/main.js")
+		assert.True(t, len(b) < len(src))
+
+		_, err = os.Stat(assetManifestFile)
+		assert.Nil(t, err)
+
+		// the manifest changes with every build so it isn't served
+		_, _, err = ReadAsset("manifest.json")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("dev", func(t *testing.T) {
+		templates.dev = true
+		defer func() { templates.dev = false }()
+
+		assert.Equal(t, "/assets/main.css", AssetPath("main.css"))
+	})
+}
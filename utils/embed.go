@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// EmbedSandbox is what embedded iframes are allowed to do. It's forced on
+// every iframe by the sanitizer, whatever the markup says.
+const EmbedSandbox = "allow-scripts allow-same-origin allow-popups allow-presentation"
+
+var (
+	rxShortcode = regexp.MustCompile(`^\{%\s*([a-z]+)\s+(\S+)\s*%\}$`)
+	rxYoutubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	rxGist      = regexp.MustCompile(`^(?:https://gist\.github\.com/)?([A-Za-z0-9-]+)/([0-9a-f]+)/?$`)
+	rxCodepen   = regexp.MustCompile(`^https://codepen\.io/([A-Za-z0-9_-]+)/(?:pen|embed)/([A-Za-z0-9]+)/?$`)
+
+	// rxEmbedSrc are the only urls an iframe may point to
+	rxEmbedSrc = regexp.MustCompile(`^https://(www\.youtube-nocookie\.com/embed/[A-Za-z0-9_-]{11}|gist\.github\.com/[A-Za-z0-9-]+/[0-9a-f]+\.pibb|codepen\.io/[A-Za-z0-9_-]+/embed/[A-Za-z0-9]+\?default-tab=result)$`)
+)
+
+type embedder struct {
+	title string
+	src   func(arg string) (string, bool)
+}
+
+// embedders are the providers `{% name arg %}` shortcodes can embed
+var embedders = map[string]embedder{
+	"youtube": {"YouTube video", youtubeSrc},
+	"gist":    {"GitHub gist", gistSrc},
+	"codepen": {"CodePen", codepenSrc},
+}
+
+// embed renders a `{% youtube id %}`, `{% gist url %}` or `{% codepen url %}`
+// shortcode into a sandboxed iframe
+func embed(shortcode string) (string, bool) {
+	m := rxShortcode.FindStringSubmatch(strings.TrimSpace(shortcode))
+	if m == nil {
+		return "", false
+	}
+
+	e, ok := embedders[m[1]]
+	if !ok {
+		return "", false
+	}
+
+	src, ok := e.src(m[2])
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf(
+		`<div class="embed embed-%s"><iframe src="%s" title="%s" sandbox="%s" loading="lazy" allowfullscreen></iframe></div>`+"\n",
+		m[1],
+		html.EscapeString(src),
+		e.title,
+		EmbedSandbox,
+	), true
+}
+
+// youtubeSrc takes a video id or a youtube.com / youtu.be link
+func youtubeSrc(arg string) (string, bool) {
+	id := arg
+	if u, err := url.Parse(arg); err == nil && u.Host != "" {
+		switch strings.TrimPrefix(u.Host, "www.") {
+		case "youtube.com", "m.youtube.com":
+			id = u.Query().Get("v")
+		case "youtu.be":
+			id = strings.Trim(u.Path, "/")
+		default:
+			return "", false
+		}
+	}
+
+	if !rxYoutubeID.MatchString(id) {
+		return "", false
+	}
+
+	return "https://www.youtube-nocookie.com/embed/" + id, true
+}
+
+// gistSrc takes a gist link or `user/id`. The .pibb page renders the gist
+// without the script tag gist embeds usually need.
+func gistSrc(arg string) (string, bool) {
+	m := rxGist.FindStringSubmatch(arg)
+	if m == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://gist.github.com/%s/%s.pibb", m[1], m[2]), true
+}
+
+func codepenSrc(arg string) (string, bool) {
+	m := rxCodepen.FindStringSubmatch(arg)
+	if m == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("https://codepen.io/%s/embed/%s?default-tab=result", m[1], m[2]), true
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbed(t *testing.T) {
+	for in, src := range map[string]string{
+		"{% youtube dQw4w9WgXcQ %}":                                       "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ",
+		"{% youtube https://www.youtube.com/watch?v=dQw4w9WgXcQ %}":       "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ",
+		"{%youtube https://youtu.be/dQw4w9WgXcQ%}":                        "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ",
+		"{% gist https://gist.github.com/octocat/6cad326836d38bd3a7ae %}": "https://gist.github.com/octocat/6cad326836d38bd3a7ae.pibb",
+		"{% gist octocat/6cad326836d38bd3a7ae %}":                         "https://gist.github.com/octocat/6cad326836d38bd3a7ae.pibb",
+		"{% codepen https://codepen.io/team/pen/abcXYZ %}":                "https://codepen.io/team/embed/abcXYZ?default-tab=result",
+	} {
+		out := Markdown(in)
+		assert.Contains(t, out, `<iframe src="`+src+`"`, in)
+		assert.Contains(t, out, `sandbox="`+EmbedSandbox+`"`, in)
+	}
+
+	for _, in := range []string{
+		"{% youtube short %}",
+		"{% youtube https://evil.example/watch?v=dQw4w9WgXcQ %}",
+		`{% youtube dQw4w9WgXcQ" onload="alert(1) %}`,
+		"{% gist https://gist.github.com/../x %}",
+		"{% codepen https://evil.example/team/pen/abc %}",
+		"{% vimeo 123 %}",
+		"text {% youtube dQw4w9WgXcQ %}",
+	} {
+		assert.NotContains(t, Markdown(in), "<iframe", in)
+	}
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"gitlab.com/golang-commonmark/markdown"
+)
+
+const (
+	lightStyle = "github"
+	darkStyle  = "github-dark"
+)
+
+// rxFenceInfo splits a fence info string like `go {1,3-5}` into the
+// language and the lines to highlight
+var rxFenceInfo = regexp.MustCompile(`^([^\s{]+)\s*(?:\{([0-9,\-\s]*)\})?`)
+
+var (
+	highlightOnce sync.Once
+	highlightCSS  string
+)
+
+// highlight swaps fenced code blocks with a known language for the html
+// chroma renders for them. Everything else is left to the markdown renderer.
+func highlight(tokens []markdown.Token) []markdown.Token {
+	for i, t := range tokens {
+		f, ok := t.(*markdown.Fence)
+		if !ok {
+			continue
+		}
+
+		if out, ok := highlightFence(f.Params, f.Content); ok {
+			tokens[i] = &markdown.HTMLBlock{Content: out}
+		}
+	}
+
+	return tokens
+}
+
+func highlightFence(info, code string) (string, bool) {
+	m := rxFenceInfo.FindStringSubmatch(strings.TrimSpace(info))
+	if m == nil {
+		return "", false
+	}
+
+	lexer := lexers.Get(m[1])
+	if lexer == nil {
+		return "", false
+	}
+
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", false
+	}
+
+	f := formatter(chromahtml.HighlightLines(lineRanges(m[2])))
+
+	var buf bytes.Buffer
+	if err := f.Format(&buf, styles.Get(lightStyle), it); err != nil {
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+// lineRanges parses `1,3-5` into [[1 1] [3 5]], skipping anything malformed
+func lineRanges(s string) [][2]int {
+	var ranges [][2]int
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+
+		start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil || start < 1 {
+			continue
+		}
+
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err != nil || end < start {
+				continue
+			}
+		}
+
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	return ranges
+}
+
+func formatter(opts ...chromahtml.Option) *chromahtml.Formatter {
+	return chromahtml.New(append([]chromahtml.Option{
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(true),
+	}, opts...)...)
+}
+
+// HighlightCSS is the stylesheet for highlighted code: the light theme by
+// default and the dark one when the reader prefers a dark color scheme.
+func HighlightCSS() template.CSS {
+	highlightOnce.Do(func() {
+		f := formatter()
+
+		var buf bytes.Buffer
+		_ = f.WriteCSS(&buf, styles.Get(lightStyle))
+		buf.WriteString("@media (prefers-color-scheme: dark) {\n")
+		_ = f.WriteCSS(&buf, styles.Get(darkStyle))
+		buf.WriteString("}\n")
+
+		highlightCSS = buf.String()
+	})
+
+	return template.CSS(highlightCSS)
+}
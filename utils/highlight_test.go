@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlight(t *testing.T) {
+	t.Run("known language", func(t *testing.T) {
+		out := Markdown("```go\npackage main\n```")
+		assert.Contains(t, out, `<pre class="chroma">`)
+		assert.Contains(t, out, `<span class="kn">package</span>`)
+		assert.Contains(t, out, `<span class="ln">1</span>`)
+	})
+
+	t.Run("highlighted lines", func(t *testing.T) {
+		out := Markdown("```go {2-3}\na := 1\nb := 2\nc := 3\nd := 4\n```")
+		assert.Equal(t, 2, strings.Count(out, `<span class="line hl">`))
+		assert.Contains(t, out, `<span class="line hl"><span class="ln">2</span>`)
+		assert.Contains(t, out, `<span class="line hl"><span class="ln">3</span>`)
+	})
+
+	t.Run("unknown language", func(t *testing.T) {
+		out := Markdown("```nope\n<b>x</b>\n```")
+		assert.Equal(t, "<pre><code class=\"language-nope\">&lt;b&gt;x&lt;/b&gt;\n</code></pre>\n", out)
+	})
+
+	t.Run("escapes code", func(t *testing.T) {
+		out := Markdown("```html\n<script>alert(1)</script>\n```")
+		assert.NotContains(t, out, "<script>")
+		assert.Contains(t, out, "&lt;")
+	})
+}
+
+func TestLineRanges(t *testing.T) {
+	assert.Equal(t, [][2]int{{1, 1}, {3, 5}}, lineRanges("1, 3-5"))
+	assert.Equal(t, [][2]int{{2, 2}}, lineRanges("x,2,5-3,0"))
+	assert.Nil(t, lineRanges(""))
+}
+
+func TestHighlightCSS(t *testing.T) {
+	css := string(HighlightCSS())
+	assert.Contains(t, css, ".chroma")
+	assert.Contains(t, css, "@media (prefers-color-scheme: dark)")
+}
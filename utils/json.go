@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WriteJSON encodes v as the response body with the given status
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WriteAPIError writes the error body every JSON API response shares, e.g.
+// `{"error": {"status": 404, "code": "not_found", "message": "..."}}`
+func WriteAPIError(w http.ResponseWriter, status int, code, message string) {
+	WriteJSON(w, status, &apiError{apiErrorBody{status, code, message}})
+}
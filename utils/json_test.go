@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAPIError(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteAPIError(w, http.StatusNotFound, "not_found", "Post not found")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("content-type"))
+
+	var body map[string]map[string]interface{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, map[string]interface{}{
+		"status":  float64(http.StatusNotFound),
+		"code":    "not_found",
+		"message": "Post not found",
+	}, body["error"])
+}
@@ -0,0 +1,397 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"gitlab.com/golang-commonmark/markdown"
+)
+
+// tocDirective is replaced with a table of contents of the post's headings
+const tocDirective = "[[toc]]"
+
+var (
+	rxSlug        = regexp.MustCompile(`[^a-z0-9]+`)
+	rxTask        = regexp.MustCompile(`^\[( |x|X)\]\s+`)
+	rxFence       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	rxFootnote    = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	rxFootnoteDef = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:\s?(.*)$`)
+)
+
+type heading struct {
+	level int
+	id    string
+	text  string
+}
+
+// render turns markdown into html, layering heading anchors, `[[toc]]`,
+// task lists, embeds, footnotes and syntax highlighting over commonmark
+func render(input string) string {
+	src, notes := footnoteDefs(input)
+
+	md := markdown.New(markdown.Linkify(false))
+	tokens, hs := headings(md.Parse([]byte(src)))
+	tokens = directives(tokens, hs)
+	tokens = taskLists(tokens)
+	tokens, used := footnoteRefs(tokens, notes)
+	tokens = highlight(tokens)
+
+	return md.RenderTokensToString(tokens) + footnotes(md, notes, used)
+}
+
+// IsDirective tells if a paragraph is a `[[toc]]` or an embed shortcode,
+// which render to something else than their text
+func IsDirective(content string) bool {
+	content = strings.TrimSpace(content)
+	if strings.EqualFold(content, tocDirective) {
+		return true
+	}
+
+	_, ok := embed(content)
+	return ok
+}
+
+// headings gives every heading an id and an anchor link to itself
+func headings(tokens []markdown.Token) ([]markdown.Token, []heading) {
+	var hs []heading
+	seen := map[string]int{}
+
+	for i, t := range tokens {
+		h, ok := t.(*markdown.HeadingOpen)
+		if !ok || i+1 >= len(tokens) {
+			continue
+		}
+
+		in, ok := tokens[i+1].(*markdown.Inline)
+		if !ok {
+			continue
+		}
+
+		text := plainText(in.Children)
+		id := slug(text)
+		if n := seen[id]; n > 0 {
+			seen[id]++
+			id = fmt.Sprintf("%s-%d", id, n)
+		} else {
+			seen[id] = 1
+		}
+
+		hs = append(hs, heading{level: h.HLevel, id: id, text: text})
+		tokens[i] = &markdown.HTMLBlock{
+			Content: fmt.Sprintf(`<h%d id="%s">`, h.HLevel, id),
+			Map:     h.Map,
+			Lvl:     h.Lvl,
+		}
+		in.Children = append(in.Children, &markdown.HTMLInline{
+			Content: fmt.Sprintf(` <a href="#%s" class="anchor">#</a>`, id),
+		})
+	}
+
+	return tokens, hs
+}
+
+// directives swaps paragraphs holding only `[[toc]]` or an embed shortcode
+// for the html they stand for
+func directives(tokens []markdown.Token, hs []heading) []markdown.Token {
+	out := make([]markdown.Token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		content, ok := paragraph(tokens, i)
+		if !ok {
+			out = append(out, tokens[i])
+			continue
+		}
+
+		var block string
+		if strings.EqualFold(content, tocDirective) {
+			block = toc(hs)
+		} else if block, ok = embed(content); !ok {
+			out = append(out, tokens[i])
+			continue
+		}
+
+		out = append(out, &markdown.HTMLBlock{Content: block})
+		// skip the paragraph's inline and closing tokens
+		i += 2
+	}
+
+	return out
+}
+
+// paragraph is the text of the paragraph opening at i, if it's nothing but
+// plain text
+func paragraph(tokens []markdown.Token, i int) (string, bool) {
+	if i+2 >= len(tokens) {
+		return "", false
+	}
+
+	if _, ok := tokens[i].(*markdown.ParagraphOpen); !ok {
+		return "", false
+	}
+	if _, ok := tokens[i+2].(*markdown.ParagraphClose); !ok {
+		return "", false
+	}
+
+	in, ok := tokens[i+1].(*markdown.Inline)
+	if !ok || len(in.Children) != 1 {
+		return "", false
+	}
+
+	text, ok := in.Children[0].(*markdown.Text)
+	if !ok {
+		return "", false
+	}
+
+	return strings.TrimSpace(text.Content), true
+}
+
+func toc(hs []heading) string {
+	if len(hs) == 0 {
+		return ""
+	}
+
+	base := hs[0].level
+	for _, h := range hs {
+		if h.level < base {
+			base = h.level
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav class="toc">`)
+
+	depth := 0
+	for _, h := range hs {
+		level := h.level - base + 1
+		// never skip a level, h2 followed by h4 nests the h4 once
+		if level > depth+1 {
+			level = depth + 1
+		}
+
+		switch {
+		case level > depth:
+			b.WriteString("<ul><li>")
+			depth++
+		default:
+			b.WriteString("</li>")
+			for ; depth > level; depth-- {
+				b.WriteString("</ul></li>")
+			}
+			b.WriteString("<li>")
+		}
+
+		fmt.Fprintf(&b, `<a href="#%s">%s</a>`, h.id, html.EscapeString(h.text))
+	}
+
+	for ; depth > 0; depth-- {
+		b.WriteString("</li></ul>")
+	}
+	b.WriteString("</nav>\n")
+
+	return b.String()
+}
+
+// taskLists turns list items starting with `[ ]` or `[x]` into checkboxes
+func taskLists(tokens []markdown.Token) []markdown.Token {
+	for i, t := range tokens {
+		if _, ok := t.(*markdown.ListItemOpen); !ok || i+2 >= len(tokens) {
+			continue
+		}
+		if _, ok := tokens[i+1].(*markdown.ParagraphOpen); !ok {
+			continue
+		}
+
+		in, ok := tokens[i+2].(*markdown.Inline)
+		if !ok || len(in.Children) == 0 {
+			continue
+		}
+
+		text, ok := in.Children[0].(*markdown.Text)
+		if !ok {
+			continue
+		}
+
+		m := rxTask.FindStringSubmatch(text.Content)
+		if m == nil {
+			continue
+		}
+
+		box := `<input type="checkbox" disabled> `
+		if m[1] != " " {
+			box = `<input type="checkbox" checked disabled> `
+		}
+
+		text.Content = text.Content[len(m[0]):]
+		in.Children = append([]markdown.Token{&markdown.HTMLInline{Content: box}}, in.Children...)
+		tokens[i] = &markdown.HTMLBlock{Content: `<li class="task-list-item">`}
+	}
+
+	return tokens
+}
+
+// footnoteDefs pulls `[^label]: note` definitions out of the source. Lines
+// indented under a definition continue it. Fenced code is left alone.
+func footnoteDefs(input string) (string, map[string]string) {
+	notes := map[string]string{}
+
+	var (
+		out   []string
+		fence string
+		label string
+	)
+	for _, line := range strings.Split(input, "\n") {
+		if m := rxFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case strings.HasPrefix(m[1], fence):
+				fence = ""
+			}
+		}
+
+		if fence != "" {
+			label = ""
+			out = append(out, line)
+			continue
+		}
+
+		if m := rxFootnoteDef.FindStringSubmatch(line); m != nil {
+			label = m[1]
+			if _, ok := notes[label]; !ok {
+				notes[label] = m[2]
+			}
+			continue
+		}
+
+		if label != "" && strings.TrimSpace(line) != "" &&
+			(strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t")) {
+			notes[label] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		label = ""
+		out = append(out, line)
+	}
+
+	return strings.Join(out, "\n"), notes
+}
+
+// footnoteRefs links `[^label]` references to their notes and numbers them
+// in the order they're first used, which it returns
+func footnoteRefs(tokens []markdown.Token, notes map[string]string) ([]markdown.Token, []string) {
+	if len(notes) == 0 {
+		return tokens, nil
+	}
+
+	var used []string
+	number := map[string]int{}
+
+	for _, t := range tokens {
+		in, ok := t.(*markdown.Inline)
+		if !ok {
+			continue
+		}
+
+		var children []markdown.Token
+		links := 0
+		for _, c := range in.Children {
+			switch c.(type) {
+			case *markdown.LinkOpen:
+				links++
+			case *markdown.LinkClose:
+				links--
+			}
+
+			text, ok := c.(*markdown.Text)
+			if !ok || links > 0 {
+				children = append(children, c)
+				continue
+			}
+
+			last := 0
+			for _, m := range rxFootnote.FindAllStringSubmatchIndex(text.Content, -1) {
+				label := text.Content[m[2]:m[3]]
+				if _, ok := notes[label]; !ok {
+					continue
+				}
+
+				id := ""
+				n, ok := number[label]
+				if !ok {
+					used = append(used, label)
+					n = len(used)
+					number[label] = n
+					id = fmt.Sprintf(` id="fnref-%d"`, n)
+				}
+
+				if m[0] > last {
+					children = append(children, &markdown.Text{Content: text.Content[last:m[0]]})
+				}
+				children = append(children, &markdown.HTMLInline{
+					Content: fmt.Sprintf(`<sup class="footnote-ref"><a href="#fn-%d"%s>%d</a></sup>`, n, id, n),
+				})
+				last = m[1]
+			}
+
+			if last < len(text.Content) {
+				children = append(children, &markdown.Text{Content: text.Content[last:]})
+			}
+		}
+		in.Children = children
+	}
+
+	return tokens, used
+}
+
+// footnotes renders the notes that were referenced, in order
+func footnotes(md *markdown.Markdown, notes map[string]string, used []string) string {
+	if len(used) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("<section class=\"footnotes\">\n<hr>\n<ol>\n")
+	for i, label := range used {
+		n := i + 1
+		note := strings.TrimSuffix(md.RenderToString([]byte(notes[label])), "\n")
+		back := fmt.Sprintf(` <a href="#fnref-%d" class="footnote-backref">↩</a>`, n)
+
+		// keep the back link on the note's last line
+		if strings.HasSuffix(note, "</p>") {
+			note = strings.TrimSuffix(note, "</p>") + back + "</p>"
+		} else {
+			note += back
+		}
+
+		fmt.Fprintf(&b, "<li id=\"fn-%d\">%s</li>\n", n, note)
+	}
+	b.WriteString("</ol>\n</section>\n")
+
+	return b.String()
+}
+
+func plainText(tokens []markdown.Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		switch t := t.(type) {
+		case *markdown.Text:
+			b.WriteString(t.Content)
+		case *markdown.CodeInline:
+			b.WriteString(t.Content)
+		case *markdown.Softbreak, *markdown.Hardbreak:
+			b.WriteString(" ")
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func slug(text string) string {
+	s := strings.Trim(rxSlug.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if s == "" {
+		return "section"
+	}
+
+	return s
+}
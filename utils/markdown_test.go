@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadings(t *testing.T) {
+	out := Markdown("# Hello, World!\n\n## Setup `go`\n\n## Setup go")
+	assert.Contains(t, out, `<h1 id="hello-world">Hello, World! <a href="#hello-world" class="anchor">#</a></h1>`)
+	assert.Contains(t, out, `<h2 id="setup-go">`)
+	assert.Contains(t, out, `<h2 id="setup-go-1">`)
+}
+
+func TestTOC(t *testing.T) {
+	t.Run("nested", func(t *testing.T) {
+		out := Markdown("[[toc]]\n\n## One\n\n#### Deep\n\n## Two <b>")
+		assert.Contains(
+			t,
+			out,
+			`<nav class="toc"><ul><li><a href="#one">One</a><ul><li><a href="#deep">Deep</a></li></ul></li><li><a href="#two-b">Two &lt;b&gt;</a></li></ul></nav>`,
+		)
+	})
+
+	t.Run("no headings", func(t *testing.T) {
+		assert.Equal(t, "<p>x</p>\n", Markdown("[[toc]]\n\nx"))
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		assert.Equal(t, "<p>see [[toc]]</p>\n", Markdown("see [[toc]]"))
+	})
+}
+
+func TestTaskLists(t *testing.T) {
+	out := Markdown("- [ ] todo\n- [x] done\n- [y] plain")
+	assert.Contains(t, out, `<li class="task-list-item"><input type="checkbox" disabled> todo</li>`)
+	assert.Contains(t, out, `<li class="task-list-item"><input type="checkbox" checked="" disabled> done</li>`)
+	assert.Contains(t, out, `<li>[y] plain</li>`)
+}
+
+func TestFootnotes(t *testing.T) {
+	t.Run("referenced", func(t *testing.T) {
+		out := Markdown("a[^b] c[^a] d[^b]\n\n[^a]: *first*\n  more\n[^b]: second\n[^c]: unused\n")
+		assert.Contains(t, out, `<p>a<sup class="footnote-ref"><a href="#fn-1" id="fnref-1">1</a></sup>`)
+		assert.Contains(t, out, `c<sup class="footnote-ref"><a href="#fn-2" id="fnref-2">2</a></sup>`)
+		assert.Contains(t, out, `d<sup class="footnote-ref"><a href="#fn-1">1</a></sup>`)
+		assert.Contains(t, out, `<li id="fn-1"><p>second <a href="#fnref-1" class="footnote-backref">↩</a></p></li>`)
+		assert.Contains(t, out, "<li id=\"fn-2\"><p><em>first</em>\nmore <a href=\"#fnref-2\" class=\"footnote-backref\">↩</a></p></li>")
+		assert.NotContains(t, out, "unused")
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		assert.Equal(t, "<p>x[^1]</p>\n", Markdown("x[^1]"))
+	})
+
+	t.Run("in code", func(t *testing.T) {
+		out := Markdown("`x[^1]`\n\n```\n[^1]: code\n```\n\n[^1]: note")
+		assert.Contains(t, out, "<code>x[^1]</code>")
+		assert.Contains(t, out, "[^1]: code")
+		assert.False(t, strings.Contains(out, "footnotes"))
+	})
+}
+
+func TestFootnoteDefs(t *testing.T) {
+	src, notes := footnoteDefs("x\n\n[^a]: one\n\ty\n\nz")
+	assert.Equal(t, "x\n\n\nz", src)
+	assert.Equal(t, map[string]string{"a": "one\ny"}, notes)
+}
+
+func TestIsDirective(t *testing.T) {
+	assert.True(t, IsDirective("[[TOC]]"))
+	assert.True(t, IsDirective("{% youtube dQw4w9WgXcQ %}"))
+	assert.False(t, IsDirective("{% vimeo 123 %}"))
+	assert.False(t, IsDirective("hello"))
+}
@@ -0,0 +1,170 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bishack.dev/services/post"
+	"bishack.dev/utils"
+	"gitlab.com/golang-commonmark/markdown"
+)
+
+// ExcerptLength is the most runes an excerpt can have. It's about what
+// search engines and link previews show before cutting it themselves.
+const ExcerptLength = 160
+
+// SiteName ...
+const SiteName = "bishack.dev"
+
+// Meta is what link previews and search engines read off a page
+type Meta struct {
+	Title       string
+	Description string
+	Canonical   string
+	Image       string
+	Type        string
+	Card        string
+	Author      string
+	Published   string
+	Modified    string
+	Tags        []string
+
+	// JSONLD is the schema.org description of the page, ready to go
+	// inside a <script type="application/ld+json"> tag
+	JSONLD template.JS
+}
+
+// Post builds the metadata of a post. base is the scheme and host the site
+// is served from.
+func Post(p *post.Post, base string) *Meta {
+	canonical := fmt.Sprintf("%s/%s/%s", base, p.Username, p.ID)
+
+	modified := p.Updated
+	if modified < p.Created {
+		modified = p.Created
+	}
+
+	m := &Meta{
+		Title:       p.Title,
+		Description: Excerpt(p.Content, ExcerptLength),
+		Canonical:   canonical,
+		Image:       absolute(p.Cover, base),
+		Type:        "article",
+		Card:        "summary_large_image",
+		Author:      p.Author,
+		Published:   timestamp(p.Created),
+		Modified:    timestamp(modified),
+		Tags:        p.Tags,
+	}
+
+	// posts without a cover get a generated image. The version busts the
+	// caches of link previews whenever the post changes.
+	if m.Image == "" {
+		m.Image = fmt.Sprintf("%s/og.png?v=%d", canonical, modified)
+	}
+
+	ld := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         m.Title,
+		"description":      m.Description,
+		"url":              canonical,
+		"mainEntityOfPage": canonical,
+		"datePublished":    m.Published,
+		"dateModified":     m.Modified,
+		"image":            m.Image,
+		"author": map[string]interface{}{
+			"@type": "Person",
+			"name":  p.Author,
+			"url":   base + "/" + p.Username,
+		},
+		"publisher": map[string]interface{}{
+			"@type": "Organization",
+			"name":  SiteName,
+			"url":   base + "/",
+		},
+	}
+
+	if len(p.Tags) > 0 {
+		ld["keywords"] = strings.Join(p.Tags, ", ")
+	}
+
+	// json.Marshal escapes <, > and & so the content can't close the
+	// script tag it's rendered in
+	out, _ := json.Marshal(ld)
+	m.JSONLD = template.JS(out)
+
+	return m
+}
+
+// Excerpt renders the markdown and keeps the text of its paragraphs, lists
+// and quotes, leaving out headings, code blocks, images and directives. The result is
+// cut on a word boundary to at most max runes.
+func Excerpt(content string, max int) string {
+	md := markdown.New(markdown.Linkify(false))
+	tokens := md.Parse([]byte(content))
+
+	var words []string
+	heading := false
+	for _, t := range tokens {
+		switch t := t.(type) {
+		case *markdown.HeadingOpen:
+			heading = true
+		case *markdown.HeadingClose:
+			heading = false
+		case *markdown.Inline:
+			if !heading && !utils.IsDirective(t.Content) {
+				words = append(words, strings.Fields(inlineText(t.Children))...)
+			}
+		}
+	}
+
+	return truncate(strings.Join(words, " "), max)
+}
+
+func inlineText(tokens []markdown.Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		switch t := t.(type) {
+		case *markdown.Text:
+			b.WriteString(t.Content)
+		case *markdown.CodeInline:
+			b.WriteString(t.Content)
+		case *markdown.Softbreak, *markdown.Hardbreak:
+			b.WriteString(" ")
+		}
+	}
+
+	return b.String()
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	// leave room for the ellipsis
+	cut := string([]rune(s)[:max-1])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:-") + "…"
+}
+
+// absolute turns root relative urls into absolute ones, previews need them
+func absolute(src, base string) string {
+	if strings.HasPrefix(src, "/") && !strings.HasPrefix(src, "//") {
+		return base + src
+	}
+
+	return src
+}
+
+func timestamp(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,97 @@
+package meta
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"bishack.dev/services/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExcerpt(t *testing.T) {
+	t.Run("plain text", func(t *testing.T) {
+		content := "# Title\r\n\r\n" +
+			"Some **bold** and [a link](https://bishack.dev) with `code`.\r\n\r\n" +
+			"![cover](/images/cover.png)\r\n\r\n" +
+			"```go\nfmt.Println(\"hidden\")\n```\r\n\r\n" +
+			"- one\n- two\n"
+
+		assert.Equal(
+			t,
+			"Some bold and a link with code. one two",
+			Excerpt(content, ExcerptLength),
+		)
+	})
+
+	t.Run("truncated on a word", func(t *testing.T) {
+		out := Excerpt(strings.Repeat("lorem ipsum, ", 40), 30)
+		assert.Equal(t, "lorem ipsum, lorem ipsum…", out)
+	})
+
+	t.Run("multibyte", func(t *testing.T) {
+		out := Excerpt(strings.Repeat("ñ", 200), 10)
+		assert.Equal(t, 10, utf8.RuneCountInString(out))
+	})
+
+	t.Run("directives", func(t *testing.T) {
+		content := "[[toc]]\n\n{% youtube dQw4w9WgXcQ %}\n\nHello"
+		assert.Equal(t, "Hello", Excerpt(content, ExcerptLength))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "", Excerpt("", ExcerptLength))
+	})
+}
+
+func TestPost(t *testing.T) {
+	t.Run("without cover", func(t *testing.T) {
+		m := Post(&post.Post{
+			ID:       "hello-42",
+			Title:    "Hello",
+			Username: "ing",
+			Author:   "Ing",
+			Content:  "Hi there",
+			Created:  42,
+		}, "https://bishack.dev")
+
+		assert.Equal(t, "https://bishack.dev/ing/hello-42", m.Canonical)
+		assert.Equal(t, "Hi there", m.Description)
+		assert.Equal(t, "summary_large_image", m.Card)
+		assert.Equal(t, "article", m.Type)
+		assert.Equal(t, "1970-01-01T00:00:42Z", m.Modified)
+		assert.Equal(t, "https://bishack.dev/ing/hello-42/og.png?v=42", m.Image)
+		assert.Contains(t, string(m.JSONLD), `"image":"https://bishack.dev/ing/hello-42/og.png?v=42"`)
+	})
+
+	t.Run("with cover and tags", func(t *testing.T) {
+		m := Post(&post.Post{
+			ID:       "hello-42",
+			Title:    "</script><b>",
+			Username: "ing",
+			Author:   "Ing",
+			Cover:    "/images/cover.png",
+			Tags:     []string{"go", "aws"},
+			Created:  42,
+			Updated:  100,
+		}, "https://bishack.dev")
+
+		assert.Equal(t, "https://bishack.dev/images/cover.png", m.Image)
+		assert.Equal(t, "summary_large_image", m.Card)
+		assert.NotContains(t, string(m.JSONLD), "</script>")
+
+		var ld map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(m.JSONLD), &ld))
+		assert.Equal(t, "BlogPosting", ld["@type"])
+		assert.Equal(t, "</script><b>", ld["headline"])
+		assert.Equal(t, "go, aws", ld["keywords"])
+		assert.Equal(t, "1970-01-01T00:01:40Z", ld["dateModified"])
+		assert.Equal(t, "Ing", ld["author"].(map[string]interface{})["name"])
+	})
+
+	t.Run("absolute cover", func(t *testing.T) {
+		m := Post(&post.Post{Cover: "https://s3.bishack.dev/x.png"}, "https://bishack.dev")
+		assert.Equal(t, "https://s3.bishack.dev/x.png", m.Image)
+	})
+}
@@ -0,0 +1,26 @@
+// Package minify shrinks the stylesheets and scripts we serve with
+// github.com/tdewolff/minify.
+package minify
+
+import (
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+var m = minify.New()
+
+func init() {
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+}
+
+// CSS minifies a stylesheet
+func CSS(src []byte) ([]byte, error) {
+	return m.Bytes("text/css", src)
+}
+
+// JS minifies a script
+func JS(src []byte) ([]byte, error) {
+	return m.Bytes("application/javascript", src)
+}
@@ -0,0 +1,68 @@
+package minify
+
+import (
+	"io/ioutil"
+	"testing"
+
+	_ "bishack.dev/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+func TestCSS(t *testing.T) {
+	for in, out := range map[string]string{
+		"a { color : red ; }":                             "a{color:red}",
+		"/* x */\n.a :hover,\n.b > .c { margin: 0 auto }": ".a :hover,.b>.c{margin:0 auto}",
+		"a { color: #ff0000 }":                            "a{color:red}",
+	} {
+		got, err := CSS([]byte(in))
+		assert.Nil(t, err)
+		assert.Equal(t, out, string(got), in)
+	}
+}
+
+func TestJS(t *testing.T) {
+	for in, out := range map[string]string{
+		"// hi\nconst a = 1\nlet b = a\n": "const a=1;let b=a",
+		"x = a.replace( / +/g, ' ' )":     "x=a.replace(/ +/g,\" \")",
+		"a = b\n++c":                      "a=b,++c",
+		"function f(){ return\nx }":       "function f(){return;x}",
+		"/*! license */ var a = 1":        "/*! license */var a=1",
+	} {
+		got, err := JS([]byte(in))
+		assert.Nil(t, err)
+		assert.Equal(t, out, string(got), in)
+	}
+
+	_, err := JS([]byte("a = ("))
+	assert.NotNil(t, err)
+}
+
+func TestCSSAssets(t *testing.T) {
+	src, err := ioutil.ReadFile("assets/css/main.css")
+	assert.Nil(t, err)
+
+	out, err := CSS(src)
+	assert.Nil(t, err)
+	assert.True(t, len(out) < len(src))
+}
+
+// the minified scripts we ship must still parse
+func TestJSAssets(t *testing.T) {
+	for _, f := range []string{
+		"assets/scripts/axios.js",
+		"assets/scripts/main.js",
+		"assets/scripts/turbolinks.js",
+	} {
+		src, err := ioutil.ReadFile(f)
+		assert.Nil(t, err)
+
+		out, err := JS(src)
+		assert.Nil(t, err)
+		assert.True(t, len(out) < len(src), f)
+
+		_, err = js.Parse(parse.NewInputBytes(out), js.Options{})
+		assert.Nil(t, err, f)
+	}
+}
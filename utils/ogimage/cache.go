@@ -0,0 +1,49 @@
+package ogimage
+
+import "sync"
+
+// Cache keeps the most recently rendered images in memory. Keys should
+// change whenever the image would, e.g. by including the post's Updated
+// timestamp, so entries never need to be invalidated.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	keys  []string
+	items map[string][]byte
+}
+
+// NewCache creates a cache holding up to size images
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:  size,
+		items: map[string][]byte{},
+	}
+}
+
+// Get ...
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.items[key]
+	return b, ok
+}
+
+// Put adds an image, evicting the oldest one when the cache is full
+func (c *Cache) Put(key string, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[key]; ok {
+		c.items[key] = b
+		return
+	}
+
+	if len(c.keys) >= c.size {
+		delete(c.items, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+
+	c.keys = append(c.keys, key)
+	c.items[key] = b
+}
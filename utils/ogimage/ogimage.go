@@ -0,0 +1,230 @@
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	// avatars come in any of these
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Width and Height are what Facebook, Twitter and Slack recommend for
+// large previews
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	padding     = 80
+	avatarSize  = 96
+	logoSize    = 56
+	titleSize   = 64
+	titleLines  = 3
+	titleLeader = 80
+)
+
+var (
+	white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	black = color.RGBA{0x1a, 0x1a, 0x1a, 0xff}
+	gray  = color.RGBA{0x6b, 0x6b, 0x6b, 0xff}
+	blue  = color.RGBA{0x00, 0x00, 0xff, 0xff}
+	light = color.RGBA{0xf0, 0xf2, 0xff, 0xff}
+)
+
+var (
+	fontsOnce sync.Once
+	regular   *opentype.Font
+	bold      *opentype.Font
+)
+
+// Card is what goes on a share image
+type Card struct {
+	Title       string
+	Author      string
+	Username    string
+	ReadingTime int
+
+	// Avatar and Logo are optional
+	Avatar image.Image
+	Logo   image.Image
+}
+
+// Render draws the card as a PNG
+func Render(c *Card) ([]byte, error) {
+	fontsOnce.Do(func() {
+		regular, _ = opentype.Parse(goregular.TTF)
+		bold, _ = opentype.Parse(gobold.TTF)
+	})
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+
+	// brand bar along the bottom
+	draw.Draw(img, image.Rect(0, Height-16, Width, Height), image.NewUniform(blue), image.Point{}, draw.Src)
+
+	// logo
+	x := padding
+	if c.Logo != nil {
+		dst := image.Rect(padding, padding, padding+logoSize, padding+logoSize)
+		draw.CatmullRom.Scale(img, dst, c.Logo, c.Logo.Bounds(), draw.Over, nil)
+		x += logoSize + 16
+	}
+	text(img, face(bold, 36), blue, x, padding+logoSize/2+13, ".bishack()")
+
+	// title, wrapped and cut to a few lines
+	tf := face(bold, titleSize)
+	y := padding + logoSize + 60 + titleSize
+	for _, line := range wrap(tf, c.Title, Width-2*padding, titleLines) {
+		text(img, tf, black, padding, y, line)
+		y += titleLeader
+	}
+
+	// author
+	top := Height - padding - avatarSize
+	x = padding
+	if c.Avatar != nil {
+		avatar(img, c.Avatar, image.Rect(x, top, x+avatarSize, top+avatarSize))
+	} else {
+		initial(img, c.Author, image.Rect(x, top, x+avatarSize, top+avatarSize))
+	}
+	x += avatarSize + 24
+
+	text(img, face(bold, 34), black, x, top+40, c.Author)
+
+	details := "@" + c.Username
+	if c.ReadingTime > 0 {
+		details += " · " + strconv.Itoa(c.ReadingTime) + " min read"
+	}
+	text(img, face(regular, 28), gray, x, top+84, details)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func face(f *opentype.Font, size float64) font.Face {
+	fc, _ := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	return fc
+}
+
+func text(img draw.Image, f font.Face, c color.Color, x, y int, s string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: f,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// wrap breaks s into lines no wider than width. Anything past max lines is
+// cut and the last line ends with an ellipsis.
+func wrap(f font.Face, s string, width, max int) []string {
+	limit := fixed.I(width)
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+
+		if font.MeasureString(f, next) <= limit || line == "" {
+			line = next
+			continue
+		}
+
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) <= max {
+		return lines
+	}
+
+	lines = lines[:max]
+	words := strings.Fields(lines[max-1])
+	for len(words) > 1 && font.MeasureString(f, strings.Join(words, " ")+"…") > limit {
+		words = words[:len(words)-1]
+	}
+	lines[max-1] = strings.Join(words, " ") + "…"
+
+	return lines
+}
+
+// avatar draws the image cropped to a circle
+func avatar(img draw.Image, src image.Image, r image.Rectangle) {
+	scaled := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, square(src.Bounds()), draw.Src, nil)
+	draw.DrawMask(img, r, scaled, image.Point{}, &circle{r.Dx()}, image.Point{}, draw.Over)
+}
+
+// initial draws the first letter of the name on a circle, for users
+// without an avatar
+func initial(img draw.Image, name string, r image.Rectangle) {
+	draw.DrawMask(img, r, image.NewUniform(light), image.Point{}, &circle{r.Dx()}, image.Point{}, draw.Over)
+
+	letter := "?"
+	if name = strings.TrimSpace(name); name != "" {
+		letter = strings.ToUpper(string([]rune(name)[0]))
+	}
+
+	f := face(bold, 48)
+	w := font.MeasureString(f, letter).Round()
+	text(img, f, blue, r.Min.X+(r.Dx()-w)/2, r.Min.Y+r.Dy()/2+17, letter)
+}
+
+// square is the largest centered square in r
+func square(r image.Rectangle) image.Rectangle {
+	size := r.Dx()
+	if r.Dy() < size {
+		size = r.Dy()
+	}
+
+	x := r.Min.X + (r.Dx()-size)/2
+	y := r.Min.Y + (r.Dy()-size)/2
+	return image.Rect(x, y, x+size, y+size)
+}
+
+// circle is an alpha mask of a circle of the given diameter
+type circle struct {
+	d int
+}
+
+func (c *circle) ColorModel() color.Model { return color.AlphaModel }
+
+func (c *circle) Bounds() image.Rectangle { return image.Rect(0, 0, c.d, c.d) }
+
+func (c *circle) At(x, y int) color.Color {
+	r := float64(c.d) / 2
+	dx, dy := float64(x)+0.5-r, float64(y)+0.5-r
+	if dx*dx+dy*dy <= r*r {
+		return color.Alpha{0xff}
+	}
+	return color.Alpha{0}
+}
@@ -0,0 +1,86 @@
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestRender(t *testing.T) {
+	avatar := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 20; y++ {
+			avatar.Set(x, y, color.RGBA{0xff, 0, 0, 0xff})
+		}
+	}
+
+	for _, c := range []*Card{
+		{Title: "Hello", Author: "Ing", Username: "ing"},
+		{
+			Title:       strings.Repeat("A very long title ", 20),
+			Author:      "Ing",
+			Username:    "ing",
+			ReadingTime: 3,
+			Avatar:      avatar,
+			Logo:        avatar,
+		},
+		{},
+	} {
+		b, err := Render(c)
+		assert.Nil(t, err)
+
+		img, err := png.Decode(bytes.NewReader(b))
+		assert.Nil(t, err)
+		assert.Equal(t, image.Rect(0, 0, Width, Height), img.Bounds())
+	}
+}
+
+func TestWrap(t *testing.T) {
+	f, _ := opentype.Parse(goregular.TTF)
+	fc, _ := opentype.NewFace(f, &opentype.FaceOptions{Size: 20, DPI: 72})
+
+	width := font.MeasureString(fc, "aaaa bbbb").Ceil()
+
+	assert.Equal(t, []string{"aaaa bbbb", "cccc"}, wrap(fc, "aaaa bbbb cccc", width, 3))
+	assert.Nil(t, wrap(fc, "  ", width, 3))
+
+	lines := wrap(fc, "aaaa bbbb cccc dddd eeee ffff", width, 2)
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasSuffix(lines[1], "…"))
+	assert.True(t, font.MeasureString(fc, lines[1]) <= fixed.I(width))
+}
+
+func TestCircle(t *testing.T) {
+	c := &circle{10}
+	assert.Equal(t, color.Alpha{0xff}, c.At(5, 5))
+	assert.Equal(t, color.Alpha{0}, c.At(0, 0))
+}
+
+func TestCache(t *testing.T) {
+	c := NewCache(2)
+
+	c.Put("a", []byte("a"))
+	c.Put("b", []byte("b"))
+	c.Put("a", []byte("A"))
+
+	b, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("A"), b)
+
+	c.Put("c", []byte("c"))
+
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+}
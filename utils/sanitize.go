@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags maps the tags rendered markdown may contain to the
+// attributes they may keep. Anything else is unwrapped, keeping its text.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title", "id", "class"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       {"class"},
+	"del":        nil,
+	"div":        {"class"},
+	"em":         nil,
+	"h1":         {"id"},
+	"h2":         {"id"},
+	"h3":         {"id"},
+	"h4":         {"id"},
+	"h5":         {"id"},
+	"h6":         {"id"},
+	"hr":         nil,
+	"i":          nil,
+	"iframe":     {"src", "title", "allowfullscreen"},
+	"img":        {"src", "alt", "title", "width", "height"},
+	"input":      {"type", "checked"},
+	"kbd":        nil,
+	"li":         {"id", "class"},
+	"nav":        {"class"},
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        {"class"},
+	"s":          nil,
+	"section":    {"class"},
+	"span":       {"class"},
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        {"class"},
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"style"},
+	"th":         {"style"},
+	"thead":      nil,
+	"tr":         nil,
+	"ul":         nil,
+}
+
+// droppedTags are removed along with everything inside them
+var droppedTags = map[string]bool{
+	"embed":    true,
+	"frame":    true,
+	"frameset": true,
+	"noembed":  true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+}
+
+var voidTags = map[string]bool{
+	"br":    true,
+	"hr":    true,
+	"img":   true,
+	"input": true,
+}
+
+// allowedSchemes are the url schemes links and images may use. Relative
+// urls have no scheme and are always fine.
+var allowedSchemes = map[string]map[string]bool{
+	"href": {"http": true, "https": true, "mailto": true},
+	"src":  {"http": true, "https": true},
+}
+
+var (
+	rxClass   = regexp.MustCompile(`^(chroma|anchor|toc|footnotes|footnote-ref|footnote-backref|task-list-item|embed|embed-[a-z]+|language-[a-zA-Z0-9_+\-]+)$`)
+	rxToken   = regexp.MustCompile(`^[a-z0-9]{1,4}( hl)?$|^line( hl)?$`)
+	rxAlign   = regexp.MustCompile(`^text-align:\s*(left|right|center);?$`)
+	rxNumeric = regexp.MustCompile(`^[0-9]{1,4}$`)
+	rxID      = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]{0,99}$`)
+)
+
+// Sanitize keeps the tags, attributes and url schemes rendered markdown is
+// allowed to have and drops the rest. Links off the page get
+// rel="nofollow noopener" and embeds are sandboxed.
+func Sanitize(input string) string {
+	ctx := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(input), ctx)
+	if err != nil {
+		return html.EscapeString(input)
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		sanitize(&buf, n)
+	}
+
+	return buf.String()
+}
+
+func sanitize(buf *bytes.Buffer, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// comments, doctypes et al
+		return
+	}
+
+	tag := strings.ToLower(n.Data)
+	if droppedTags[tag] {
+		return
+	}
+
+	attrs, ok := allowedTags[tag]
+	if !ok {
+		sanitizeChildren(buf, n)
+		return
+	}
+
+	if !required(tag, n.Attr) {
+		return
+	}
+
+	buf.WriteString("<" + tag)
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" || !contains(attrs, key) || !validAttr(tag, key, a.Val) {
+			continue
+		}
+
+		buf.WriteString(" " + key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+	switch tag {
+	case "a":
+		if !fragment(n.Attr) {
+			buf.WriteString(` rel="nofollow noopener"`)
+		}
+	case "iframe":
+		// embeds are empty, whatever the markup put in them
+		buf.WriteString(` sandbox="` + EmbedSandbox + `" loading="lazy"></iframe>`)
+		return
+	case "input":
+		buf.WriteString(" disabled")
+	}
+	buf.WriteString(">")
+
+	if voidTags[tag] {
+		return
+	}
+
+	sanitizeChildren(buf, n)
+	buf.WriteString("</" + tag + ">")
+}
+
+func sanitizeChildren(buf *bytes.Buffer, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sanitize(buf, c)
+	}
+}
+
+func validAttr(tag, key, val string) bool {
+	switch key {
+	case "href":
+		return validURL(key, val)
+	case "src":
+		if tag == "iframe" {
+			return rxEmbedSrc.MatchString(val)
+		}
+		return validURL(key, val)
+	case "class":
+		if tag == "span" {
+			// token classes from the syntax highlighter
+			return rxToken.MatchString(val)
+		}
+		for _, c := range strings.Fields(val) {
+			if !rxClass.MatchString(c) {
+				return false
+			}
+		}
+		return val != ""
+	case "id":
+		return rxID.MatchString(val)
+	case "type":
+		return val == "checkbox"
+	case "style":
+		return rxAlign.MatchString(strings.TrimSpace(val))
+	case "width", "height", "start":
+		return rxNumeric.MatchString(val)
+	}
+
+	return true
+}
+
+// required drops iframes that don't point to an embed and inputs that
+// aren't checkboxes
+func required(tag string, attrs []html.Attribute) bool {
+	var key string
+	switch tag {
+	case "iframe":
+		key = "src"
+	case "input":
+		key = "type"
+	default:
+		return true
+	}
+
+	for _, a := range attrs {
+		if a.Namespace == "" && strings.ToLower(a.Key) == key && validAttr(tag, key, a.Val) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fragment tells if a link points somewhere on the same page, like heading
+// anchors and footnotes do
+func fragment(attrs []html.Attribute) bool {
+	for _, a := range attrs {
+		if strings.ToLower(a.Key) == "href" {
+			return strings.HasPrefix(strings.TrimSpace(a.Val), "#")
+		}
+	}
+
+	return false
+}
+
+func validURL(key, val string) bool {
+	// url.Parse rejects control characters and colons in relative paths, so
+	// obfuscated schemes like "java\tscript:" never get this far
+	u, err := url.Parse(strings.TrimSpace(val))
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "" || allowedSchemes[key][strings.ToLower(u.Scheme)]
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
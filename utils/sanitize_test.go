@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	t.Run("keeps markdown output", func(t *testing.T) {
+		for _, in := range []string{
+			"<p><strong>bold</strong> <em>em</em> <code>x</code></p>",
+			`<pre class="chroma"><code class="language-go">fmt.Println(&#34;&lt;b&gt;&#34;)` + "\n</code></pre>",
+			`<ol start="3"><li>one</li></ol>`,
+			`<table><thead><tr><th style="text-align:right">a</th></tr></thead></table>`,
+			`<img src="https://s3.bishack.dev/x.png" alt="x">`,
+			`<img src="/images/icon.png" alt="">`,
+			"<blockquote><p>quote</p></blockquote><hr><br>",
+		} {
+			assert.Equal(t, in, Sanitize(in))
+		}
+	})
+
+	t.Run("links", func(t *testing.T) {
+		assert.Equal(
+			t,
+			`<a href="https://bishack.dev" title="t" rel="nofollow noopener">x</a>`,
+			Sanitize(`<a href="https://bishack.dev" title="t" target="_blank" rel="opener">x</a>`),
+		)
+		assert.Equal(
+			t,
+			`<a href="mailto:hi@bishack.dev" rel="nofollow noopener">x</a>`,
+			Sanitize(`<a href="mailto:hi@bishack.dev">x</a>`),
+		)
+	})
+
+	t.Run("extensions", func(t *testing.T) {
+		for _, in := range []string{
+			`<h2 id="setup-go">Setup <a href="#setup-go" class="anchor">#</a></h2>`,
+			`<nav class="toc"><ul><li><a href="#intro">Intro</a></li></ul></nav>`,
+			`<sup class="footnote-ref"><a href="#fn-1" id="fnref-1">1</a></sup>`,
+			`<section class="footnotes"><ol><li id="fn-1">x</li></ol></section>`,
+			`<li class="task-list-item"><input type="checkbox" checked="" disabled> one</li>`,
+		} {
+			assert.Equal(t, in, Sanitize(in))
+		}
+	})
+
+	t.Run("embeds", func(t *testing.T) {
+		assert.Equal(
+			t,
+			`<div class="embed embed-youtube"><iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ" sandbox="`+EmbedSandbox+`" loading="lazy"></iframe></div>`,
+			Sanitize(`<div class="embed embed-youtube"><iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ" sandbox="allow-top-navigation" srcdoc="x">x</iframe></div>`),
+		)
+		assert.Equal(t, "", Sanitize(`<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ"></iframe>`))
+		assert.Equal(t, "", Sanitize(`<input type="text" value="x">`))
+		assert.Equal(t, `<input type="checkbox" disabled>`, Sanitize(`<input type="checkbox" onfocus="alert(1)" autofocus>`))
+	})
+
+	// payloads from the OWASP XSS filter evasion cheat sheet
+	payloads := []string{
+		`<script>alert(1)</script>`,
+		`<SCRIPT SRC=//xss.rocks/.j></SCRIPT>`,
+		`<img src=x onerror=alert(1)>`,
+		`<IMG SRC="javascript:alert('XSS');">`,
+		`<IMG SRC=JaVaScRiPt:alert('XSS')>`,
+		`<IMG SRC=&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;&#97;&#108;&#101;&#114;&#116;&#40;&#39;&#88;&#83;&#83;&#39;&#41;>`,
+		`<IMG SRC="jav	ascript:alert('XSS');">`,
+		`<IMG SRC="jav&#x0A;ascript:alert('XSS');">`,
+		`<IMG SRC=" &#14;  javascript:alert('XSS');">`,
+		`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+		`<a href="vbscript:msgbox(1)">x</a>`,
+		`<svg/onload=alert(1)>`,
+		`<BODY ONLOAD=alert('XSS')>`,
+		`<iframe src="https://evil.example"></iframe>`,
+		`<object data="javascript:alert(1)"></object>`,
+		`<embed src="javascript:alert(1)">`,
+		`<style>@import'//evil.example/x.css';</style>`,
+		`<div style="background:url(javascript:alert(1))">x</div>`,
+		`<p style="x:expression(alert(1))">x</p>`,
+		`<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`,
+		`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+		`<a href="#" onclick="alert(1)">x</a>`,
+		`<input autofocus onfocus=alert(1)>`,
+		`<details open ontoggle=alert(1)>`,
+		`<!--<script>alert(1)</script>-->`,
+		`<a href="jAvAsCrIpT&colon;alert(1)">x</a>`,
+		`<img src="x` + "\x00" + `" onerror="alert(1)">`,
+		`<form action="javascript:alert(1)"><button>x</button></form>`,
+		`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+		`<base href="javascript:alert(1)//">`,
+	}
+
+	rxUnsafe := regexp.MustCompile(`(?i)<script|<style|<iframe|<object|<embed|<svg|<meta|<base|<form|<input|\bon[a-z]+=|javascript:|vbscript:|data:|expression\(|style="[^"]*url`)
+
+	for _, p := range payloads {
+		out := Sanitize(p)
+		assert.NotRegexp(t, rxUnsafe, out, "payload: %s", p)
+	}
+}
+
+func TestMDSanitized(t *testing.T) {
+	out := string(md("[x](https://bishack.dev) <script>alert(1)</script>"))
+	assert.Regexp(t, regexp.MustCompile(`rel="nofollow noopener"`), out)
+	assert.NotRegexp(t, regexp.MustCompile(`<script`), out)
+}
@@ -0,0 +1,51 @@
+package session
+
+import "strings"
+
+// browsers and systems are checked in order, Chrome's user agent mentions
+// Safari and Edge's mentions Chrome
+var (
+	browsers = [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	systems = [][2]string{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// device names the browser and system of a user agent, like "Firefox on
+// Linux". It's only shown to the user so a rough guess is fine.
+func device(ua string) string {
+	browser, system := match(ua, browsers), match(ua, systems)
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	return "Unknown device"
+}
+
+func match(ua string, list [][2]string) string {
+	for _, m := range list {
+		if strings.Contains(ua, m[0]) {
+			return m[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,154 @@
+package session
+
+import (
+	"bishack.dev/services/dynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// DynamoStore keeps sessions in a dynamo table keyed by id, with a
+// username_index to list them. `expires` is the table's TTL attribute.
+type DynamoStore struct {
+	*dynamo.Client
+}
+
+// NewDynamoStore ...
+func NewDynamoStore(
+	tableName,
+	endpoint string,
+	provider dynamo.Provider,
+) *DynamoStore {
+	return &DynamoStore{
+		dynamo.New(tableName, endpoint, provider),
+	}
+}
+
+// Create ...
+func (d *DynamoStore) Create(s *Session) error {
+	item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id":       s.ID,
+		"username": s.Username,
+		"token":    s.Token,
+		"device":   s.Device,
+		"ip":       s.IP,
+		"created":  s.Created,
+		"lastSeen": s.LastSeen,
+		"expires":  s.Expires,
+	})
+
+	input := &dynamodb.PutItemInput{
+		Item: item,
+	}
+	input.SetTableName(d.TableName)
+	input.SetConditionExpression("attribute_not_exists(id)")
+
+	if _, err := d.Provider.PutItem(input); err != nil {
+		return errors.Wrap(err, "Create/PutItem error")
+	}
+
+	return nil
+}
+
+// Get looks a session up by id. The read is consistent so a session that
+// was just revoked is never handed back.
+func (d *DynamoStore) Get(id string) (*Session, error) {
+	keys, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id": id,
+	})
+
+	input := &dynamodb.GetItemInput{}
+	input.SetTableName(d.TableName)
+	input.SetKey(keys)
+	input.SetConsistentRead(true)
+
+	out, err := d.Provider.GetItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "Get/GetItem error")
+	}
+
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+
+	var s Session
+	_ = dynamodbattribute.UnmarshalMap(out.Item, &s)
+	return &s, nil
+}
+
+// Touch ...
+func (d *DynamoStore) Touch(id string, lastSeen int64) error {
+	keys, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id": id,
+	})
+
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":lastSeen": lastSeen,
+	})
+
+	input := &dynamodb.UpdateItemInput{}
+	input.SetTableName(d.TableName)
+	input.SetKey(keys)
+	input.SetUpdateExpression("SET lastSeen = :lastSeen")
+	input.SetConditionExpression("attribute_exists(id)")
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := d.Provider.UpdateItem(input)
+	if aerr, ok := err.(awserr.Error); ok &&
+		aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "Touch/UpdateItem error")
+	}
+
+	return nil
+}
+
+// List returns the sessions of a user, newest first
+func (d *DynamoStore) List(username string) ([]*Session, error) {
+	ks := "username = :username"
+	vals := map[string]interface{}{
+		":username": username,
+	}
+
+	out, err := d.Query("username_index", ks, "", vals, false, 0, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "List/Query error")
+	}
+
+	list := []*Session{}
+	_ = dynamodbattribute.UnmarshalListOfMaps(out.Items, &list)
+	return list, nil
+}
+
+// Delete removes a session of the user
+func (d *DynamoStore) Delete(username, id string) error {
+	keys, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"id": id,
+	})
+
+	vals, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+		":username": username,
+	})
+
+	input := &dynamodb.DeleteItemInput{}
+	input.SetTableName(d.TableName)
+	input.SetKey(keys)
+	input.SetConditionExpression("username = :username")
+	input.SetExpressionAttributeValues(vals)
+
+	_, err := d.Provider.DeleteItem(input)
+	if aerr, ok := err.(awserr.Error); ok &&
+		aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "Delete/DeleteItem error")
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package session
+
+import (
+	"regexp"
+	"testing"
+
+	test "bishack.dev/testing"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDynamoCreate(t *testing.T) {
+	m := new(test.DynamoProviderMock)
+	d := NewDynamoStore("a", "b", m)
+
+	m.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		return *input.Item["id"].S == "x" &&
+			*input.Item["token"].S == "tuku" &&
+			*input.Item["expires"].N == "30" &&
+			*input.ConditionExpression == "attribute_not_exists(id)"
+	})).Return(&dynamodb.PutItemOutput{}, nil)
+
+	assert.Nil(t, d.Create(&Session{ID: "x", Username: "ing", Token: "tuku", Expires: 30}))
+	m.AssertExpectations(t)
+}
+
+func TestDynamoGet(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		d := NewDynamoStore("a", "b", m)
+
+		m.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+		s, err := d.Get("x")
+		assert.Nil(t, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		d := NewDynamoStore("a", "b", m)
+
+		item, _ := dynamodbattribute.MarshalMap(map[string]interface{}{
+			"id":       "x",
+			"username": "ing",
+			"lastSeen": 20,
+		})
+		m.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+			return *input.Key["id"].S == "x" && *input.ConsistentRead
+		})).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+
+		s, err := d.Get("x")
+		assert.Nil(t, err)
+		assert.Equal(t, &Session{ID: "x", Username: "ing", LastSeen: 20}, s)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		d := NewDynamoStore("a", "b", m)
+
+		m.On("GetItem", mock.Anything).Return(nil, errors.New("beep"))
+
+		_, err := d.Get("x")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestDynamoTouch(t *testing.T) {
+	m := new(test.DynamoProviderMock)
+	d := NewDynamoStore("a", "b", m)
+
+	m.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "x" &&
+			*input.ExpressionAttributeValues[":lastSeen"].N == "99"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	assert.Nil(t, d.Touch("x", 99))
+	m.AssertExpectations(t)
+}
+
+func TestDynamoList(t *testing.T) {
+	m := new(test.DynamoProviderMock)
+	d := NewDynamoStore("a", "b", m)
+
+	m.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.IndexName == "username_index" && !*input.ScanIndexForward
+	})).Return(&dynamodb.QueryOutput{}, nil)
+
+	list, err := d.List("ing")
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+}
+
+func TestDynamoDelete(t *testing.T) {
+	t.Run("not owned", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		d := NewDynamoStore("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+		assert.Equal(t, ErrNotFound, d.Delete("ing", "x"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		d := NewDynamoStore("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return true
+		})).Return(nil, errors.New("beep"))
+
+		err := d.Delete("ing", "x")
+		assert.Regexp(t, regexp.MustCompile("(?i)delete/deleteitem error: beep"), err.Error())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		m := new(test.DynamoProviderMock)
+		d := NewDynamoStore("a", "b", m)
+
+		m.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+			return *input.Key["id"].S == "x" &&
+				*input.ExpressionAttributeValues[":username"].S == "ing"
+		})).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+		assert.Nil(t, d.Delete("ing", "x"))
+		m.AssertExpectations(t)
+	})
+}
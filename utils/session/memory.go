@@ -0,0 +1,88 @@
+package session
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore keeps sessions in process, for tests and local hacking
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}}
+}
+
+// Create ...
+func (m *MemoryStore) Create(s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[s.ID] = *s
+	return nil
+}
+
+// Get ...
+func (m *MemoryStore) Get(id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &s, nil
+}
+
+// Touch ...
+func (m *MemoryStore) Touch(id string, lastSeen int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	s.LastSeen = lastSeen
+	m.sessions[id] = s
+	return nil
+}
+
+// List returns the sessions of a user, newest first
+func (m *MemoryStore) List(username string) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := []*Session{}
+	for _, s := range m.sessions {
+		if s.Username == username {
+			s := s
+			list = append(list, &s)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created > list[j].Created
+	})
+
+	return list, nil
+}
+
+// Delete ...
+func (m *MemoryStore) Delete(username, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok || s.Username != username {
+		return ErrNotFound
+	}
+
+	delete(m.sessions, id)
+	return nil
+}
@@ -1,10 +1,18 @@
 package session
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	// autoload env
 	_ "github.com/joho/godotenv/autoload"
@@ -14,49 +22,130 @@ import (
 
 var store = sessions.NewCookieStore([]byte(os.Getenv("SESSION_KEY")))
 
+// MaxAge is how long a session lasts, same as the cookie
+const MaxAge = 30 * 24 * 60 * 60
+
+// touchInterval keeps last seen fresh enough without writing on every request
+const touchInterval = 5 * 60
+
 // New ...
-func New() *Client {
-	return &Client{store}
+func New(sessions Store) *Client {
+	return &Client{store, sessions}
 }
 
-// SetUser ...
+// SetUser starts a server side session for the user. The cookie only gets
+// an opaque id.
 func (s *Client) SetUser(
 	w http.ResponseWriter,
 	r *http.Request,
 	username,
 	token string,
 ) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Println("SetUser/Read error", err.Error())
+		return
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now().Unix()
+	err := s.Sessions.Create(&Session{
+		ID:       hash(id),
+		Username: username,
+		Token:    token,
+		Device:   device(r.UserAgent()),
+		IP:       clientIP(r),
+		Created:  now,
+		LastSeen: now,
+		Expires:  now + MaxAge,
+	})
+	if err != nil {
+		log.Println("SetUser/Create error", err.Error())
+		return
+	}
+
 	session, _ := s.Store.Get(r, "user")
-	session.Values["username"] = username
-	session.Values["token"] = token
+	session.Values = map[interface{}]interface{}{"id": id}
 	_ = session.Save(r, w)
 }
 
-// GetUser ...
+// GetUser gets the username and refresh token of the session, along with
+// the session's id. Revoked and expired sessions give nil.
 func (s *Client) GetUser(r *http.Request) map[string]string {
-	session, _ := s.Store.Get(r, "user")
-
-	username := session.Values["username"]
-	token := session.Values["token"]
-
-	if username == nil || token == nil {
+	ses := s.current(r)
+	if ses == nil {
 		return nil
 	}
 
+	if now := time.Now().Unix(); now-ses.LastSeen > touchInterval {
+		_ = s.Sessions.Touch(ses.ID, now)
+	}
+
 	return map[string]string{
-		"username": username.(string),
-		"token":    token.(string),
+		"username": ses.Username,
+		"token":    ses.Token,
+		"session":  ses.ID,
 	}
 }
 
-// DeleteUser ...
+// DeleteUser ends the session on the server and clears the cookie
 func (s *Client) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if ses := s.current(r); ses != nil {
+		_ = s.Sessions.Delete(ses.Username, ses.ID)
+	}
+
 	session, _ := s.Store.Get(r, "user")
+	session.Values = map[interface{}]interface{}{}
+	session.Options.MaxAge = -1
+	_ = session.Save(r, w)
+}
 
-	session.Values["username"] = nil
-	session.Values["token"] = nil
+// GetSessions lists the active sessions of a user, most recently used first
+func (s *Client) GetSessions(username string) ([]*Session, error) {
+	list, err := s.Sessions.List(username)
+	if err != nil {
+		return nil, err
+	}
 
-	_ = session.Save(r, w)
+	now := time.Now().Unix()
+	active := []*Session{}
+	for _, ses := range list {
+		if ses.Expires > now {
+			active = append(active, ses)
+		}
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].LastSeen > active[j].LastSeen
+	})
+
+	return active, nil
+}
+
+// RevokeSession ends a session of the user, wherever it is
+func (s *Client) RevokeSession(username, id string) error {
+	return s.Sessions.Delete(username, id)
+}
+
+func (s *Client) current(r *http.Request) *Session {
+	session, _ := s.Store.Get(r, "user")
+
+	id, _ := session.Values["id"].(string)
+	if id == "" {
+		return nil
+	}
+
+	ses, err := s.Sessions.Get(hash(id))
+	if err != nil {
+		log.Println("GetUser/Get error", err.Error())
+		return nil
+	}
+
+	if ses == nil || ses.Expires <= time.Now().Unix() {
+		return nil
+	}
+
+	return ses
 }
 
 // SetFlash sets the flash message with the given
@@ -85,3 +174,23 @@ func (s *Client) GetFlash(w http.ResponseWriter, r *http.Request) *Flash {
 
 	return nil
 }
+
+func hash(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// clientIP is the address of the client, behind API Gateway that's the
+// first hop in X-Forwarded-For
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		return strings.TrimSpace(strings.Split(fwd, ",")[0])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,7 +13,7 @@ func TestFlash(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-	c := New()
+	c := New(NewMemoryStore())
 
 	c.SetFlash(w, r, "error", "error")
 
@@ -29,7 +30,7 @@ func TestUser(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest(http.MethodGet, "/", nil)
 
-	c := New()
+	c := New(NewMemoryStore())
 
 	t.Run("email or token nil", func(t *testing.T) {
 		u := c.GetUser(r)
@@ -46,10 +47,89 @@ func TestUser(t *testing.T) {
 		assert.Equal(t, "tuku", u["token"])
 	})
 
+	t.Run("cookie holds only the id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+		c.SetUser(w, r, "test", "tuku")
+
+		session, _ := c.Store.Get(r, "user")
+		assert.Len(t, session.Values, 1)
+		assert.NotContains(t, w.Header().Get("Set-Cookie"), "tuku")
+	})
+
 	t.Run("delete", func(t *testing.T) {
 		c.SetUser(w, r, "test", "tuku")
+		id := c.GetUser(r)["session"]
+
 		c.DeleteUser(w, r)
 		u := c.GetUser(r)
 		assert.Nil(t, u)
+
+		ses, _ := c.Sessions.Get(id)
+		assert.Nil(t, ses)
+	})
+}
+
+func TestSessions(t *testing.T) {
+	c := New(NewMemoryStore())
+
+	signIn := func(ua string) *http.Request {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("User-Agent", ua)
+		r.RemoteAddr = "10.0.0.1:1234"
+		c.SetUser(w, r, "test", "tuku")
+		return r
+	}
+
+	laptop := signIn("Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0")
+	phone := signIn("Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 Version/16.0 Mobile/15E148 Safari/604.1")
+
+	t.Run("list", func(t *testing.T) {
+		list, err := c.GetSessions("test")
+		assert.Nil(t, err)
+		assert.Len(t, list, 2)
+
+		devices := []string{list[0].Device, list[1].Device}
+		assert.ElementsMatch(t, []string{"Firefox on Linux", "Safari on iOS"}, devices)
+		assert.Equal(t, "10.0.0.1", list[0].IP)
+
+		list, _ = c.GetSessions("other")
+		assert.Empty(t, list)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		id := c.GetUser(phone)["session"]
+
+		assert.Equal(t, ErrNotFound, c.RevokeSession("other", id))
+		assert.NotNil(t, c.GetUser(phone))
+
+		assert.Nil(t, c.RevokeSession("test", id))
+		assert.Nil(t, c.GetUser(phone))
+		assert.NotNil(t, c.GetUser(laptop))
 	})
+
+	t.Run("expired", func(t *testing.T) {
+		id := c.GetUser(laptop)["session"]
+		ses, _ := c.Sessions.Get(id)
+		ses.Expires = time.Now().Unix() - 1
+		_ = c.Sessions.Create(ses)
+
+		assert.Nil(t, c.GetUser(laptop))
+
+		list, _ := c.GetSessions("test")
+		assert.Empty(t, list)
+	})
+}
+
+func TestDevice(t *testing.T) {
+	for ua, d := range map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0": "Edge on Windows",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36":     "Chrome on macOS",
+		"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36":              "Chrome on Android",
+		"curl/8.0": "Unknown device",
+	} {
+		assert.Equal(t, d, device(ua), ua)
+	}
 }
@@ -1,6 +1,13 @@
 package session
 
-import "github.com/gorilla/sessions"
+import (
+	"errors"
+
+	"github.com/gorilla/sessions"
+)
+
+// ErrNotFound is returned when revoking a session the user doesn't own
+var ErrNotFound = errors.New("Session not found")
 
 // Flash ...
 type Flash struct {
@@ -10,5 +17,29 @@ type Flash struct {
 
 // Client ...
 type Client struct {
-	Store *sessions.CookieStore
+	Store    *sessions.CookieStore
+	Sessions Store
+}
+
+// Session is a signed in device. The cookie only holds an opaque id, which
+// is never stored either, only its hash which doubles as the id here.
+type Session struct {
+	ID       string
+	Username string
+	Token    string
+	Device   string
+	IP       string
+	Created  int64
+	LastSeen int64
+	Expires  int64
+}
+
+// Store keeps sessions on the server so they can be listed and revoked
+type Store interface {
+	Create(s *Session) error
+	// Get returns nil when there's no session with the id
+	Get(id string) (*Session, error)
+	Touch(id string, lastSeen int64) error
+	List(username string) ([]*Session, error)
+	Delete(username, id string) error
 }
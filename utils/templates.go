@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// templateDir is where layouts, pages and components live
+var templateDir = "assets/templates"
+
+// shared are parsed into every template set, after the layout and the page.
+// Stylesheets and scripts aren't inlined, see AssetPath.
+var shared = []string{
+	// components
+	"components/main-nav.tmpl",
+	"components/user-card.tmpl",
+	"components/posts.tmpl",
+	"components/tags.tmpl",
+	"components/svg.tmpl",
+	"components/css.tmpl",
+}
+
+// templates caches a parsed set per layout and page. In dev mode nothing is
+// cached so edits show up on the next request.
+var templates = struct {
+	sync.RWMutex
+	dev bool
+	set map[string]*template.Template
+}{set: map[string]*template.Template{}}
+
+// LoadTemplates parses every page with every layout up front so a broken
+// template fails at startup rather than on a page view. With dev set,
+// templates are parsed again on every render.
+func LoadTemplates(dev bool) error {
+	layouts, err := filepath.Glob(filepath.Join(templateDir, "layout", "*.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	pages, err := filepath.Glob(filepath.Join(templateDir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	set := map[string]*template.Template{}
+	for _, l := range layouts {
+		for _, p := range pages {
+			base := strings.TrimSuffix(filepath.Base(l), ".tmpl")
+			content := strings.TrimSuffix(filepath.Base(p), ".tmpl")
+
+			tmpl, err := parse(base, content)
+			if err != nil {
+				return err
+			}
+
+			set[key(base, content)] = tmpl
+		}
+	}
+
+	templates.Lock()
+	templates.dev = dev
+	templates.set = set
+	templates.Unlock()
+
+	return nil
+}
+
+// lookup gets the set for a layout and page, parsing it if it isn't cached
+func lookup(base, content string) (*template.Template, error) {
+	templates.RLock()
+	tmpl, ok := templates.set[key(base, content)]
+	dev := templates.dev
+	templates.RUnlock()
+
+	if ok && !dev {
+		return tmpl, nil
+	}
+
+	tmpl, err := parse(base, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dev {
+		templates.Lock()
+		templates.set[key(base, content)] = tmpl
+		templates.Unlock()
+	}
+
+	return tmpl, nil
+}
+
+func parse(base, content string) (*template.Template, error) {
+	files := []string{
+		filepath.Join(templateDir, "layout", base+".tmpl"),
+		filepath.Join(templateDir, content+".tmpl"),
+	}
+	for _, f := range shared {
+		files = append(files, filepath.Join(templateDir, f))
+	}
+
+	tmpl, err := template.New("").Funcs(funcs()).ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("template %s/%s: %v", base, content, err)
+	}
+
+	return tmpl, nil
+}
+
+func funcs() template.FuncMap {
+	return template.FuncMap{
+		"md":   md,
+		"date": date,
+		"join": strings.Join,
+		// fingerprinted stylesheets and scripts
+		"asset": AssetPath,
+	}
+}
+
+func key(base, content string) string {
+	return base + "/" + content
+}
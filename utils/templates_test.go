@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	defer func() { _ = LoadTemplates(false) }()
+
+	t.Run("all templates parse", func(t *testing.T) {
+		assert.Nil(t, LoadTemplates(false))
+
+		a, err := lookup("main", "home")
+		assert.Nil(t, err)
+
+		b, _ := lookup("main", "home")
+		assert.Same(t, a, b)
+	})
+
+	t.Run("dev reloads", func(t *testing.T) {
+		assert.Nil(t, LoadTemplates(true))
+
+		a, _ := lookup("main", "home")
+		b, _ := lookup("main", "home")
+		assert.NotSame(t, a, b)
+	})
+
+	t.Run("broken template", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "templates")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		_ = os.Mkdir(filepath.Join(dir, "layout"), 0755)
+		_ = ioutil.WriteFile(filepath.Join(dir, "layout", "main.tmpl"), []byte(`{{define "layout"}}{{end}}`), 0644)
+		_ = ioutil.WriteFile(filepath.Join(dir, "home.tmpl"), []byte(`{{define "content"}}{{.Title}`), 0644)
+
+		prev := templateDir
+		templateDir = dir
+		defer func() { templateDir = prev }()
+
+		err = LoadTemplates(false)
+		assert.Regexp(t, "template main/home: .*home.tmpl:1", err.Error())
+	})
+}
@@ -1,28 +1,51 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"html/template"
+	"log"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	// autoload env
 	_ "github.com/joho/godotenv/autoload"
-
-	"gitlab.com/golang-commonmark/markdown"
 )
 
 const (
 	oauthEndpoint = "https://github.com/login/oauth"
 )
 
+// rxLive matches the up stages that serve real traffic
+var rxLive = regexp.MustCompile(`(?i)stag|prod`)
+
 func md(input string) template.HTML {
-	md := markdown.New(markdown.Linkify(false))
-	out := md.RenderToString([]byte(input))
-	out = strings.Replace(out, "<pre>", "<pre class=\"prettyprint\">", -1)
-	return template.HTML(out)
+	return template.HTML(Sanitize(render(input)))
+}
+
+// Markdown renders markdown into the same HTML the templates get from `md`
+func Markdown(input string) string {
+	return string(md(input))
+}
+
+// IsLive is true on the staging and production stages, everything else is
+// local development
+func IsLive() bool {
+	return rxLive.MatchString(os.Getenv("UP_STAGE"))
+}
+
+// BaseURL is where the site is served from, scheme and host without a
+// trailing slash. It comes from BASE_URL, never from the request, whose Host
+// header anyone can set, and defaults to the local dev server.
+func BaseURL() string {
+	if base := strings.TrimRight(os.Getenv("BASE_URL"), "/"); base != "" {
+		return base
+	}
+
+	return "http://localhost:3000"
 }
 
 func date(fmt string, input int64) string {
@@ -30,39 +53,49 @@ func date(fmt string, input int64) string {
 	return t.Format(fmt)
 }
 
-// Render parses templates and writes them into the the passed in ResponseWriter
-// interface
+// Render executes the cached template set for the layout and page into the
+// passed in ResponseWriter. Errors are logged and the visitor gets an error
+// page instead.
 func Render(w http.ResponseWriter, base, content string, ctx interface{}) {
-	fns := template.FuncMap{
-		"md":   md,
-		"date": date,
+	var buf bytes.Buffer
+
+	tmpl, err := lookup(base, content)
+	if err == nil {
+		err = tmpl.ExecuteTemplate(&buf, "layout", ctx)
 	}
-	tmpl, err := template.New("").Funcs(fns).ParseFiles(
-		fmt.Sprintf("assets/templates/layout/%s.tmpl", base),
-		fmt.Sprintf("assets/templates/%s.tmpl", content),
-		// components
-		"assets/templates/components/main-nav.tmpl",
-		"assets/templates/components/user-card.tmpl",
-		"assets/templates/components/posts.tmpl",
-		"assets/templates/components/svg.tmpl",
-		"assets/templates/components/css.tmpl",
-		// stylesheets
-		"assets/css/main.css",
-		// scripts
-		"assets/scripts/turbolinks.js",
-		"assets/scripts/axios.js",
-		"assets/scripts/main.js",
-	)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Println("Render error", err)
+		renderError(w)
 		return
 	}
 
 	if content == "notfound" {
 		w.WriteHeader(http.StatusNotFound)
 	}
-	_ = tmpl.ExecuteTemplate(w, "layout", ctx)
+	_, _ = buf.WriteTo(w)
+}
+
+// renderError writes the 500 page, falling back to plain text if even that
+// can't be rendered
+func renderError(w http.ResponseWriter) {
+	var buf bytes.Buffer
+
+	tmpl, err := lookup("error", "servererror")
+	if err == nil {
+		err = tmpl.ExecuteTemplate(&buf, "layout", map[string]interface{}{
+			"Title": "500 - Something went wrong",
+		})
+	}
+
+	if err != nil {
+		log.Println("renderError error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = buf.WriteTo(w)
 }
 
 // GithubEndpoint parses endpoint for github request
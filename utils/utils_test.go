@@ -3,6 +3,7 @@ package utils
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"regexp"
 	"testing"
 
@@ -14,7 +15,9 @@ func TestRender(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		Render(w, "xxx", "yyy", nil)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Regexp(t, regexp.MustCompile("Something went wrong"), w.Body.String())
+		assert.NotRegexp(t, regexp.MustCompile("xxx|yyy"), w.Body.String())
 	})
 
 	t.Run("not found", func(t *testing.T) {
@@ -22,6 +25,12 @@ func TestRender(t *testing.T) {
 		Render(w, "main", "notfound", nil)
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
+
+	t.Run("execution error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		Render(w, "main", "home", "not a map")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
 }
 
 func TestGithubEndpoint(t *testing.T) {
@@ -38,7 +47,38 @@ func TestGithubEndpoint(t *testing.T) {
 
 func TestMD(t *testing.T) {
 	tmpl := md(`# hello`)
-	assert.Regexp(t, regexp.MustCompile("<h1>hello</h1>"), tmpl)
+	assert.Regexp(t, regexp.MustCompile(`<h1 id="hello">hello`), tmpl)
+}
+
+func TestMarkdown(t *testing.T) {
+	assert.Regexp(t, regexp.MustCompile(`<h1 id="hello">hello`), Markdown(`# hello`))
+}
+
+func TestIsLive(t *testing.T) {
+	defer os.Unsetenv("UP_STAGE")
+
+	for stage, live := range map[string]bool{
+		"":           false,
+		"dev":        false,
+		"local":      false,
+		"staging":    true,
+		"Staging":    true,
+		"production": true,
+		"PROD":       true,
+	} {
+		os.Setenv("UP_STAGE", stage)
+		assert.Equal(t, live, IsLive(), stage)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	defer os.Unsetenv("BASE_URL")
+
+	os.Unsetenv("BASE_URL")
+	assert.Equal(t, "http://localhost:3000", BaseURL())
+
+	os.Setenv("BASE_URL", "https://bishack.dev/")
+	assert.Equal(t, "https://bishack.dev", BaseURL())
 }
 
 func TestDate(t *testing.T) {